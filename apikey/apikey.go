@@ -0,0 +1,78 @@
+// El paquete apikey contiene la generación, el hash y los permisos (scopes) de las API keys.
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+// PREFIX es el prefijo que identifica una API key frente a un token JWT.
+// PREFIX_SIZE es el número de caracteres de la key que se guardan en claro para reconocerla.
+const (
+	PREFIX      = "tpk_"
+	PREFIX_SIZE = 12
+)
+
+// Permisos que se pueden asignar a una API key.
+const (
+	SCOPE_USERS_READ       = "users:read"
+	SCOPE_CHALLENGES_READ  = "challenges:read"
+	SCOPE_CHALLENGES_WRITE = "challenges:write"
+	SCOPE_COMPANIES_READ   = "companies:read"
+	SCOPE_COMPANIES_WRITE  = "companies:write"
+)
+
+// SCOPES contiene todos los permisos válidos.
+var SCOPES = []string{
+	SCOPE_USERS_READ,
+	SCOPE_CHALLENGES_READ,
+	SCOPE_CHALLENGES_WRITE,
+	SCOPE_COMPANIES_READ,
+	SCOPE_COMPANIES_WRITE,
+}
+
+// Generate es una función que genera una nueva API key y devuelve la key en claro y su prefijo visible.
+func Generate() (string, string, error) {
+	// Generar 32 bytes aleatorios
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	// Construir la key con el prefijo
+	key := PREFIX + base64.RawURLEncoding.EncodeToString(buf)
+	return key, key[:PREFIX_SIZE], nil
+}
+
+// Hash es una función que calcula el hash SHA-256 de una API key. Al tener alta entropía no necesita un hash lento.
+func Hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsAPIKey es una función que indica si una credencial tiene el formato de una API key.
+func IsAPIKey(credential string) bool {
+	return strings.HasPrefix(credential, PREFIX)
+}
+
+// ValidScope es una función que indica si un permiso es válido.
+func ValidScope(scope string) bool {
+	for _, s := range SCOPES {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// HasScope es una función que indica si una lista de permisos contiene el permiso requerido.
+func HasScope(scopes []string, required string) bool {
+	for _, s := range scopes {
+		if s == required {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+// El paquete audit registra las operaciones que modifican datos en el registro de auditoría.
+package audit
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+
+	"talentpitchGo/logging"    // logger de la solicitud
+	"talentpitchGo/middleware" // usuario autenticado de la solicitud
+	"talentpitchGo/models"     // modelos de datos
+	"talentpitchGo/repository" // operaciones de base de datos
+
+	"github.com/segmentio/ksuid" // para generar IDs únicos
+)
+
+// REQUEST_ID_HEADER es la cabecera con el ID de la solicitud
+const REQUEST_ID_HEADER = middleware.REQUEST_ID_HEADER
+
+// REDACTED son los campos que nunca se guardan en el registro
+var REDACTED = []string{"password"}
+
+// Record es una función que agrega al registro de auditoría un cambio sobre una entidad.
+// before y after son el estado de la entidad antes y después del cambio (nil al crear o eliminar);
+// solo se guardan los campos que cambiaron. Un fallo se registra en el log y no interrumpe la solicitud.
+func Record(r *http.Request, action string, entityType string, entityID string, before interface{}, after interface{}) {
+	// Calcular la diferencia entre los dos estados
+	beforeDiff, afterDiff, err := Diff(before, after)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("error computing audit diff", "entity_type", entityType, "entity_id", entityID, "error", err)
+		return
+	}
+	// Crear la entrada con los datos de la solicitud
+	entry := &models.AuditEntry{
+		Id:         ksuid.New().String(),
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Before:     beforeDiff,
+		After:      afterDiff,
+		RequestID:  r.Header.Get(REQUEST_ID_HEADER),
+		IP:         middleware.RequestIP(r),
+	}
+	// Agregar el usuario autenticado, si lo hay
+	if principal, ok := middleware.PrincipalFromContext(r.Context()); ok {
+		entry.ActorID = principal.UserID
+		entry.APIKeyID = principal.APIKeyID
+	}
+	// Guardar la entrada
+	if err := repository.InsertAuditEntry(r.Context(), entry); err != nil {
+		logging.FromContext(r.Context()).Error("error recording audit entry", "entity_type", entityType, "entity_id", entityID, "error", err)
+	}
+}
+
+// Diff es una función que compara dos estados de una entidad y devuelve los campos que cambiaron
+// con su valor anterior y su valor nuevo. Si uno de los estados es nil se devuelve el otro completo.
+func Diff(before interface{}, after interface{}) (json.RawMessage, json.RawMessage, error) {
+	// Convertir los estados en mapas de campos
+	beforeFields, err := fields(before)
+	if err != nil {
+		return nil, nil, err
+	}
+	afterFields, err := fields(after)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Quitar los campos que no cambiaron
+	if beforeFields != nil && afterFields != nil {
+		for key, value := range beforeFields {
+			if other, ok := afterFields[key]; ok && reflect.DeepEqual(value, other) {
+				delete(beforeFields, key)
+				delete(afterFields, key)
+			}
+		}
+	}
+	// Codificar los campos restantes
+	beforeJSON, err := encode(beforeFields)
+	if err != nil {
+		return nil, nil, err
+	}
+	afterJSON, err := encode(afterFields)
+	if err != nil {
+		return nil, nil, err
+	}
+	return beforeJSON, afterJSON, nil
+}
+
+// fields es una función que convierte una entidad en un mapa con sus campos JSON, sin los campos protegidos
+func fields(entity interface{}) (map[string]interface{}, error) {
+	// Una entidad nil no tiene campos
+	if entity == nil || (reflect.ValueOf(entity).Kind() == reflect.Ptr && reflect.ValueOf(entity).IsNil()) {
+		return nil, nil
+	}
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return nil, err
+	}
+	var result map[string]interface{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	// Quitar los campos protegidos
+	for _, key := range REDACTED {
+		delete(result, key)
+	}
+	return result, nil
+}
+
+// encode es una función que codifica un mapa de campos, devolviendo nil si no hay mapa
+func encode(values map[string]interface{}) (json.RawMessage, error) {
+	if values == nil {
+		return nil, nil
+	}
+	return json.Marshal(values)
+}
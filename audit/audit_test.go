@@ -0,0 +1,39 @@
+package audit_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"talentpitchGo/audit"
+	"talentpitchGo/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffKeepsOnlyChangedFields(t *testing.T) {
+	before := &models.Company{Id: "1", Name: "Old", Location: "Bogotá", UserID: "u1"}
+	after := &models.Company{Id: "1", Name: "New", Location: "Bogotá", UserID: "u1"}
+
+	beforeJSON, afterJSON, err := audit.Diff(before, after)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"name":"Old"}`, string(beforeJSON))
+	assert.JSONEq(t, `{"name":"New"}`, string(afterJSON))
+}
+
+func TestDiffCreateAndDeleteRedactPassword(t *testing.T) {
+	user := &models.User{Id: "1", Email: "a@b.co", Password: "hash"}
+
+	beforeJSON, afterJSON, err := audit.Diff(nil, user)
+	assert.NoError(t, err)
+	assert.Nil(t, beforeJSON)
+	var fields map[string]interface{}
+	assert.NoError(t, json.Unmarshal(afterJSON, &fields))
+	assert.Equal(t, "a@b.co", fields["email"])
+	assert.NotContains(t, fields, "password")
+
+	var deleted *models.User
+	beforeJSON, afterJSON, err = audit.Diff(user, deleted)
+	assert.NoError(t, err)
+	assert.NotNil(t, beforeJSON)
+	assert.Nil(t, afterJSON)
+}
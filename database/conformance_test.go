@@ -0,0 +1,384 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"talentpitchGo/models"
+	"talentpitchGo/repository"
+	"talentpitchGo/storage"
+
+	"github.com/joho/godotenv" // para cargar variables de entorno desde un archivo .env
+	"github.com/segmentio/ksuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// conformanceRepository son los repositorios que deben comportarse igual con cualquier controlador
+type conformanceRepository interface {
+	repository.UserRepository
+	repository.ChallengeRepository
+	repository.CompanyRepository
+}
+
+// testDatabaseURL es una función que devuelve la URL de la base de datos de .env_test con el esquema de up.sql
+// aplicado. Si la base de datos no está disponible, la prueba se omite.
+func testDatabaseURL(t *testing.T) string {
+	godotenv.Load("./../.env_test")
+	url := os.Getenv("DATABASE_URL")
+	if url == "" {
+		t.Skip("DATABASE_URL is not set")
+	}
+	db, err := sql.Open("postgres", url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := db.PingContext(ctx); err != nil {
+		t.Skipf("database is not available: %v", err)
+	}
+	// El esquema es idempotente, así que se puede aplicar en cada ejecución
+	schema, err := os.ReadFile("up.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.ExecContext(ctx, string(schema)); err != nil {
+		t.Fatalf("applying schema: %v", err)
+	}
+	return url
+}
+
+func TestPostgresRepositoryConformance(t *testing.T) {
+	repo, err := NewPostgresRepository(testDatabaseURL(t))
+	require.NoError(t, err)
+	defer repo.Close()
+	testRepositoryConformance(t, repo)
+}
+
+func TestPgxRepositoryConformance(t *testing.T) {
+	repo, err := NewPgxRepository(context.Background(), testDatabaseURL(t))
+	require.NoError(t, err)
+	defer repo.Close()
+	testRepositoryConformance(t, repo)
+}
+
+// testRepositoryConformance es la batería de pruebas que deben pasar todas las implementaciones de los
+// repositorios de usuarios, retos y empresas
+func testRepositoryConformance(t *testing.T, repo conformanceRepository) {
+	ctx := context.Background()
+	id := func() string { return ksuid.New().String() }
+	// assertUpdated es una función que verifica que una actualización conserve created_at y avance updated_at
+	assertUpdated := func(entity string, created time.Time, updated time.Time, after time.Time, afterUpdated time.Time) {
+		assert.True(t, after.Equal(created), "%s: created_at changed from %v to %v", entity, created, after)
+		assert.True(t, afterUpdated.After(updated), "%s: updated_at did not advance from %v", entity, updated)
+	}
+
+	// Usuarios
+	user := &models.User{Id: id(), Fullname: "Conformance", Email: id() + "@example.com", Password: "hash"}
+	require.NoError(t, repo.InsertUser(ctx, user))
+	assert.Equal(t, 1, user.Version)
+	assert.False(t, user.CreatedAt.IsZero())
+
+	found, err := repo.GetUserById(ctx, user.Id)
+	require.NoError(t, err)
+	assert.Equal(t, user.Email, found.Email)
+	assert.Equal(t, "user", found.Role)
+	byEmail, err := repo.GetUserByEmail(ctx, user.Email)
+	require.NoError(t, err)
+	assert.Equal(t, user.Id, byEmail.Id)
+	assert.Equal(t, "hash", byEmail.Password)
+
+	_, err = repo.GetUserById(ctx, id())
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	missing, err := repo.GetUserByEmail(ctx, id()+"@example.com")
+	assert.NoError(t, err)
+	assert.Nil(t, missing)
+
+	user.Fullname = "Conformance Updated"
+	created, updated := user.CreatedAt, user.UpdatedAt
+	// Esperar a que cambie el reloj, para que la actualización tenga otra marca de tiempo
+	time.Sleep(10 * time.Millisecond)
+	require.NoError(t, repo.UpdateUser(ctx, user.Id, user))
+	assert.Equal(t, 2, user.Version)
+	assertUpdated("user", created, updated, user.CreatedAt, user.UpdatedAt)
+	found, err = repo.GetUserById(ctx, user.Id)
+	require.NoError(t, err)
+	assertUpdated("stored user", created, updated, found.CreatedAt, found.UpdatedAt)
+	// Una versión anterior es un conflicto y un ID desconocido no existe
+	stale := *user
+	stale.Version = 1
+	assert.ErrorIs(t, repo.UpdateUser(ctx, user.Id, &stale), repository.ErrVersionConflict)
+	assert.ErrorIs(t, repo.UpdateUser(ctx, id(), &stale), repository.ErrNotFound)
+
+	user.AvatarPath = "/uploads/avatar.png"
+	require.NoError(t, repo.PatchUser(ctx, user.Id, user, []string{"avatar_path"}))
+	assert.Equal(t, 3, user.Version)
+	assert.Error(t, repo.PatchUser(ctx, user.Id, user, []string{"role"}))
+
+	_, _, err = repo.GetUsers(ctx, 0, 10)
+	assert.Error(t, err)
+	users, count, err := repo.GetUsers(ctx, 1, 10)
+	require.NoError(t, err)
+	assert.NotEmpty(t, users)
+	// El listado no lee el hash de la contraseña
+	for _, listed := range users {
+		assert.Empty(t, listed.Password)
+	}
+	assert.GreaterOrEqual(t, count, len(users))
+
+	// Retos
+	easy := &models.Challenge{Id: id(), Title: "Easy", Description: "Easy challenge", Difficulty: 1, UserID: user.Id}
+	hard := &models.Challenge{Id: id(), Title: "Hard", Description: "Hard challenge", Difficulty: 5, UserID: user.Id}
+	require.NoError(t, repo.InsertChallenge(ctx, easy))
+	require.NoError(t, repo.InsertChallenge(ctx, hard))
+
+	challenge, err := repo.GetChallengeById(ctx, easy.Id)
+	require.NoError(t, err)
+	assert.Equal(t, "Easy challenge", challenge.Description)
+	_, err = repo.GetChallengeById(ctx, id())
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	challenges, count, err := repo.GetChallenges(ctx, models.ChallengeFilter{UserID: user.Id}, 1, 10)
+	require.NoError(t, err)
+	assert.Len(t, challenges, 2)
+	assert.Equal(t, 2, count)
+	challenges, count, err = repo.GetChallenges(ctx, models.ChallengeFilter{UserID: user.Id, Difficulties: []int{5}}, 1, 10)
+	require.NoError(t, err)
+	require.Len(t, challenges, 1)
+	assert.Equal(t, hard.Id, challenges[0].Id)
+	assert.Equal(t, 1, count)
+	// Una página vacía no tiene retos pero sí el total
+	challenges, count, err = repo.GetChallenges(ctx, models.ChallengeFilter{UserID: user.Id}, 2, 10)
+	require.NoError(t, err)
+	assert.Empty(t, challenges)
+	assert.Equal(t, 2, count)
+
+	stats, err := repo.GetChallengeStats(ctx, models.ChallengeFilter{UserID: user.Id})
+	require.NoError(t, err)
+	assert.Equal(t, map[int]int{1: 1, 5: 1}, stats)
+
+	easy.Title = "Easy Updated"
+	created, updated = easy.CreatedAt, easy.UpdatedAt
+	time.Sleep(10 * time.Millisecond)
+	require.NoError(t, repo.UpdateChallenge(ctx, easy.Id, easy))
+	assert.Equal(t, 2, easy.Version)
+	assertUpdated("challenge", created, updated, easy.CreatedAt, easy.UpdatedAt)
+	easy.Difficulty = 2
+	require.NoError(t, repo.PatchChallenge(ctx, easy.Id, easy, []string{"difficulty"}))
+	assert.Equal(t, 3, easy.Version)
+
+	assert.ErrorIs(t, repo.DeleteChallenge(ctx, hard.Id, 2), repository.ErrVersionConflict)
+	require.NoError(t, repo.DeleteChallenge(ctx, hard.Id, hard.Version))
+	_, err = repo.GetChallengeById(ctx, hard.Id)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	assert.ErrorIs(t, repo.DeleteChallenge(ctx, hard.Id, 0), repository.ErrNotFound)
+	require.NoError(t, repo.RestoreChallenge(ctx, hard.Id))
+	assert.ErrorIs(t, repo.RestoreChallenge(ctx, hard.Id), repository.ErrNotFound)
+
+	// Empresas; la imagen es opcional
+	company := &models.Company{Id: id(), Name: "Conformance", Location: "Bogotá", Industry: "Software", UserID: user.Id}
+	require.NoError(t, repo.InsertCompany(ctx, company))
+	foundCompany, err := repo.GetCompanyById(ctx, company.Id)
+	require.NoError(t, err)
+	assert.Equal(t, "", foundCompany.ImagePath)
+	_, err = repo.GetCompanyById(ctx, id())
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	company.ImagePath = "/uploads/logo.png"
+	company.Location = "Medellín"
+	created, updated = company.CreatedAt, company.UpdatedAt
+	time.Sleep(10 * time.Millisecond)
+	require.NoError(t, repo.UpdateCompany(ctx, company.Id, company))
+	assertUpdated("company", created, updated, company.CreatedAt, company.UpdatedAt)
+	// UpdateCompany no cambia el logo; solo lo asigna la subida de la imagen
+	foundCompany, err = repo.GetCompanyById(ctx, company.Id)
+	require.NoError(t, err)
+	assert.Equal(t, "Medellín", foundCompany.Location)
+	assert.Equal(t, "", foundCompany.ImagePath)
+	company.Industry = "Education"
+	require.NoError(t, repo.PatchCompany(ctx, company.Id, company, []string{"industry", "image_path"}))
+	assert.Equal(t, 3, company.Version)
+
+	companies, count, err := repo.GetCompaniesByUser(ctx, user.Id, 1, 10)
+	require.NoError(t, err)
+	require.Len(t, companies, 1)
+	assert.Equal(t, "Education", companies[0].Industry)
+	assert.Equal(t, "/uploads/logo.png", companies[0].ImagePath)
+	assert.Equal(t, 1, count)
+	companies, count, err = repo.GetCompanies(ctx, 1, 10)
+	require.NoError(t, err)
+	assert.NotEmpty(t, companies)
+	assert.GreaterOrEqual(t, count, 1)
+
+	// Eliminar el usuario elimina sus retos y empresas, y restaurarlo los restaura
+	assert.ErrorIs(t, repo.DeleteUser(ctx, user.Id, 1), repository.ErrVersionConflict)
+	require.NoError(t, repo.DeleteUser(ctx, user.Id, user.Version))
+	_, err = repo.GetUserById(ctx, user.Id)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	_, count, err = repo.GetChallenges(ctx, models.ChallengeFilter{UserID: user.Id}, 1, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+	_, err = repo.GetCompanyById(ctx, company.Id)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	// Un reto no se restaura mientras su dueño está eliminado
+	assert.ErrorIs(t, repo.RestoreChallenge(ctx, easy.Id), repository.ErrNotFound)
+
+	require.NoError(t, repo.RestoreUser(ctx, user.Id))
+	assert.ErrorIs(t, repo.RestoreUser(ctx, user.Id), repository.ErrNotFound)
+	_, count, err = repo.GetChallenges(ctx, models.ChallengeFilter{UserID: user.Id}, 1, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	_, err = repo.GetCompanyById(ctx, company.Id)
+	assert.NoError(t, err)
+
+	// Dejar los datos de la prueba eliminados
+	require.NoError(t, repo.DeleteUser(ctx, user.Id, 0))
+}
+
+func TestPurgeDeleted(t *testing.T) {
+	url := testDatabaseURL(t)
+	repo, err := NewPostgresRepository(url)
+	require.NoError(t, err)
+	defer repo.Close()
+	db, err := sql.Open("postgres", url)
+	require.NoError(t, err)
+	defer db.Close()
+	ctx := context.Background()
+	id := func() string { return ksuid.New().String() }
+	// count es una función que cuenta las filas de la tabla con el valor dado en la columna, eliminadas o no
+	count := func(table string, column string, value string) int {
+		var n int
+		require.NoError(t, db.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+table+" WHERE "+column+" = $1", value).Scan(&n))
+		return n
+	}
+	// deletedAgo es una función que marca la fila como eliminada hace el tiempo dado
+	deletedAgo := func(table string, rowID string, ago time.Duration) {
+		_, err := db.ExecContext(ctx, "UPDATE "+table+" SET deleted_at = $1 WHERE id = $2", time.Now().Add(-ago), rowID)
+		require.NoError(t, err)
+	}
+	// newUser es una función que crea un usuario con un reto y una empresa
+	newUser := func() (*models.User, *models.Challenge, *models.Company) {
+		user := &models.User{Id: id(), Fullname: "Purge", Email: id() + "@example.com", Password: "hash"}
+		require.NoError(t, repo.InsertUser(ctx, user))
+		challenge := &models.Challenge{Id: id(), Title: "Purge", Description: "d", Difficulty: 1, UserID: user.Id}
+		require.NoError(t, repo.InsertChallenge(ctx, challenge))
+		company := &models.Company{Id: id(), Name: "Purge", Location: "Bogotá", Industry: "Software", UserID: user.Id}
+		require.NoError(t, repo.InsertCompany(ctx, company))
+		return user, challenge, company
+	}
+
+	// Un usuario eliminado hace más que la retención, con 2FA, una identidad externa y una API key
+	expired, expiredChallenge, expiredCompany := newUser()
+	require.NoError(t, repo.SaveUserMFASecret(ctx, expired.Id, "secret"))
+	require.NoError(t, repo.EnableUserMFA(ctx, expired.Id, []*models.RecoveryCode{{Id: id(), UserID: expired.Id, CodeHash: id()}}))
+	require.NoError(t, repo.InsertIdentity(ctx, &models.Identity{Id: id(), UserID: expired.Id, Provider: "github", Subject: id(), Email: expired.Email}))
+	require.NoError(t, repo.InsertAPIKey(ctx, &models.APIKey{Id: id(), UserID: expired.Id, Name: "purge", Prefix: "tp_purge", KeyHash: id(), Scopes: []string{}}))
+	require.NoError(t, repo.DeleteUser(ctx, expired.Id, 0))
+	for _, table := range []string{"users", "challenges", "companies"} {
+		_, err := db.ExecContext(ctx, "UPDATE "+table+" SET deleted_at = $1 WHERE deleted_at IS NOT NULL AND (id = $2 OR user_id = $2)", time.Now().Add(-2*time.Hour), expired.Id)
+		require.NoError(t, err)
+	}
+
+	// Un usuario eliminado hace poco, todavía restaurable
+	recent, recentChallenge, recentCompany := newUser()
+	require.NoError(t, repo.DeleteUser(ctx, recent.Id, 0))
+
+	// Un usuario activo con un reto eliminado hace tiempo y uno eliminado hace poco
+	active, activeChallenge, activeCompany := newUser()
+	oldChallenge := &models.Challenge{Id: id(), Title: "Old", Description: "d", Difficulty: 1, UserID: active.Id}
+	require.NoError(t, repo.InsertChallenge(ctx, oldChallenge))
+	deletedAgo("challenges", oldChallenge.Id, 2*time.Hour)
+	deletedAgo("challenges", activeChallenge.Id, time.Minute)
+
+	purged, err := repo.PurgeDeleted(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	// El usuario, su reto, su empresa y el reto viejo del usuario activo, como mínimo
+	assert.GreaterOrEqual(t, purged, int64(4))
+
+	// Las filas vencidas y todo lo que depende del usuario purgado desaparecen
+	assert.Equal(t, 0, count("users", "id", expired.Id))
+	assert.Equal(t, 0, count("challenges", "id", expiredChallenge.Id))
+	assert.Equal(t, 0, count("companies", "id", expiredCompany.Id))
+	assert.Equal(t, 0, count("user_mfa", "user_id", expired.Id))
+	assert.Equal(t, 0, count("user_recovery_codes", "user_id", expired.Id))
+	assert.Equal(t, 0, count("identities", "user_id", expired.Id))
+	assert.Equal(t, 0, count("api_keys", "user_id", expired.Id))
+	assert.Equal(t, 0, count("challenges", "id", oldChallenge.Id))
+
+	// Las filas eliminadas dentro de la retención y las activas se conservan
+	assert.Equal(t, 1, count("users", "id", recent.Id))
+	assert.Equal(t, 1, count("challenges", "id", recentChallenge.Id))
+	assert.Equal(t, 1, count("companies", "id", recentCompany.Id))
+	assert.Equal(t, 1, count("users", "id", active.Id))
+	assert.Equal(t, 1, count("challenges", "id", activeChallenge.Id))
+	assert.Equal(t, 1, count("companies", "id", activeCompany.Id))
+	require.NoError(t, repo.RestoreUser(ctx, recent.Id))
+}
+
+func TestPurgeDeletedBlobs(t *testing.T) {
+	url := testDatabaseURL(t)
+	repo, err := NewPostgresRepository(url)
+	require.NoError(t, err)
+	defer repo.Close()
+	db, err := sql.Open("postgres", url)
+	require.NoError(t, err)
+	defer db.Close()
+	dir := t.TempDir()
+	store, err := storage.NewLocalStore(dir, "/uploads")
+	require.NoError(t, err)
+	storage.SetBlobStore(store)
+	ctx := context.Background()
+	id := func() string { return ksuid.New().String() }
+	// put guarda un archivo en el almacén y devuelve su URL
+	put := func(key string) string {
+		url, err := storage.Put(ctx, key, "image/png", []byte("data"))
+		require.NoError(t, err)
+		return url
+	}
+	// exists indica si el archivo de la clave sigue en el almacén
+	exists := func(key string) bool {
+		_, err := os.Stat(filepath.Join(dir, filepath.FromSlash(key)))
+		return err == nil
+	}
+
+	// Un usuario eliminado hace tiempo con su avatar, una empresa con logo y un reto con un adjunto
+	expired := &models.User{Id: id(), Fullname: "Expired", Email: id() + "@example.com", Password: "hash"}
+	require.NoError(t, repo.InsertUser(ctx, expired))
+	avatar := storage.UserAvatarPrefix(expired.Id) + "/a.png"
+	expired.AvatarPath = put(avatar)
+	put(storage.ThumbnailKey(avatar, 64))
+	require.NoError(t, repo.PatchUser(ctx, expired.Id, expired, []string{"avatar_path"}))
+	company := &models.Company{Id: id(), Name: "Expired", Location: "Bogotá", Industry: "Software", UserID: expired.Id}
+	require.NoError(t, repo.InsertCompany(ctx, company))
+	logo := storage.CompanyLogoPrefix(company.Id) + "/l.png"
+	company.ImagePath = put(logo)
+	put(storage.ThumbnailKey(logo, 256))
+	require.NoError(t, repo.PatchCompany(ctx, company.Id, company, []string{"image_path"}))
+	challenge := &models.Challenge{Id: id(), Title: "Expired", Description: "d", Difficulty: 1, UserID: expired.Id}
+	require.NoError(t, repo.InsertChallenge(ctx, challenge))
+	attachment := storage.PRIVATE_PREFIX + "challenges/" + challenge.Id + "/" + id()
+	put(attachment)
+	require.NoError(t, repo.InsertAttachment(ctx, &models.Attachment{Id: id(), ChallengeID: challenge.Id, UserID: expired.Id, Filename: "f.txt", ContentType: "text/plain", Size: 4, BlobKey: attachment}))
+	_, err = db.ExecContext(ctx, "UPDATE users SET deleted_at = $1 WHERE id = $2", time.Now().Add(-time.Hour), expired.Id)
+	require.NoError(t, err)
+
+	// El avatar de un usuario activo se conserva
+	active := &models.User{Id: id(), Fullname: "Active", Email: id() + "@example.com", Password: "hash"}
+	require.NoError(t, repo.InsertUser(ctx, active))
+	activeAvatar := storage.UserAvatarPrefix(active.Id) + "/a.png"
+	active.AvatarPath = put(activeAvatar)
+	require.NoError(t, repo.PatchUser(ctx, active.Id, active, []string{"avatar_path"}))
+
+	_, err = repo.PurgeDeleted(ctx, time.Now())
+	require.NoError(t, err)
+	for _, key := range []string{avatar, storage.ThumbnailKey(avatar, 64), logo, storage.ThumbnailKey(logo, 256), attachment} {
+		assert.False(t, exists(key), key)
+	}
+	assert.True(t, exists(activeAvatar))
+}
@@ -0,0 +1,565 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/trace"
+	"talentpitchGo/metrics"
+	"talentpitchGo/models"
+	"talentpitchGo/repository"
+)
+
+// PgxRepository es una estructura que contiene el pool de conexiones de pgx. Implementa los repositorios de
+// usuarios, retos y empresas con el protocolo nativo de Postgres: pgx prepara y guarda en caché las consultas
+// de los archivos .sql en cada conexión.
+type PgxRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPgxRepository es una función que crea un pool de conexiones con pgx y devuelve un nuevo repositorio.
+func NewPgxRepository(ctx context.Context, url string) (*PgxRepository, error) {
+	config, err := pgxpool.ParseConfig(url)
+	if err != nil {
+		return nil, err
+	}
+	// Abrir un span por cada consulta, igual que en el repositorio de database/sql
+	config.ConnConfig.Tracer = pgxTracer{}
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	return &PgxRepository{pool: pool}, nil
+}
+
+// pgxTracer es el trazador de pgx que abre un span por cada consulta
+type pgxTracer struct{}
+
+// TraceQueryStart abre el span de la consulta
+func (pgxTracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	ctx, _ = startQuerySpan(ctx, data.SQL)
+	return ctx
+}
+
+// TraceQueryEnd cierra el span de la consulta
+func (pgxTracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	endQuerySpan(trace.SpanFromContext(ctx), data.Err)
+}
+
+// ping es una función que verifica que el repositorio y la base de datos estén disponibles
+func (p *PgxRepository) ping(ctx context.Context) error {
+	// Verificar que el repositorio no sea nil
+	if p == nil || p.pool == nil {
+		return errors.New("repository cannot be nil")
+	}
+	// Verificar que el contexto no sea nil
+	if ctx == nil {
+		return errors.New("context cannot be nil")
+	}
+	// Verificar que la base de datos está disponible
+	if err := p.pool.Ping(ctx); err != nil {
+		return errors.New("database is not available")
+	}
+	return nil
+}
+
+// Close es una función que cierra el pool de conexiones.
+func (p *PgxRepository) Close() error {
+	p.pool.Close()
+	return nil
+}
+
+// CloseChallenge es una función que cierra el pool de conexiones.
+func (p *PgxRepository) CloseChallenge() error {
+	return p.Close()
+}
+
+// CloseCompany es una función que cierra el pool de conexiones.
+func (p *PgxRepository) CloseCompany() error {
+	return p.Close()
+}
+
+//********************************************************************************************************************
+//************************************************************* USER *************************************************
+//********************************************************************************************************************
+
+// InsertUser es una función que inserta un nuevo usuario en la base de datos.
+func (p *PgxRepository) InsertUser(ctx context.Context, user *models.User) error {
+	defer metrics.ObserveQuery("InsertUser", time.Now())
+	if err := p.ping(ctx); err != nil {
+		return err
+	}
+	// Verificar que el usuario no sea nil
+	if user == nil {
+		return errors.New("user cannot be nil")
+	}
+	// Los campos se validan en el paquete repository; aquí solo se exige el ID
+	if user.Id == "" {
+		return errors.New("user id cannot be empty")
+	}
+	// Insertar un nuevo usuario en la base de datos
+	return p.pool.QueryRow(ctx, queries["InsertUser"], user.Id, user.Fullname, user.Email, user.Password).Scan(&user.CreatedAt, &user.UpdatedAt, &user.Version)
+}
+
+// UpdateUser es una función que actualiza un usuario en la base de datos.
+func (p *PgxRepository) UpdateUser(ctx context.Context, id string, user *models.User) error {
+	defer metrics.ObserveQuery("UpdateUser", time.Now())
+	// Ejecutar la consulta de actualización; los triggers de la tabla actualizan updated_at y version
+	err := p.pool.QueryRow(ctx, queries["UpdateUser"], user.Fullname, user.Email, id, user.Version).Scan(&user.CreatedAt, &user.UpdatedAt, &user.Version)
+	// El usuario no existe, fue eliminado o cambió de versión
+	if errors.Is(err, pgx.ErrNoRows) {
+		return p.rowError(ctx, "users", id)
+	}
+	return err
+}
+
+// PatchUser es una función que actualiza solo los campos dados de un usuario.
+func (p *PgxRepository) PatchUser(ctx context.Context, id string, user *models.User, fields []string) error {
+	defer metrics.ObserveQuery("PatchUser", time.Now())
+	return p.patch(ctx, "users", id, user.Version, userColumns(user), fields, &user.CreatedAt, &user.UpdatedAt, &user.Version)
+}
+
+// DeleteUser es una función que elimina lógicamente un usuario junto con sus retos y empresas.
+// Los recursos del usuario se marcan con la misma fecha para poder restaurarlos juntos.
+func (p *PgxRepository) DeleteUser(ctx context.Context, id string, version int) error {
+	defer metrics.ObserveQuery("DeleteUser", time.Now())
+	// Iniciar una transacción para eliminar el usuario y sus recursos de forma atómica
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	// Deshacer la transacción si no se confirma
+	defer tx.Rollback(ctx)
+
+	// Marcar el usuario como eliminado y obtener la fecha de eliminación
+	var deletedAt time.Time
+	err = tx.QueryRow(ctx, queries["SoftDeleteUser"], id, version).Scan(&deletedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return p.rowError(ctx, "users", id)
+	}
+	if err != nil {
+		return err
+	}
+	// Eliminar en cascada los retos y empresas activos del usuario en un solo viaje a la base de datos
+	batch := &pgx.Batch{}
+	batch.Queue(queries["SoftDeleteUserChallenges"], deletedAt, id)
+	batch.Queue(queries["SoftDeleteUserCompanies"], deletedAt, id)
+	if err = tx.SendBatch(ctx, batch).Close(); err != nil {
+		return err
+	}
+	// Confirmar la transacción
+	return tx.Commit(ctx)
+}
+
+// RestoreUser es una función que restaura un usuario eliminado y los recursos que se eliminaron en cascada con él.
+func (p *PgxRepository) RestoreUser(ctx context.Context, id string) error {
+	defer metrics.ObserveQuery("RestoreUser", time.Now())
+	// Iniciar una transacción para restaurar el usuario y sus recursos de forma atómica
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	// Deshacer la transacción si no se confirma
+	defer tx.Rollback(ctx)
+
+	// Obtener la fecha de eliminación del usuario
+	var deletedAt time.Time
+	err = tx.QueryRow(ctx, queries["LockDeletedUser"], id).Scan(&deletedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%w: no deleted user found with id %s", repository.ErrNotFound, id)
+	}
+	if err != nil {
+		return err
+	}
+	// Restaurar el usuario y solo los recursos eliminados en cascada con él
+	batch := &pgx.Batch{}
+	batch.Queue(queries["RestoreUser"], id)
+	batch.Queue(queries["RestoreUserChallenges"], id, deletedAt)
+	batch.Queue(queries["RestoreUserCompanies"], id, deletedAt)
+	if err = tx.SendBatch(ctx, batch).Close(); err != nil {
+		return err
+	}
+	// Confirmar la transacción
+	return tx.Commit(ctx)
+}
+
+// GetUsers obtiene usuarios de la base de datos con paginación
+func (p *PgxRepository) GetUsers(ctx context.Context, page int, pageSize int) ([]*models.User, int, error) {
+	defer metrics.ObserveQuery("GetUsers", time.Now())
+	// Comprobar si la página y el tamaño de la página son válidos
+	if page < 1 || pageSize < 1 {
+		return nil, 0, errors.New("invalid page number or page size")
+	}
+	// Obtener la página de usuarios
+	rows, err := p.pool.Query(ctx, queries["ListUsers"], pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	var users []*models.User
+	for rows.Next() {
+		var user = models.User{}
+		if err = rows.Scan(&user.Id, &user.Fullname, &user.Email, &user.AvatarPath, &user.CreatedAt, &user.UpdatedAt, &user.Version); err != nil {
+			return nil, 0, err
+		}
+		users = append(users, &user)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	// Contar el número total de usuarios
+	var count int
+	if err = p.pool.QueryRow(ctx, queries["CountUsers"]).Scan(&count); err != nil {
+		return nil, 0, err
+	}
+	return users, count, nil
+}
+
+// GetUserById es una función que obtiene un usuario de la base de datos por su ID.
+// Devuelve ErrNotFound si no existe o fue eliminado.
+func (p *PgxRepository) GetUserById(ctx context.Context, id string) (*models.User, error) {
+	defer metrics.ObserveQuery("GetUserById", time.Now())
+	var user = models.User{}
+	err := p.pool.QueryRow(ctx, queries["GetUserById"], id).
+		Scan(&user.Id, &user.Fullname, &user.Email, &user.Role, &user.AvatarPath, &user.CreatedAt, &user.UpdatedAt, &user.Version)
+	// El usuario no existe o fue eliminado
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("%w: no user found with id %s", repository.ErrNotFound, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetUserByEmail es una función que obtiene un usuario de la base de datos por su email, o nil si no existe.
+func (p *PgxRepository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	defer metrics.ObserveQuery("GetUserByEmail", time.Now())
+	var user = models.User{}
+	err := p.pool.QueryRow(ctx, queries["GetUserByEmail"], email).
+		Scan(&user.Id, &user.Fullname, &user.Email, &user.Password, &user.Role, &user.AvatarPath, &user.CreatedAt, &user.UpdatedAt, &user.Version)
+	// Ningún usuario activo tiene el email
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+//********************************************************************************************************************
+//************************************************************* CHALLENGE ********************************************
+//********************************************************************************************************************
+
+// InsertChallenge es una función que inserta un nuevo reto en la base de datos.
+func (p *PgxRepository) InsertChallenge(ctx context.Context, challenge *models.Challenge) error {
+	defer metrics.ObserveQuery("InsertChallenge", time.Now())
+	if err := p.ping(ctx); err != nil {
+		return err
+	}
+	// Verificar que el reto no sea nil
+	if challenge == nil {
+		return errors.New("challenge cannot be nil")
+	}
+	return p.pool.QueryRow(ctx, queries["InsertChallenge"], challenge.Id, challenge.Title, challenge.Description, challenge.Difficulty, challenge.UserID).
+		Scan(&challenge.CreatedAt, &challenge.UpdatedAt, &challenge.Version)
+}
+
+// UpdateChallenge es una función que actualiza un reto en la base de datos.
+func (p *PgxRepository) UpdateChallenge(ctx context.Context, id string, challenge *models.Challenge) error {
+	defer metrics.ObserveQuery("UpdateChallenge", time.Now())
+	if err := p.ping(ctx); err != nil {
+		return err
+	}
+	// Verificar que el reto no sea nil
+	if challenge == nil {
+		return errors.New("challenge cannot be nil")
+	}
+	// Actualizar el reto; los triggers de la tabla actualizan updated_at y version
+	err := p.pool.QueryRow(ctx, queries["UpdateChallenge"], challenge.Title, challenge.Description, challenge.Difficulty, challenge.UserID, id, challenge.Version).
+		Scan(&challenge.CreatedAt, &challenge.UpdatedAt, &challenge.Version)
+	// El reto no existe, fue eliminado o cambió de versión
+	if errors.Is(err, pgx.ErrNoRows) {
+		return p.rowError(ctx, "challenges", id)
+	}
+	return err
+}
+
+// PatchChallenge es una función que actualiza solo los campos dados de un reto.
+func (p *PgxRepository) PatchChallenge(ctx context.Context, id string, challenge *models.Challenge, fields []string) error {
+	defer metrics.ObserveQuery("PatchChallenge", time.Now())
+	return p.patch(ctx, "challenges", id, challenge.Version, challengeColumns(challenge), fields, &challenge.CreatedAt, &challenge.UpdatedAt, &challenge.Version)
+}
+
+// DeleteChallenge es una función que elimina un reto de la base de datos.
+func (p *PgxRepository) DeleteChallenge(ctx context.Context, id string, version int) error {
+	defer metrics.ObserveQuery("DeleteChallenge", time.Now())
+	if err := p.ping(ctx); err != nil {
+		return err
+	}
+	return p.softDelete(ctx, "challenges", id, version)
+}
+
+// RestoreChallenge es una función que restaura un reto eliminado.
+func (p *PgxRepository) RestoreChallenge(ctx context.Context, id string) error {
+	defer metrics.ObserveQuery("RestoreChallenge", time.Now())
+	return p.restore(ctx, "challenges", id)
+}
+
+// GetChallenges es una función que obtiene retos de la base de datos con paginación.
+func (p *PgxRepository) GetChallenges(ctx context.Context, filter models.ChallengeFilter, page int, pageSize int) ([]*models.Challenge, int, error) {
+	defer metrics.ObserveQuery("GetChallenges", time.Now())
+	// Comprobar si la página y el tamaño de la página son válidos
+	if page < 1 || pageSize < 1 {
+		return nil, 0, errors.New("invalid page number or page size")
+	}
+	// Obtener la página de retos y el total en un solo viaje a la base de datos
+	batch := &pgx.Batch{}
+	batch.Queue(queries["ListChallenges"], filter.UserID, filter.Difficulties, pageSize, (page-1)*pageSize)
+	batch.Queue(queries["CountChallenges"], filter.UserID, filter.Difficulties)
+	results := p.pool.SendBatch(ctx, batch)
+	defer results.Close()
+	rows, err := results.Query()
+	if err != nil {
+		return nil, 0, err
+	}
+	var challenges []*models.Challenge
+	for rows.Next() {
+		var challenge = models.Challenge{}
+		if err = scanChallenge(rows, &challenge); err != nil {
+			rows.Close()
+			return nil, 0, err
+		}
+		challenges = append(challenges, &challenge)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	var count int
+	if err = results.QueryRow().Scan(&count); err != nil {
+		return nil, 0, err
+	}
+	return challenges, count, nil
+}
+
+// GetChallengeStats es una función que cuenta los retos no eliminados que cumplen el filtro por nivel de dificultad.
+func (p *PgxRepository) GetChallengeStats(ctx context.Context, filter models.ChallengeFilter) (map[int]int, error) {
+	defer metrics.ObserveQuery("GetChallengeStats", time.Now())
+	rows, err := p.pool.Query(ctx, queries["GetChallengeStats"], filter.UserID, filter.Difficulties)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	stats := map[int]int{}
+	for rows.Next() {
+		var level, count int
+		if err = rows.Scan(&level, &count); err != nil {
+			return nil, err
+		}
+		stats[level] = count
+	}
+	return stats, rows.Err()
+}
+
+// GetChallengeById es una función que obtiene un reto de la base de datos por su ID.
+// Devuelve ErrNotFound si no existe o fue eliminado.
+func (p *PgxRepository) GetChallengeById(ctx context.Context, id string) (*models.Challenge, error) {
+	defer metrics.ObserveQuery("GetChallengeById", time.Now())
+	var challenge = models.Challenge{}
+	err := scanChallenge(p.pool.QueryRow(ctx, queries["GetChallengeById"], id), &challenge)
+	// El reto no existe o fue eliminado
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("%w: no challenge found with id %s", repository.ErrNotFound, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &challenge, nil
+}
+
+//********************************************************************************************************************
+//************************************************************* COMPANY **********************************************
+//********************************************************************************************************************
+
+// InsertCompany es una función que inserta una nueva empresa en la base de datos.
+func (p *PgxRepository) InsertCompany(ctx context.Context, company *models.Company) error {
+	defer metrics.ObserveQuery("InsertCompany", time.Now())
+	if err := p.ping(ctx); err != nil {
+		return err
+	}
+	// Verificar que la empresa no sea nil
+	if company == nil {
+		return errors.New("company cannot be nil")
+	}
+	return p.pool.QueryRow(ctx, queries["InsertCompany"], company.Id, company.Name, company.ImagePath, company.Location, company.Industry, company.UserID).
+		Scan(&company.CreatedAt, &company.UpdatedAt, &company.Version)
+}
+
+// UpdateCompany es una función que actualiza una empresa en la base de datos.
+func (p *PgxRepository) UpdateCompany(ctx context.Context, id string, company *models.Company) error {
+	defer metrics.ObserveQuery("UpdateCompany", time.Now())
+	if err := p.ping(ctx); err != nil {
+		return err
+	}
+	// Verificar que la empresa no sea nil
+	if company == nil {
+		return errors.New("company cannot be nil")
+	}
+	// Actualizar la empresa; los triggers de la tabla actualizan updated_at y version
+	err := p.pool.QueryRow(ctx, queries["UpdateCompany"], company.Name, company.Location, company.Industry, company.UserID, id, company.Version).
+		Scan(&company.CreatedAt, &company.UpdatedAt, &company.Version)
+	// La empresa no existe, fue eliminada o cambió de versión
+	if errors.Is(err, pgx.ErrNoRows) {
+		return p.rowError(ctx, "companies", id)
+	}
+	return err
+}
+
+// PatchCompany es una función que actualiza solo los campos dados de una empresa.
+func (p *PgxRepository) PatchCompany(ctx context.Context, id string, company *models.Company, fields []string) error {
+	defer metrics.ObserveQuery("PatchCompany", time.Now())
+	return p.patch(ctx, "companies", id, company.Version, companyColumns(company), fields, &company.CreatedAt, &company.UpdatedAt, &company.Version)
+}
+
+// DeleteCompany es una función que elimina una empresa de la base de datos por su ID.
+func (p *PgxRepository) DeleteCompany(ctx context.Context, id string, version int) error {
+	defer metrics.ObserveQuery("DeleteCompany", time.Now())
+	if err := p.ping(ctx); err != nil {
+		return err
+	}
+	return p.softDelete(ctx, "companies", id, version)
+}
+
+// RestoreCompany es una función que restaura una empresa eliminada.
+func (p *PgxRepository) RestoreCompany(ctx context.Context, id string) error {
+	defer metrics.ObserveQuery("RestoreCompany", time.Now())
+	return p.restore(ctx, "companies", id)
+}
+
+// GetCompanies es una función que obtiene empresas de la base de datos con paginación.
+func (p *PgxRepository) GetCompanies(ctx context.Context, page int, pageSize int) ([]*models.Company, int, error) {
+	defer metrics.ObserveQuery("GetCompanies", time.Now())
+	return p.listCompanies(ctx, page, pageSize, "")
+}
+
+// GetCompaniesByUser es una función que obtiene una lista de empresas de un usuario.
+func (p *PgxRepository) GetCompaniesByUser(ctx context.Context, userID string, page int, pageSize int) ([]*models.Company, int, error) {
+	defer metrics.ObserveQuery("GetCompaniesByUser", time.Now())
+	return p.listCompanies(ctx, page, pageSize, userID)
+}
+
+// listCompanies es una función que obtiene una página de empresas no eliminadas del usuario dado, o de todos
+// los usuarios si userID está vacío.
+func (p *PgxRepository) listCompanies(ctx context.Context, page int, pageSize int, userID string) ([]*models.Company, int, error) {
+	// Comprobar si la página y el tamaño de la página son válidos
+	if page < 1 || pageSize < 1 {
+		return nil, 0, errors.New("invalid page number or page size")
+	}
+	// Obtener la página de empresas y el total en un solo viaje a la base de datos
+	batch := &pgx.Batch{}
+	batch.Queue(queries["ListCompanies"], userID, pageSize, (page-1)*pageSize)
+	batch.Queue(queries["CountCompanies"], userID)
+	results := p.pool.SendBatch(ctx, batch)
+	defer results.Close()
+	rows, err := results.Query()
+	if err != nil {
+		return nil, 0, err
+	}
+	var companies []*models.Company
+	for rows.Next() {
+		var company = models.Company{}
+		if err = scanCompany(rows, &company); err != nil {
+			rows.Close()
+			return nil, 0, err
+		}
+		companies = append(companies, &company)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	var count int
+	if err = results.QueryRow().Scan(&count); err != nil {
+		return nil, 0, err
+	}
+	return companies, count, nil
+}
+
+// GetCompanyById es una función que obtiene una empresa de la base de datos por su ID.
+// Devuelve ErrNotFound si no existe o fue eliminada.
+func (p *PgxRepository) GetCompanyById(ctx context.Context, id string) (*models.Company, error) {
+	defer metrics.ObserveQuery("GetCompanyById", time.Now())
+	var company = models.Company{}
+	err := scanCompany(p.pool.QueryRow(ctx, queries["GetCompanyById"], id), &company)
+	// La empresa no existe o fue eliminada
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("%w: no company found with id %s", repository.ErrNotFound, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &company, nil
+}
+
+//********************************************************************************************************************
+//************************************************************* SOFT DELETE Y PATCH **********************************
+//********************************************************************************************************************
+
+// softDelete es una función que marca como eliminada una fila activa de la tabla dada (challenges o companies).
+// Si version no es 0, solo la elimina si coincide con la versión actual.
+func (p *PgxRepository) softDelete(ctx context.Context, table string, id string, version int) error {
+	tag, err := p.pool.Exec(ctx, queries[tableQueries[table].softDelete], id, version)
+	if err != nil {
+		return err
+	}
+	// Verificar que la fila existiera y no estuviera eliminada
+	if tag.RowsAffected() == 0 {
+		return p.rowError(ctx, table, id)
+	}
+	return nil
+}
+
+// rowError es una función que explica por qué una modificación no afectó a la fila activa de la tabla dada:
+// la fila no existe o fue eliminada (ErrNotFound), o su versión cambió (ErrVersionConflict).
+func (p *PgxRepository) rowError(ctx context.Context, table string, id string) error {
+	var exists bool
+	if err := p.pool.QueryRow(ctx, queries[tableQueries[table].isActive], id).Scan(&exists); err != nil {
+		return err
+	}
+	return unaffectedRowError(table, id, exists)
+}
+
+// restore es una función que restaura una fila eliminada de la tabla dada (challenges o companies),
+// siempre que su dueño no esté eliminado.
+func (p *PgxRepository) restore(ctx context.Context, table string, id string) error {
+	tag, err := p.pool.Exec(ctx, queries[tableQueries[table].restore], id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: no deleted %s found with id %s, or its owner is deleted", repository.ErrNotFound, table, id)
+	}
+	return nil
+}
+
+// patch es una función que actualiza solo las columnas dadas de una fila activa y lee en dest
+// su created_at, updated_at y version nuevos.
+func (p *PgxRepository) patch(ctx context.Context, table string, id string, version int, columns map[string]interface{}, fields []string, dest ...interface{}) error {
+	query, args, err := patchQuery(table, id, version, columns, fields)
+	if err != nil {
+		return err
+	}
+	// Actualizar la fila; los triggers de la tabla actualizan updated_at y version
+	err = p.pool.QueryRow(ctx, query, args...).Scan(dest...)
+	// La fila no existe, fue eliminada o cambió de versión
+	if errors.Is(err, pgx.ErrNoRows) {
+		return p.rowError(ctx, table, id)
+	}
+	return err
+}
@@ -6,14 +6,27 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"log"
-	_ "github.com/lib/pq"
+	"strings"
+	"time"
+	"github.com/lib/pq"
+	"talentpitchGo/metrics"
 	"talentpitchGo/models"
+	"talentpitchGo/logging"
+	"talentpitchGo/ratelimit"
+	"talentpitchGo/repository"
+	"talentpitchGo/storage"
 )
 
-// PostgresRepositoy es una estructura que contiene la conexión a la base de datos.
+// PostgresRepositoy es una estructura que contiene la conexión a la base de datos y sus consultas preparadas.
+// Dentro de una unidad de trabajo la conexión es la transacción de WithTx.
 type PostgresRepositoy struct {
-	db *sql.DB
+	db      conn
+	queries *statements
+}
+
+// scanner es la interfaz de una fila que se puede leer: *sql.Row o *sql.Rows
+type scanner interface {
+	Scan(dest ...interface{}) error
 }
 
 
@@ -24,18 +37,23 @@ func NewPostgresRepository(url string) (*PostgresRepositoy, error ){
 	if err != nil {
 		return nil, err
 	}
-	return &PostgresRepositoy{db: db}, nil
+	// Preparar las consultas una sola vez; falla si alguna no compila contra el esquema
+	queries, err := prepareStatements(context.Background(), db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	// Exponer las métricas del pool de conexiones
+	if err := metrics.RegisterDBStats(db, "postgres"); err != nil {
+		return nil, err
+	}
+	return &PostgresRepositoy{db: &tracedDB{DB: db}, queries: queries}, nil
 }
 
 
 // InsertUser es una función que inserta un nuevo usuario en la base de datos.
 func (p *PostgresRepositoy) InsertUser(ctx context.Context ,user *models.User) error {
-	// Verificar que la base de datos está disponible
-    _, err := p.db.ExecContext(ctx, "SELECT 1")
-    if err != nil {
-        return errors.New("database is not available")
-    }
-
+	defer metrics.ObserveQuery("InsertUser", time.Now())
 	// Verificar que el repositorio no sea nil
     if p == nil {
         return errors.New("repository cannot be nil")
@@ -51,67 +69,121 @@ func (p *PostgresRepositoy) InsertUser(ctx context.Context ,user *models.User) e
         return errors.New("context cannot be nil")
     }
 
+	// Verificar que la base de datos está disponible
+    _, err := p.queries.exec(ctx, p.db, "Ping")
+    if err != nil {
+        return errors.New("database is not available")
+    }
+
     // Verificar que el usuario no sea nil
     if user == nil {
         return errors.New("user cannot be nil")
     }
 
-    // Verificar que los campos necesarios del usuario no estén vacíos
+    // Los campos se validan en el paquete repository; aquí solo se exige el ID
     if user.Id == "" {
         return errors.New("user id cannot be empty")
-    }
-    if user.Fullname == "" {
-        return errors.New("user fullname cannot be empty")
-    }
-    if user.Email == "" {
-        return errors.New("user email cannot be empty")
-    }
-    if user.Password == "" {
-        return errors.New("user password cannot be empty")
     }
 	// Insertar un nuevo usuario en la base de datos
-	_, err = p.db.ExecContext(ctx, "INSERT INTO users (id, fullname, email, password) VALUES ($1, $2, $3, $4)",user.Id, user.Fullname, user.Email, user.Password)
-	return err
+	return p.queries.queryRow(ctx, p.db, "InsertUser",user.Id, user.Fullname, user.Email, user.Password).Scan(&user.CreatedAt, &user.UpdatedAt, &user.Version)
 }
 
 
 // Update actualiza un elemento en la base de datos
 func (p *PostgresRepositoy) UpdateUser(ctx context.Context ,id string, user *models.User)  error {
-    // Ejecutar la consulta de actualización
-	_, err := p.db.ExecContext(ctx, "UPDATE users SET fullname = $1, email = $2 WHERE id = $3", user.Fullname, user.Email, id)
-	// Manejar el error si existe
+	defer metrics.ObserveQuery("UpdateUser", time.Now())
+	// Ejecutar la consulta de actualización; los triggers de la tabla actualizan updated_at y version
+	err := p.queries.queryRow(ctx, p.db, "UpdateUser",
+		user.Fullname, user.Email, id, user.Version).Scan(&user.CreatedAt, &user.UpdatedAt, &user.Version)
+	// El usuario no existe, fue eliminado o cambió de versión
+	if errors.Is(err, sql.ErrNoRows) {
+		return p.rowError(ctx, "users", id)
+	}
+	// Devolver el error si la actualización falló
+    return  err
+}
+
+
+// PatchUser es una función que actualiza solo los campos dados de un usuario.
+func (p *PostgresRepositoy) PatchUser(ctx context.Context, id string, user *models.User, fields []string) error {
+	defer metrics.ObserveQuery("PatchUser", time.Now())
+	// Actualizar las columnas modificadas
+	return p.patch(ctx, "users", id, user.Version, userColumns(user), fields, &user.CreatedAt, &user.UpdatedAt, &user.Version)
+}
+
+
+// DeleteUser es una función que elimina lógicamente un usuario junto con sus retos y empresas.
+// Los recursos del usuario se marcan con la misma fecha para poder restaurarlos juntos.
+func (p *PostgresRepositoy) DeleteUser(ctx context.Context, id string, version int) error {
+	defer metrics.ObserveQuery("DeleteUser", time.Now())
+	// Iniciar una transacción para eliminar el usuario y sus recursos de forma atómica
+	tx, err := p.db.BeginTx(ctx, nil)
 	if err != nil {
-		log.Fatal("Error closing database connection: ", err)
+		return err
 	}
-	// Cerrar la conexión a la base de datos
-	defer func() {
-		if err != nil {
-			log.Fatal("Error closing database connection: ", err)
-		}
-	}()
-	// Devolver nil si la actualización fue exitosa
-    return  nil
+	// Deshacer la transacción si no se confirma
+	defer tx.Rollback()
+
+	// Marcar el usuario como eliminado y obtener la fecha de eliminación
+	var deletedAt time.Time
+	err = p.queries.queryRow(ctx, tx, "SoftDeleteUser", id, version).Scan(&deletedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return p.rowError(ctx, "users", id)
+	}
+	if err != nil {
+		return err
+	}
+	// Eliminar en cascada los retos y empresas activos del usuario
+	if _, err = p.queries.exec(ctx, tx, "SoftDeleteUserChallenges", deletedAt, id); err != nil {
+		return err
+	}
+	if _, err = p.queries.exec(ctx, tx, "SoftDeleteUserCompanies", deletedAt, id); err != nil {
+		return err
+	}
+	// Confirmar la transacción
+	return tx.Commit()
 }
 
 
-// Delete elimina un elemento de la base de datos
-func (p *PostgresRepositoy) DeleteUser(ctx context.Context, id string) error {
-    
-	log.Println("Deleting user with id: ", id)
-    // Ejecutar la consulta de eliminación
-    _, err := p.db.ExecContext(ctx, "DELETE FROM users WHERE id = $1 ", id)
-    // Manejar el error si existe
-    if err != nil {
-        return err
-    }
-    
-    // Devolver nil si la eliminación fue exitosa
-    return nil
+// RestoreUser es una función que restaura un usuario eliminado y los recursos que se eliminaron en cascada con él.
+func (p *PostgresRepositoy) RestoreUser(ctx context.Context, id string) error {
+	defer metrics.ObserveQuery("RestoreUser", time.Now())
+	// Iniciar una transacción para restaurar el usuario y sus recursos de forma atómica
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	// Deshacer la transacción si no se confirma
+	defer tx.Rollback()
+
+	// Obtener la fecha de eliminación del usuario
+	var deletedAt time.Time
+	err = p.queries.queryRow(ctx, tx, "LockDeletedUser", id).Scan(&deletedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: no deleted user found with id %s", repository.ErrNotFound, id)
+	}
+	if err != nil {
+		return err
+	}
+	// Restaurar el usuario
+	if _, err = p.queries.exec(ctx, tx, "RestoreUser", id); err != nil {
+		return err
+	}
+	// Restaurar solo los recursos eliminados en cascada, no los que se eliminaron antes por separado
+	if _, err = p.queries.exec(ctx, tx, "RestoreUserChallenges", id, deletedAt); err != nil {
+		return err
+	}
+	if _, err = p.queries.exec(ctx, tx, "RestoreUserCompanies", id, deletedAt); err != nil {
+		return err
+	}
+	// Confirmar la transacción
+	return tx.Commit()
 }
 
 
 // GetUsers obtiene usuarios de la base de datos con paginación
 func (p *PostgresRepositoy) GetUsers(ctx context.Context, page int, pageSize int) ([]*models.User, int, error) {
+	defer metrics.ObserveQuery("GetUsers", time.Now())
 	var users []*models.User
     // Comprobar si la página y el tamaño de la página son válidos
     if page < 1 || pageSize < 1 {
@@ -122,7 +194,7 @@ func (p *PostgresRepositoy) GetUsers(ctx context.Context, page int, pageSize int
     offset := (page - 1) * pageSize
 
     // Ejecutar la consulta para obtener usuarios
-    rows, err := p.db.QueryContext(ctx, "SELECT id, fullname, email, password FROM users ORDER BY id LIMIT $1 OFFSET $2", pageSize, offset)
+    rows, err := p.queries.query(ctx, p.db, "ListUsers", pageSize, offset)
     if err != nil {
         return nil, 0, err
     }
@@ -132,7 +204,7 @@ func (p *PostgresRepositoy) GetUsers(ctx context.Context, page int, pageSize int
     
     for rows.Next() {
 		var user = models.User{}
-        if err = rows.Scan(&user.Id, &user.Fullname, &user.Email, &user.Password); err != nil {
+        if err = rows.Scan(&user.Id, &user.Fullname, &user.Email, &user.AvatarPath, &user.CreatedAt, &user.UpdatedAt, &user.Version); err != nil {
             return nil, 0, err
         }
         users = append(users, &user)
@@ -144,7 +216,7 @@ func (p *PostgresRepositoy) GetUsers(ctx context.Context, page int, pageSize int
     }
 
     // Ejecutar la consulta para contar el número total de usuarios
-    row := p.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM users")
+    row := p.queries.queryRow(ctx, p.db, "CountUsers")
     var count int
     err = row.Scan(&count)
     if err != nil {
@@ -156,67 +228,41 @@ func (p *PostgresRepositoy) GetUsers(ctx context.Context, page int, pageSize int
 
 
 // GetUserById es una función que obtiene un usuario de la base de datos por su ID.
+// Devuelve ErrNotFound si no existe o fue eliminado.
 func (p *PostgresRepositoy) GetUserById(ctx context.Context, id string) (*models.User, error) {
+	defer metrics.ObserveQuery("GetUserById", time.Now())
 	// Crear una nueva estructura de usuario
 	var user = models.User{}
 	// Obtener un usuario de la base de datos por su ID
-	rows, err := p.db.QueryContext(ctx, "SELECT id, fullname, email FROM users WHERE id = $1", id)
+	err := p.queries.queryRow(ctx, p.db, "GetUserById", id).
+		Scan(&user.Id, &user.Fullname, &user.Email, &user.Role, &user.AvatarPath, &user.CreatedAt, &user.UpdatedAt, &user.Version)
+	// El usuario no existe o fue eliminado
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: no user found with id %s", repository.ErrNotFound, id)
+	}
 	// Manejar el error si existe
 	if err != nil {
 		return nil, err
 	}
-	// Cerrar la conexión a la base de datos
-	defer func() {
-		err = rows.Close()
-		if err != nil {
-			log.Fatal("Error closing database connection: ", err)
-		}
-	}()
-	// Iterar sobre los resultados de la consulta
-	for rows.Next() {
-		if err = rows.Scan(&user.Id, &user.Fullname, &user.Email); err == nil {
-			return &user, nil
-		}
-	}
-
-	// Iterar sobre los resultados de la consulta
-	if !rows.Next() {
-		return nil, fmt.Errorf("no user found with id %s", id)
-	}
-    // Manejar el error si existe
-	if err = rows.Err(); err != nil {
-		return nil, err
-	}
 	// Devolver el usuario
 	return &user, nil
 }
 
 
-// GetUserByEmail es una función que obtiene un usuario de la base de datos por su email.
+// GetUserByEmail es una función que obtiene un usuario de la base de datos por su email, o nil si no existe.
 func (p *PostgresRepositoy) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	defer metrics.ObserveQuery("GetUserByEmail", time.Now())
 	// Crear una nueva estructura de usuario
 	var user = models.User{}
 	// Obtener un usuario de la base de datos por su email
-	rows, err := p.db.QueryContext(ctx, "SELECT id, fullname, email, password FROM users WHERE email = $1", email)
-	// Manejar el error si existe	
-    if err != nil {
-		return nil, err
+	err := p.queries.queryRow(ctx, p.db, "GetUserByEmail", email).
+		Scan(&user.Id, &user.Fullname, &user.Email, &user.Password, &user.Role, &user.AvatarPath, &user.CreatedAt, &user.UpdatedAt, &user.Version)
+	// Ningún usuario activo tiene el email
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
 	}
-	// Cerrar la conexión a la base de datos
-	defer func() {
-		err = rows.Close()
-		if err != nil {
-			log.Fatal("Error closing database connection: ", err)
-		}
-	}()
-	// Iterar sobre los resultados de la consulta
-	for rows.Next() {
-		if err = rows.Scan(&user.Id, &user.Fullname, &user.Email, &user.Password); err == nil {
-			return &user, nil
-		}
-	}
-    // Manejar el error si existe
-	if err = rows.Err(); err != nil {
+	// Manejar el error si existe
+	if err != nil {
 		return nil, err
 	}
 	// Devolver el usuario
@@ -224,9 +270,13 @@ func (p *PostgresRepositoy) GetUserByEmail(ctx context.Context, email string) (*
 }
 
 
-// Close es una función que cierra la conexión a la base de datos.
+// Close es una función que libera las consultas preparadas y cierra la conexión a la base de datos.
 func (p *PostgresRepositoy) Close() error {
-	return p.db.Close()
+	// Dentro de una unidad de trabajo la conexión no se cierra y las consultas siguen preparadas
+	if err := p.db.Close(); err != nil {
+		return err
+	}
+	return p.queries.Close()
 }
 
 
@@ -236,12 +286,7 @@ func (p *PostgresRepositoy) Close() error {
 
 // InsertChallenge es una función que inserta un nuevo reto en la base de datos.
 func (p *PostgresRepositoy) InsertChallenge(ctx context.Context, challenge *models.Challenge) error {
-	// Verificar que la base de datos está disponible
-	_, err := p.db.ExecContext(ctx, "SELECT 1")
-	if err != nil {
-		return errors.New("database is not available")
-	}
-
+	defer metrics.ObserveQuery("InsertChallenge", time.Now())
 	// Verificar que el repositorio no sea nil
 	if p == nil {
 		return errors.New("repository cannot be nil")
@@ -257,39 +302,25 @@ func (p *PostgresRepositoy) InsertChallenge(ctx context.Context, challenge *mode
 		return errors.New("context cannot be nil")
 	}
 
+	// Verificar que la base de datos está disponible
+	_, err := p.queries.exec(ctx, p.db, "Ping")
+	if err != nil {
+		return errors.New("database is not available")
+	}
+
 	// Verificar que el reto no sea nil
 	if challenge == nil {
 		return errors.New("challenge cannot be nil")
 	}
 
-	// Verificar que los campos necesarios del reto no estén vacíos
-	if challenge.Title == "" {
-		return errors.New("challenge title cannot be empty")
-	}
-	if challenge.Description == "" {
-		return errors.New("challenge description cannot be empty")
-	}
-	if challenge.Difficulty == 0 {
-		return errors.New("challenge difficulty cannot be empty")
-	}
-	if challenge.UserID == "" {
-		return errors.New("challenge user_id cannot be empty")
-	}
-
 	// Insertar un nuevo reto en la base de datos
-	_, err = p.db.ExecContext(ctx, "INSERT INTO challenges (id, title, description, difficulty, user_id) VALUES ($1, $2, $3, $4, $5)",challenge.Id, challenge.Title, challenge.Description, challenge.Difficulty, challenge.UserID)
-	return err
+	return p.queries.queryRow(ctx, p.db, "InsertChallenge",challenge.Id, challenge.Title, challenge.Description, challenge.Difficulty, challenge.UserID).Scan(&challenge.CreatedAt, &challenge.UpdatedAt, &challenge.Version)
 }
 
 
 // UpdateChallenge es una función que actualiza un reto en la base de datos.
 func (p *PostgresRepositoy) UpdateChallenge(ctx context.Context ,id string, challenge *models.Challenge) error {
-	// Verificar que la base de datos está disponible
-	_, err := p.db.ExecContext(ctx, "SELECT 1")
-	if err != nil {
-		return errors.New("database is not available")
-	}
-
+	defer metrics.ObserveQuery("UpdateChallenge", time.Now())
 	// Verificar que el repositorio no sea nil
 	if p == nil {
 		return errors.New("repository cannot be nil")
@@ -305,39 +336,39 @@ func (p *PostgresRepositoy) UpdateChallenge(ctx context.Context ,id string, chal
 		return errors.New("context cannot be nil")
 	}
 
+	// Verificar que la base de datos está disponible
+	_, err := p.queries.exec(ctx, p.db, "Ping")
+	if err != nil {
+		return errors.New("database is not available")
+	}
+
 	// Verificar que el reto no sea nil
 	if challenge == nil {
 		return errors.New("challenge cannot be nil")
 	}
 
-	// Verificar que los campos necesarios del reto no estén vacíos
-	if challenge.Title == "" {
-		return errors.New("challenge title cannot be empty")
-	}
-	if challenge.Description == "" {
-		return errors.New("challenge description cannot be empty")
-	}
-	if challenge.Difficulty == 0 {
-		return errors.New("challenge difficulty cannot be empty")
+	// Actualizar un reto en la base de datos; los triggers de la tabla actualizan updated_at y version
+	err = p.queries.queryRow(ctx, p.db, "UpdateChallenge",
+		challenge.Title, challenge.Description, challenge.Difficulty, challenge.UserID, id, challenge.Version).Scan(&challenge.CreatedAt, &challenge.UpdatedAt, &challenge.Version)
+	// El reto no existe, fue eliminado o cambió de versión
+	if errors.Is(err, sql.ErrNoRows) {
+		return p.rowError(ctx, "challenges", id)
 	}
-	if challenge.UserID == "" {
-		return errors.New("challenge user_id cannot be empty")
-	}
-
-	// Actualizar un reto en la base de datos
-	_, err = p.db.ExecContext(ctx, "UPDATE challenges SET title = $1, description = $2, difficulty = $3, user_id = $4 WHERE id = $5", challenge.Title, challenge.Description, challenge.Difficulty, challenge.UserID, id)
 	return err
 }
 
 
-// DeleteChallenge es una función que elimina un reto de la base de datos.
-func (p *PostgresRepositoy) DeleteChallenge(ctx context.Context, id string) error {
-	// Verificar que la base de datos está disponible
-	_, err := p.db.ExecContext(ctx, "SELECT 1")
-	if err != nil {
-		return errors.New("database is not available")
-	}
+// PatchChallenge es una función que actualiza solo los campos dados de un reto.
+func (p *PostgresRepositoy) PatchChallenge(ctx context.Context, id string, challenge *models.Challenge, fields []string) error {
+	defer metrics.ObserveQuery("PatchChallenge", time.Now())
+	// Actualizar las columnas modificadas
+	return p.patch(ctx, "challenges", id, challenge.Version, challengeColumns(challenge), fields, &challenge.CreatedAt, &challenge.UpdatedAt, &challenge.Version)
+}
 
+
+// DeleteChallenge es una función que elimina un reto de la base de datos.
+func (p *PostgresRepositoy) DeleteChallenge(ctx context.Context, id string, version int) error {
+	defer metrics.ObserveQuery("DeleteChallenge", time.Now())
 	// Verificar que el repositorio no sea nil
 	if p == nil {
 		return errors.New("repository cannot be nil")
@@ -353,14 +384,28 @@ func (p *PostgresRepositoy) DeleteChallenge(ctx context.Context, id string) erro
 		return errors.New("context cannot be nil")
 	}
 
-	// Eliminar un reto de la base de datos
-	_, err = p.db.ExecContext(ctx, "DELETE FROM challenges WHERE id = $1", id)
-	return err
+	// Verificar que la base de datos está disponible
+	_, err := p.queries.exec(ctx, p.db, "Ping")
+	if err != nil {
+		return errors.New("database is not available")
+	}
+
+	// Eliminar lógicamente un reto de la base de datos
+	return p.softDelete(ctx, "challenges", id, version)
+}
+
+
+// RestoreChallenge es una función que restaura un reto eliminado.
+func (p *PostgresRepositoy) RestoreChallenge(ctx context.Context, id string) error {
+	defer metrics.ObserveQuery("RestoreChallenge", time.Now())
+	// Restaurar el reto solo si su dueño no está eliminado
+	return p.restore(ctx, "challenges", id)
 }
 
 
 // GetChallenges es una función que obtiene retos de la base de datos con paginación.
-func (p *PostgresRepositoy) GetChallenges(ctx context.Context, page int, pageSize int) ([]*models.Challenge, int, error) {
+func (p *PostgresRepositoy) GetChallenges(ctx context.Context, filter models.ChallengeFilter, page int, pageSize int) ([]*models.Challenge, int, error) {
+	defer metrics.ObserveQuery("GetChallenges", time.Now())
 	var challenges []*models.Challenge
 	// Comprobar si la página y el tamaño de la página son válidos
 	if page < 1 || pageSize < 1 {
@@ -369,9 +414,11 @@ func (p *PostgresRepositoy) GetChallenges(ctx context.Context, page int, pageSiz
 
 	// Calcular el offset
 	offset := (page - 1) * pageSize
+	// Los filtros vacíos no filtran
+	userId, difficulties := filter.UserID, pq.Array(filter.Difficulties)
 
 	// Ejecutar la consulta para obtener retos
-	rows, err := p.db.QueryContext(ctx, "SELECT id, title, description, difficulty, user_id FROM challenges ORDER BY id LIMIT $1 OFFSET $2", pageSize, offset)
+	rows, err := p.queries.query(ctx, p.db, "ListChallenges", userId, difficulties, pageSize, offset)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -380,7 +427,7 @@ func (p *PostgresRepositoy) GetChallenges(ctx context.Context, page int, pageSiz
 	// Iterar sobre los resultados
 	for rows.Next() {
 		var challenge = models.Challenge{}
-		if err = rows.Scan(&challenge.Id, &challenge.Title, &challenge.Description, &challenge.Difficulty, &challenge.UserID); err != nil {
+		if err = scanChallenge(rows, &challenge); err != nil {
 			return nil, 0, err
 		}
 		challenges = append(challenges, &challenge)
@@ -392,7 +439,7 @@ func (p *PostgresRepositoy) GetChallenges(ctx context.Context, page int, pageSiz
 	}
 
 	// Ejecutar la consulta para contar el número total de retos
-	row := p.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM challenges")
+	row := p.queries.queryRow(ctx, p.db, "CountChallenges", userId, difficulties)
 	var count int
 	err = row.Scan(&count)
 	if err != nil {
@@ -403,49 +450,62 @@ func (p *PostgresRepositoy) GetChallenges(ctx context.Context, page int, pageSiz
 	return challenges, count, nil
 }
 
-
-// GetChallengeById es una función que obtiene un reto de la base de datos por su ID.
-func (p *PostgresRepositoy) GetChallengeById(ctx context.Context, id string) (*models.Challenge, error) {
-	// Crear una nueva estructura de reto
-	var challenge = models.Challenge{}
-	// Obtener un reto de la base de datos por su ID
-	rows, err := p.db.QueryContext(ctx, "SELECT id, title, description, difficulty, user_id FROM challenges WHERE id = $1", id)
-	
-	// Manejar el error si existe
+// GetChallengeStats es una función que cuenta los retos no eliminados que cumplen el filtro por nivel de dificultad.
+func (p *PostgresRepositoy) GetChallengeStats(ctx context.Context, filter models.ChallengeFilter) (map[int]int, error) {
+	defer metrics.ObserveQuery("GetChallengeStats", time.Now())
+	// Contar los retos por nivel
+	rows, err := p.queries.query(ctx, p.db, "GetChallengeStats", filter.UserID, pq.Array(filter.Difficulties))
 	if err != nil {
 		return nil, err
 	}
-	// Cerrar la conexión a la base de datos
-	defer func() {
-		err = rows.Close()
-		if err != nil {
-			log.Fatal("Error closing database connection: ", err)
-		}
-	}()
-	// Iterar sobre los resultados de la consulta
+	defer rows.Close()
+	stats := map[int]int{}
 	for rows.Next() {
-		if err = rows.Scan(&challenge.Id, &challenge.Title, &challenge.Description, &challenge.Difficulty, &challenge.UserID); err == nil {
-			return &challenge, nil
+		var level, count int
+		if err = rows.Scan(&level, &count); err != nil {
+			return nil, err
 		}
+		stats[level] = count
 	}
+	return stats, rows.Err()
+}
+
 
-	// Iterar sobre los resultados de la consulta
-	if !rows.Next() {
-		return nil, fmt.Errorf("no user found with id %s", id)
+// GetChallengeById es una función que obtiene un reto de la base de datos por su ID.
+// Devuelve ErrNotFound si no existe o fue eliminado.
+func (p *PostgresRepositoy) GetChallengeById(ctx context.Context, id string) (*models.Challenge, error) {
+	defer metrics.ObserveQuery("GetChallengeById", time.Now())
+	// Crear una nueva estructura de reto
+	var challenge = models.Challenge{}
+	// Obtener un reto de la base de datos por su ID
+	err := scanChallenge(p.queries.queryRow(ctx, p.db, "GetChallengeById", id), &challenge)
+	// El reto no existe o fue eliminado
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: no challenge found with id %s", repository.ErrNotFound, id)
 	}
-    // Manejar el error si existe
-	if err = rows.Err(); err != nil {
+	// Manejar el error si existe
+	if err != nil {
 		return nil, err
 	}
-	
 	// Devolver el reto
 	return &challenge, nil
 }
 
+// scanChallenge es una función que lee un reto de una fila con las columnas id, title, description, difficulty,
+// user_id, created_at, updated_at y version. Las columnas que admiten NULL se leen como cadenas vacías.
+func scanChallenge(row scanner, challenge *models.Challenge) error {
+	var title, description, userId sql.NullString
+	if err := row.Scan(&challenge.Id, &title, &description, &challenge.Difficulty, &userId, &challenge.CreatedAt, &challenge.UpdatedAt, &challenge.Version); err != nil {
+		return err
+	}
+	challenge.Title, challenge.Description, challenge.UserID = title.String, description.String, userId.String
+	return nil
+}
+
 
 // Close es una función que cierra la conexión a la base de datos.
 func (p *PostgresRepositoy) CloseChallenge() error {
-	return p.db.Close()
+	return p.Close()
 }
 
 
@@ -455,12 +515,7 @@ func (p *PostgresRepositoy) CloseChallenge() error {
 
 // InsertCompany es una función que inserta una nueva empresa en la base de datos.
 func (p *PostgresRepositoy) InsertCompany(ctx context.Context, company *models.Company) error {
-	// Verificar que la base de datos está disponible
-	_, err := p.db.ExecContext(ctx, "SELECT 1")
-	if err != nil {
-		return errors.New("database is not available")
-	}
-
+	defer metrics.ObserveQuery("InsertCompany", time.Now())
 	// Verificar que el repositorio no sea nil
 	if p == nil {
 		return errors.New("repository cannot be nil")
@@ -476,42 +531,25 @@ func (p *PostgresRepositoy) InsertCompany(ctx context.Context, company *models.C
 		return errors.New("context cannot be nil")
 	}
 
+	// Verificar que la base de datos está disponible
+	_, err := p.queries.exec(ctx, p.db, "Ping")
+	if err != nil {
+		return errors.New("database is not available")
+	}
+
 	// Verificar que la empresa no sea nil
 	if company == nil {
 		return errors.New("company cannot be nil")
 	}
 
-	// Verificar que los campos necesarios de la empresa no estén vacíos
-	if company.Name == "" {
-		return errors.New("company name cannot be empty")
-	}
-	if company.ImagePath == "" {
-		return errors.New("company image_path cannot be empty")
-	}
-	if company.Location == "" {
-		return errors.New("company location cannot be empty")
-	}
-	if company.Industry == "" {
-		return errors.New("company industry cannot be empty")
-	}
-	if company.UserID == "" {
-		return errors.New("company user_id cannot be empty")
-	}
-
 	// Insertar una nueva empresa en la base de datos
-	_, err = p.db.ExecContext(ctx, "INSERT INTO companies (id, name, image_path, location, industry, user_id) VALUES ($1, $2, $3, $4, $5, $6)",company.Id, company.Name, company.ImagePath, company.Location, company.Industry, company.UserID)
-	return err
+	return p.queries.queryRow(ctx, p.db, "InsertCompany",company.Id, company.Name, company.ImagePath, company.Location, company.Industry, company.UserID).Scan(&company.CreatedAt, &company.UpdatedAt, &company.Version)
 }
 
 
 // UpdateCompany es una función que actualiza una empresa en la base de datos.
 func (p *PostgresRepositoy) UpdateCompany(ctx context.Context, id string, company *models.Company) error {
-	// Verificar que la base de datos está disponible
-	_, err := p.db.ExecContext(ctx, "SELECT 1")
-	if err != nil {
-		return errors.New("database is not available")
-	}
-
+	defer metrics.ObserveQuery("UpdateCompany", time.Now())
 	// Verificar que el repositorio no sea nil
 	if p == nil {
 		return errors.New("repository cannot be nil")
@@ -527,41 +565,37 @@ func (p *PostgresRepositoy) UpdateCompany(ctx context.Context, id string, compan
 		return errors.New("context cannot be nil")
 	}
 
+	// Verificar que la base de datos está disponible
+	_, err := p.queries.exec(ctx, p.db, "Ping")
+	if err != nil {
+		return errors.New("database is not available")
+	}
+
 	// Verificar que la empresa no sea nil
 	if company == nil {
 		return errors.New("company cannot be nil")
 	}
 
-	// Verificar que los campos necesarios de la empresa no estén vacíos
-	if company.Name == "" {
-		return errors.New("company name cannot be empty")
-	}
-	if company.ImagePath == "" {
-		return errors.New("company image_path cannot be empty")
-	}
-	if company.Location == "" {
-		return errors.New("company location cannot be empty")
+	// Actualizar una empresa en la base de datos; los triggers de la tabla actualizan updated_at y version
+	err = p.queries.queryRow(ctx, p.db, "UpdateCompany",
+		company.Name, company.Location, company.Industry, company.UserID, id, company.Version).Scan(&company.CreatedAt, &company.UpdatedAt, &company.Version)
+	// La empresa no existe, fue eliminada o cambió de versión
+	if errors.Is(err, sql.ErrNoRows) {
+		return p.rowError(ctx, "companies", id)
 	}
-	if company.Industry == "" {
-		return errors.New("company industry cannot be empty")
-	}
-	if company.UserID == "" {
-		return errors.New("company user_id cannot be empty")
-	}
-
-	// Actualizar una empresa en la base de datos
-	_, err = p.db.ExecContext(ctx, "UPDATE companies SET name = $1, image_path = $2, location = $3, industry = $4, user_id = $5 WHERE id = $6", company.Name, company.ImagePath, company.Location, company.Industry, company.UserID, id)
 	return err
 }
 
-// DeleteCompany es una función que elimina una empresa de la base de datos por su ID.
-func (p *PostgresRepositoy) DeleteCompany(ctx context.Context, id string) error {
-	// Verificar que la base de datos está disponible
-	_, err := p.db.ExecContext(ctx, "SELECT 1")
-	if err != nil {
-		return errors.New("database is not available")
-	}
+// PatchCompany es una función que actualiza solo los campos dados de una empresa.
+func (p *PostgresRepositoy) PatchCompany(ctx context.Context, id string, company *models.Company, fields []string) error {
+	defer metrics.ObserveQuery("PatchCompany", time.Now())
+	// Actualizar las columnas modificadas
+	return p.patch(ctx, "companies", id, company.Version, companyColumns(company), fields, &company.CreatedAt, &company.UpdatedAt, &company.Version)
+}
 
+// DeleteCompany es una función que elimina una empresa de la base de datos por su ID.
+func (p *PostgresRepositoy) DeleteCompany(ctx context.Context, id string, version int) error {
+	defer metrics.ObserveQuery("DeleteCompany", time.Now())
 	// Verificar que el repositorio no sea nil
 	if p == nil {
 		return errors.New("repository cannot be nil")
@@ -577,13 +611,38 @@ func (p *PostgresRepositoy) DeleteCompany(ctx context.Context, id string) error
 		return errors.New("context cannot be nil")
 	}
 
-	// Eliminar una empresa de la base de datos
-	_, err = p.db.ExecContext(ctx, "DELETE FROM companies WHERE id = $1", id)
-	return err
+	// Verificar que la base de datos está disponible
+	_, err := p.queries.exec(ctx, p.db, "Ping")
+	if err != nil {
+		return errors.New("database is not available")
+	}
+
+	// Eliminar lógicamente una empresa de la base de datos
+	return p.softDelete(ctx, "companies", id, version)
+}
+
+// RestoreCompany es una función que restaura una empresa eliminada.
+func (p *PostgresRepositoy) RestoreCompany(ctx context.Context, id string) error {
+	defer metrics.ObserveQuery("RestoreCompany", time.Now())
+	// Restaurar la empresa solo si su dueño no está eliminado
+	return p.restore(ctx, "companies", id)
 }
 
 // GetCompanies es una función que obtiene empresas de la base de datos con paginación.
 func (p *PostgresRepositoy) GetCompanies(ctx context.Context, page int, pageSize int) ([]*models.Company, int, error) {
+	defer metrics.ObserveQuery("GetCompanies", time.Now())
+	return p.listCompanies(ctx, page, pageSize, "")
+}
+
+// GetCompaniesByUser es una función que obtiene una lista de empresas de un usuario.
+func (p *PostgresRepositoy) GetCompaniesByUser(ctx context.Context, userID string, page int, pageSize int) ([]*models.Company, int, error) {
+	defer metrics.ObserveQuery("GetCompaniesByUser", time.Now())
+	return p.listCompanies(ctx, page, pageSize, userID)
+}
+
+// listCompanies es una función que obtiene una página de empresas no eliminadas del usuario dado, o de todos
+// los usuarios si userID está vacío.
+func (p *PostgresRepositoy) listCompanies(ctx context.Context, page int, pageSize int, userID string) ([]*models.Company, int, error) {
 	var companies []*models.Company
 	// Comprobar si la página y el tamaño de la página son válidos
 	if page < 1 || pageSize < 1 {
@@ -594,7 +653,7 @@ func (p *PostgresRepositoy) GetCompanies(ctx context.Context, page int, pageSize
 	offset := (page - 1) * pageSize
 
 	// Ejecutar la consulta para obtener empresas
-	rows, err := p.db.QueryContext(ctx, "SELECT id, name, image_path, location, industry, user_id FROM companies ORDER BY id LIMIT $1 OFFSET $2", pageSize, offset)
+	rows, err := p.queries.query(ctx, p.db, "ListCompanies", userID, pageSize, offset)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -603,7 +662,7 @@ func (p *PostgresRepositoy) GetCompanies(ctx context.Context, page int, pageSize
 	// Iterar sobre los resultados
 	for rows.Next() {
 		var company = models.Company{}
-		if err = rows.Scan(&company.Id, &company.Name, &company.ImagePath, &company.Location, &company.Industry, &company.UserID); err != nil {
+		if err = scanCompany(rows, &company); err != nil {
 			return nil, 0, err
 		}
 		companies = append(companies, &company)
@@ -615,7 +674,7 @@ func (p *PostgresRepositoy) GetCompanies(ctx context.Context, page int, pageSize
 	}
 
 	// Ejecutar la consulta para contar el número total de empresas
-	row := p.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM companies")
+	row := p.queries.queryRow(ctx, p.db, "CountCompanies", userID)
 	var count int
 	err = row.Scan(&count)
 	if err != nil {
@@ -627,43 +686,876 @@ func (p *PostgresRepositoy) GetCompanies(ctx context.Context, page int, pageSize
 }
 
 // GetCompanyById es una función que obtiene una empresa de la base de datos por su ID.
+// Devuelve ErrNotFound si no existe o fue eliminada.
 func (p *PostgresRepositoy) GetCompanyById(ctx context.Context, id string) (*models.Company, error) {
+	defer metrics.ObserveQuery("GetCompanyById", time.Now())
 	// Crear una nueva estructura de empresa
 	var company = models.Company{}
 	// Obtener una empresa de la base de datos por su ID
-	rows, err := p.db.QueryContext(ctx, "SELECT id, name, image_path, location, industry, user_id FROM companies WHERE id = $1", id)
+	err := scanCompany(p.queries.queryRow(ctx, p.db, "GetCompanyById", id), &company)
+	// La empresa no existe o fue eliminada
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: no company found with id %s", repository.ErrNotFound, id)
+	}
 	// Manejar el error si existe
 	if err != nil {
 		return nil, err
 	}
-	// Cerrar la conexión a la base de datos
-	defer func() {
-		err = rows.Close()
-		if err != nil {
-			log.Fatal("Error closing database connection: ", err)
+	// Devolver la empresa
+	return &company, nil
+}
+
+// scanCompany es una función que lee una empresa de una fila con las columnas id, name, image_path, location,
+// industry, user_id, created_at, updated_at y version. Las columnas que admiten NULL se leen como cadenas vacías.
+func scanCompany(row scanner, company *models.Company) error {
+	var name, imagePath, location, industry, userId sql.NullString
+	if err := row.Scan(&company.Id, &name, &imagePath, &location, &industry, &userId, &company.CreatedAt, &company.UpdatedAt, &company.Version); err != nil {
+		return err
+	}
+	company.Name, company.ImagePath, company.Location, company.Industry, company.UserID = name.String, imagePath.String, location.String, industry.String, userId.String
+	return nil
+}
+
+// CloseCompany es una función que cierra la conexión a la base de datos.
+func (p *PostgresRepositoy) CloseCompany() error {
+	return p.Close()
+}
+
+
+
+//********************************************************************************************************************
+//************************************************************* MFA **************************************************
+//********************************************************************************************************************
+
+// GetUserMFA es una función que obtiene la configuración TOTP de un usuario, o nil si no tiene.
+func (p *PostgresRepositoy) GetUserMFA(ctx context.Context, userId string) (*models.UserMFA, error) {
+	defer metrics.ObserveQuery("GetUserMFA", time.Now())
+	// Crear una nueva estructura de configuración TOTP
+	var mfa = models.UserMFA{}
+	// Obtener la configuración TOTP del usuario
+	err := p.queries.queryRow(ctx, p.db, "GetUserMFA", userId).Scan(&mfa.UserID, &mfa.Secret, &mfa.Enabled)
+	// El usuario no tiene 2FA configurado
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	// Manejar el error si existe
+	if err != nil {
+		return nil, err
+	}
+	// Devolver la configuración TOTP
+	return &mfa, nil
+}
+
+// SaveUserMFASecret es una función que guarda un secreto TOTP pendiente de verificación para un usuario.
+func (p *PostgresRepositoy) SaveUserMFASecret(ctx context.Context, userId string, secret string) error {
+	defer metrics.ObserveQuery("SaveUserMFASecret", time.Now())
+	// Insertar o reemplazar el secreto, dejándolo desactivado hasta que se verifique
+	_, err := p.queries.exec(ctx, p.db, "SaveUserMFASecret", userId, secret)
+	return err
+}
+
+// EnableUserMFA es una función que activa el TOTP de un usuario y reemplaza sus códigos de recuperación.
+func (p *PostgresRepositoy) EnableUserMFA(ctx context.Context, userId string, codes []*models.RecoveryCode) error {
+	defer metrics.ObserveQuery("EnableUserMFA", time.Now())
+	// Iniciar una transacción para activar el TOTP y guardar los códigos de forma atómica
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	// Deshacer la transacción si no se confirma
+	defer tx.Rollback()
+
+	// Activar el TOTP del usuario
+	result, err := p.queries.exec(ctx, tx, "EnableUserMFA", userId)
+	if err != nil {
+		return err
+	}
+	// Verificar que el usuario tenga un secreto pendiente
+	if affected, err := result.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return fmt.Errorf("no mfa secret found for user %s", userId)
+	}
+
+	// Eliminar los códigos de recuperación anteriores
+	if _, err = p.queries.exec(ctx, tx, "DeleteRecoveryCodes", userId); err != nil {
+		return err
+	}
+	// Insertar los nuevos códigos de recuperación
+	for _, code := range codes {
+		if _, err = p.queries.exec(ctx, tx, "InsertRecoveryCode", code.Id, userId, code.CodeHash); err != nil {
+			return err
 		}
-	}()
-	// Iterar sobre los resultados de la consulta
+	}
+	// Confirmar la transacción
+	return tx.Commit()
+}
+
+// DisableUserMFA es una función que desactiva el TOTP de un usuario y elimina sus códigos de recuperación.
+func (p *PostgresRepositoy) DisableUserMFA(ctx context.Context, userId string) error {
+	defer metrics.ObserveQuery("DisableUserMFA", time.Now())
+	// Iniciar una transacción para eliminar el secreto y los códigos de forma atómica
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	// Deshacer la transacción si no se confirma
+	defer tx.Rollback()
+
+	// Eliminar los códigos de recuperación
+	if _, err = p.queries.exec(ctx, tx, "DeleteRecoveryCodes", userId); err != nil {
+		return err
+	}
+	// Eliminar el secreto TOTP
+	if _, err = p.queries.exec(ctx, tx, "DeleteUserMFA", userId); err != nil {
+		return err
+	}
+	// Confirmar la transacción
+	return tx.Commit()
+}
+
+// GetRecoveryCodes es una función que obtiene los códigos de recuperación sin usar de un usuario.
+func (p *PostgresRepositoy) GetRecoveryCodes(ctx context.Context, userId string) ([]*models.RecoveryCode, error) {
+	defer metrics.ObserveQuery("GetRecoveryCodes", time.Now())
+	var codes []*models.RecoveryCode
+	// Ejecutar la consulta para obtener los códigos sin usar
+	rows, err := p.queries.query(ctx, p.db, "GetRecoveryCodes", userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Iterar sobre los resultados
+	for rows.Next() {
+		var code = models.RecoveryCode{}
+		if err = rows.Scan(&code.Id, &code.UserID, &code.CodeHash); err != nil {
+			return nil, err
+		}
+		codes = append(codes, &code)
+	}
+
+	// Comprobar si hubo errores durante la iteración
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	// Devolver los códigos
+	return codes, nil
+}
+
+// UseRecoveryCode es una función que marca un código de recuperación como usado.
+func (p *PostgresRepositoy) UseRecoveryCode(ctx context.Context, id string) error {
+	defer metrics.ObserveQuery("UseRecoveryCode", time.Now())
+	// Marcar el código como usado solo si no se había usado antes
+	result, err := p.queries.exec(ctx, p.db, "UseRecoveryCode", id)
+	if err != nil {
+		return err
+	}
+	// Verificar que el código no haya sido usado por otra solicitud concurrente
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return repository.ErrCodeUsed
+	}
+	return nil
+}
+
+// UseTOTPStep es una función que guarda la ventana del último código TOTP aceptado de un usuario, o devuelve
+// ErrCodeUsed si ya se había aceptado un código de esa ventana o de una posterior.
+func (p *PostgresRepositoy) UseTOTPStep(ctx context.Context, userId string, step int64) error {
+	defer metrics.ObserveQuery("UseTOTPStep", time.Now())
+	// Guardar la ventana solo si es posterior a la última, de forma atómica frente a solicitudes concurrentes
+	result, err := p.queries.exec(ctx, p.db, "UseTOTPStep", userId, step)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return repository.ErrCodeUsed
+	}
+	return nil
+}
+
+
+//********************************************************************************************************************
+//************************************************************* IDENTITY *********************************************
+//********************************************************************************************************************
+
+// GetIdentity es una función que obtiene una identidad por proveedor y sujeto, o nil si no existe.
+func (p *PostgresRepositoy) GetIdentity(ctx context.Context, provider string, subject string) (*models.Identity, error) {
+	defer metrics.ObserveQuery("GetIdentity", time.Now())
+	// Crear una nueva estructura de identidad
+	var identity = models.Identity{}
+	var email sql.NullString
+	// Obtener la identidad de la base de datos
+	err := p.queries.queryRow(ctx, p.db, "GetIdentity", provider, subject).Scan(&identity.Id, &identity.UserID, &identity.Provider, &identity.Subject, &email)
+	// La identidad no está vinculada a ningún usuario
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	// Manejar el error si existe
+	if err != nil {
+		return nil, err
+	}
+	identity.Email = email.String
+	// Devolver la identidad
+	return &identity, nil
+}
+
+// InsertIdentity es una función que vincula una identidad externa con un usuario existente.
+func (p *PostgresRepositoy) InsertIdentity(ctx context.Context, identity *models.Identity) error {
+	defer metrics.ObserveQuery("InsertIdentity", time.Now())
+	// Insertar la identidad en la base de datos
+	_, err := p.queries.exec(ctx, p.db, "InsertIdentity", identity.Id, identity.UserID, identity.Provider, identity.Subject, identity.Email)
+	return err
+}
+
+// InsertUserWithIdentity es una función que crea un usuario y su identidad externa de forma atómica.
+func (p *PostgresRepositoy) InsertUserWithIdentity(ctx context.Context, user *models.User, identity *models.Identity) error {
+	defer metrics.ObserveQuery("InsertUserWithIdentity", time.Now())
+	// Iniciar una transacción para crear el usuario y la identidad juntos
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	// Deshacer la transacción si no se confirma
+	defer tx.Rollback()
+
+	// Insertar el usuario
+	if err = p.queries.queryRow(ctx, tx, "InsertUser", user.Id, user.Fullname, user.Email, user.Password).Scan(&user.CreatedAt, &user.UpdatedAt, &user.Version); err != nil {
+		return err
+	}
+	// Insertar la identidad
+	if _, err = p.queries.exec(ctx, tx, "InsertIdentity", identity.Id, identity.UserID, identity.Provider, identity.Subject, identity.Email); err != nil {
+		return err
+	}
+	// Confirmar la transacción
+	return tx.Commit()
+}
+
+
+//********************************************************************************************************************
+//************************************************************* API KEY **********************************************
+//********************************************************************************************************************
+
+// InsertAPIKey es una función que inserta una nueva API key en la base de datos.
+func (p *PostgresRepositoy) InsertAPIKey(ctx context.Context, key *models.APIKey) error {
+	defer metrics.ObserveQuery("InsertAPIKey", time.Now())
+	// Insertar la key y obtener su fecha de creación
+	return p.queries.queryRow(ctx, p.db, "InsertAPIKey",
+		key.Id, key.UserID, key.Name, key.Prefix, key.KeyHash, pq.Array(key.Scopes)).Scan(&key.CreatedAt)
+}
+
+// GetAPIKeysByUser es una función que obtiene las API keys activas de un usuario.
+func (p *PostgresRepositoy) GetAPIKeysByUser(ctx context.Context, userId string) ([]*models.APIKey, error) {
+	defer metrics.ObserveQuery("GetAPIKeysByUser", time.Now())
+	var keys []*models.APIKey
+	// Ejecutar la consulta para obtener las keys no revocadas
+	rows, err := p.queries.query(ctx, p.db, "GetAPIKeysByUser", userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Iterar sobre los resultados
 	for rows.Next() {
-		if err = rows.Scan(&company.Id, &company.Name, &company.ImagePath, &company.Location, &company.Industry, &company.UserID); err == nil {
-			return &company, nil
+		var key = models.APIKey{}
+		if err = rows.Scan(&key.Id, &key.UserID, &key.Name, &key.Prefix, pq.Array(&key.Scopes), &key.LastUsedAt, &key.CreatedAt); err != nil {
+			return nil, err
 		}
+		keys = append(keys, &key)
+	}
+
+	// Comprobar si hubo errores durante la iteración
+	if err = rows.Err(); err != nil {
+		return nil, err
 	}
+	// Devolver las keys
+	return keys, nil
+}
 
-	// Iterar sobre los resultados de la consulta
-	if !rows.Next() {
-		return nil, fmt.Errorf("no user found with id %s", id)
+// GetAPIKeyByHash es una función que obtiene una API key activa por su hash, o nil si no existe.
+func (p *PostgresRepositoy) GetAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	defer metrics.ObserveQuery("GetAPIKeyByHash", time.Now())
+	// Crear una nueva estructura de API key
+	var key = models.APIKey{}
+	// Obtener la key no revocada con el hash dado
+	err := p.queries.queryRow(ctx, p.db, "GetAPIKeyByHash", keyHash).
+		Scan(&key.Id, &key.UserID, &key.Name, &key.Prefix, &key.KeyHash, pq.Array(&key.Scopes), &key.LastUsedAt, &key.CreatedAt)
+	// La key no existe o fue revocada
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
 	}
 	// Manejar el error si existe
+	if err != nil {
+		return nil, err
+	}
+	// Devolver la key
+	return &key, nil
+}
+
+// RevokeAPIKey es una función que revoca una API key de un usuario.
+func (p *PostgresRepositoy) RevokeAPIKey(ctx context.Context, userId string, id string) error {
+	defer metrics.ObserveQuery("RevokeAPIKey", time.Now())
+	// Revocar la key solo si pertenece al usuario y sigue activa
+	result, err := p.queries.exec(ctx, p.db, "RevokeAPIKey", id, userId)
+	if err != nil {
+		return err
+	}
+	// Verificar que se haya revocado alguna key
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return repository.ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// TouchAPIKey es una función que actualiza la fecha de último uso de una API key.
+func (p *PostgresRepositoy) TouchAPIKey(ctx context.Context, id string) error {
+	defer metrics.ObserveQuery("TouchAPIKey", time.Now())
+	// Actualizar la fecha de último uso
+	_, err := p.queries.exec(ctx, p.db, "TouchAPIKey", id)
+	return err
+}
+
+
+
+//********************************************************************************************************************
+//************************************************************* SOFT DELETE ******************************************
+//********************************************************************************************************************
+
+// tableQueries son los nombres de las consultas de borrado lógico, restauración y existencia de cada tabla
+var tableQueries = map[string]struct {
+	softDelete string
+	restore    string
+	isActive   string
+}{
+	"users":      {isActive: "UserIsActive"},
+	"challenges": {softDelete: "SoftDeleteChallenge", restore: "RestoreChallenge", isActive: "ChallengeIsActive"},
+	"companies":  {softDelete: "SoftDeleteCompany", restore: "RestoreCompany", isActive: "CompanyIsActive"},
+	"programs":   {isActive: "ProgramExists"},
+}
+
+// softDelete es una función que marca como eliminada una fila activa de la tabla dada (challenges o companies).
+// Si version no es 0, solo la elimina si coincide con la versión actual.
+func (p *PostgresRepositoy) softDelete(ctx context.Context, table string, id string, version int) error {
+	// Marcar la fila como eliminada
+	result, err := p.queries.exec(ctx, p.db, tableQueries[table].softDelete, id, version)
+	if err != nil {
+		return err
+	}
+	// Verificar que la fila existiera y no estuviera eliminada
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return p.rowError(ctx, table, id)
+	}
+	return nil
+}
+
+// rowError es una función que explica por qué una modificación no afectó a la fila activa de la tabla dada:
+// la fila no existe o fue eliminada (ErrNotFound), o su versión cambió (ErrVersionConflict).
+func (p *PostgresRepositoy) rowError(ctx context.Context, table string, id string) error {
+	// Comprobar si la fila sigue activa
+	var exists bool
+	if err := p.queries.queryRow(ctx, p.db, tableQueries[table].isActive, id).Scan(&exists); err != nil {
+		return err
+	}
+	return unaffectedRowError(table, id, exists)
+}
+
+// unaffectedRowError es una función que devuelve el error de una modificación que no afectó a la fila:
+// ErrVersionConflict si la fila sigue activa y ErrNotFound si no
+func unaffectedRowError(table string, id string, active bool) error {
+	if active {
+		return fmt.Errorf("%w: %s with id %s was modified", repository.ErrVersionConflict, table, id)
+	}
+	return fmt.Errorf("%w: no %s found with id %s", repository.ErrNotFound, table, id)
+}
+
+// restore es una función que restaura una fila eliminada de la tabla dada (challenges o companies),
+// siempre que su dueño no esté eliminado.
+func (p *PostgresRepositoy) restore(ctx context.Context, table string, id string) error {
+	// Restaurar la fila
+	result, err := p.queries.exec(ctx, p.db, tableQueries[table].restore, id)
+	if err != nil {
+		return err
+	}
+	// Verificar que se haya restaurado alguna fila
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("%w: no deleted %s found with id %s, or its owner is deleted", repository.ErrNotFound, table, id)
+	}
+	return nil
+}
+
+// PurgeDeleted es una función que elimina definitivamente las filas marcadas como eliminadas antes de la fecha dada,
+// junto con las filas que dependen de ellas, y devuelve el número de filas principales eliminadas. Los adjuntos,
+// logos y avatares de las filas purgadas se eliminan del almacén después de confirmar la transacción.
+func (p *PostgresRepositoy) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	defer metrics.ObserveQuery("PurgeDeleted", time.Now())
+	// Iniciar una transacción para purgar de forma atómica
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	// Deshacer la transacción si no se confirma
+	defer tx.Rollback()
+
+	// Leer los archivos de las filas que se van a purgar, antes de que se eliminen
+	keys, err := p.purgedBlobKeys(ctx, tx, before)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	statements := []struct {
+		name  string
+		count bool
+	}{
+		// Participaciones de los retos y empresas que se van a purgar
+		{"PurgeProgramParticipants", false},
+		// Retos y empresas vencidos o de usuarios purgados
+		{"PurgeChallenges", true},
+		{"PurgeCompanies", true},
+		// Datos que dependen de los usuarios purgados
+		{"PurgePrograms", false},
+		{"PurgeRecoveryCodes", false},
+		{"PurgeUserMFA", false},
+		{"PurgeIdentities", false},
+		{"PurgeAPIKeys", false},
+		// Finalmente los usuarios
+		{"PurgeUsers", true},
+	}
+	for _, statement := range statements {
+		result, err := p.queries.exec(ctx, tx, statement.name, before)
+		if err != nil {
+			return 0, err
+		}
+		if statement.count {
+			affected, err := result.RowsAffected()
+			if err != nil {
+				return 0, err
+			}
+			total += affected
+		}
+	}
+	// Confirmar la transacción
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	// Eliminar los archivos solo cuando las filas ya no existen; si falla, el archivo queda huérfano pero no roto
+	for _, key := range keys {
+		if err := storage.Delete(ctx, key); err != nil {
+			logging.FromContext(ctx).Error("error deleting purged file", "key", key, "error", err)
+		}
+	}
+	return total, nil
+}
+
+// purgedBlobKeys es una función que devuelve las claves en el almacén de los adjuntos de los retos, los logos de
+// las empresas y los avatares de los usuarios que purga PurgeDeleted con la fecha dada.
+func (p *PostgresRepositoy) purgedBlobKeys(ctx context.Context, tx transaction, before time.Time) ([]string, error) {
+	var keys []string
+	// collect es una función que agrega las claves de cada fila de la consulta dada
+	collect := func(name string, rowKeys func(rows *sql.Rows) ([]string, error)) error {
+		rows, err := p.queries.query(ctx, tx, name, before)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			found, err := rowKeys(rows)
+			if err != nil {
+				return err
+			}
+			keys = append(keys, found...)
+		}
+		return rows.Err()
+	}
+	// Adjuntos de los retos
+	err := collect("PurgedAttachmentKeys", func(rows *sql.Rows) ([]string, error) {
+		var key string
+		err := rows.Scan(&key)
+		return []string{key}, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Logos y avatares, con sus miniaturas
+	images := []struct {
+		name   string
+		prefix func(id string) string
+	}{
+		{"PurgedCompanyLogos", storage.CompanyLogoPrefix},
+		{"PurgedUserAvatars", storage.UserAvatarPrefix},
+	}
+	for _, image := range images {
+		err := collect(image.name, func(rows *sql.Rows) ([]string, error) {
+			var id, url string
+			if err := rows.Scan(&id, &url); err != nil {
+				return nil, err
+			}
+			return storage.ImageKeys(url, image.prefix(id)), nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
+
+
+
+//********************************************************************************************************************
+//************************************************************* AUDIT ************************************************
+//********************************************************************************************************************
+
+// InsertAuditEntry es una función que agrega una entrada al registro de auditoría.
+func (p *PostgresRepositoy) InsertAuditEntry(ctx context.Context, entry *models.AuditEntry) error {
+	defer metrics.ObserveQuery("InsertAuditEntry", time.Now())
+	// Insertar la entrada y obtener su fecha de creación
+	return p.queries.queryRow(ctx, p.db, "InsertAuditEntry",
+		entry.Id, entry.ActorID, entry.APIKeyID, entry.Action, entry.EntityType, entry.EntityID,
+		nullJSON(entry.Before), nullJSON(entry.After), entry.RequestID, entry.IP).Scan(&entry.CreatedAt)
+}
+
+// GetAuditEntries es una función que obtiene las entradas del registro que cumplen el filtro, con paginación.
+func (p *PostgresRepositoy) GetAuditEntries(ctx context.Context, filter models.AuditFilter, page int, pageSize int) ([]*models.AuditEntry, int, error) {
+	defer metrics.ObserveQuery("GetAuditEntries", time.Now())
+	var entries []*models.AuditEntry
+	// Comprobar si la página y el tamaño de la página son válidos
+	if page < 1 || pageSize < 1 {
+		return nil, 0, errors.New("invalid page number or page size")
+	}
+
+	// Construir las condiciones del filtro
+	where := "WHERE 1 = 1"
+	var args []interface{}
+	addCondition := func(condition string, value interface{}) {
+		args = append(args, value)
+		where += fmt.Sprintf(" AND %s $%d", condition, len(args))
+	}
+	if filter.EntityType != "" {
+		addCondition("entity_type =", filter.EntityType)
+	}
+	if filter.EntityID != "" {
+		addCondition("entity_id =", filter.EntityID)
+	}
+	if filter.ActorID != "" {
+		addCondition("actor_id =", filter.ActorID)
+	}
+	if filter.From != nil {
+		addCondition("created_at >=", *filter.From)
+	}
+	if filter.To != nil {
+		addCondition("created_at <", *filter.To)
+	}
+
+	// Ejecutar la consulta para obtener las entradas, de la más reciente a la más antigua
+	query := fmt.Sprintf(`SELECT id, COALESCE(actor_id, ''), COALESCE(api_key_id, ''), action, entity_type, entity_id, before, after,
+		COALESCE(request_id, ''), COALESCE(ip, ''), created_at FROM audit_log %s ORDER BY created_at DESC, id DESC LIMIT $%d OFFSET $%d`,
+		where, len(args)+1, len(args)+2)
+	rows, err := p.db.QueryContext(ctx, query, append(args, pageSize, (page-1)*pageSize)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	// Iterar sobre los resultados
+	for rows.Next() {
+		var entry = models.AuditEntry{}
+		var before, after []byte
+		if err = rows.Scan(&entry.Id, &entry.ActorID, &entry.APIKeyID, &entry.Action, &entry.EntityType, &entry.EntityID,
+			&before, &after, &entry.RequestID, &entry.IP, &entry.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+		entry.Before, entry.After = before, after
+		entries = append(entries, &entry)
+	}
+
+	// Comprobar si hubo errores durante la iteración
 	if err = rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	// Ejecutar la consulta para contar el número total de entradas
+	var count int
+	if err = p.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM audit_log "+where, args...).Scan(&count); err != nil {
+		return nil, 0, err
+	}
+
+	// Devolver las entradas y el conteo
+	return entries, count, nil
+}
+
+// nullJSON es una función que convierte un JSON vacío en NULL para la base de datos
+func nullJSON(data []byte) interface{} {
+	if len(data) == 0 {
+		return nil
+	}
+	return string(data)
+}
+
+
+
+
+//********************************************************************************************************************
+//************************************************************* PROGRAM **********************************************
+//********************************************************************************************************************
+
+// GetProgramById es una función que obtiene un programa de la base de datos por su ID.
+func (p *PostgresRepositoy) GetProgramById(ctx context.Context, id string) (*models.Program, error) {
+	defer metrics.ObserveQuery("GetProgramById", time.Now())
+	// Crear una nueva estructura de programa
+	var program = models.Program{}
+	var title, description, userId sql.NullString
+	var startDate, endDate sql.NullTime
+	// Obtener el programa de la base de datos por su ID
+	err := p.queries.queryRow(ctx, p.db, "GetProgramById", id).
+		Scan(&program.Id, &title, &description, &startDate, &endDate, &userId, &program.CreatedAt, &program.UpdatedAt, &program.Version)
+	// El programa no existe
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: no program found with id %s", repository.ErrNotFound, id)
+	}
+	// Manejar el error si existe
+	if err != nil {
 		return nil, err
 	}
-	// Devolver la empresa
-	return &company, nil
+	program.Title, program.Description, program.UserID = title.String, description.String, userId.String
+	program.StartDate, program.EndDate = startDate.Time, endDate.Time
+	// Devolver el programa
+	return &program, nil
 }
 
-// CloseCompany es una función que cierra la conexión a la base de datos.
-func (p *PostgresRepositoy) CloseCompany() error {
-	return p.db.Close()
+// PatchProgram es una función que actualiza solo los campos dados de un programa.
+func (p *PostgresRepositoy) PatchProgram(ctx context.Context, id string, program *models.Program, fields []string) error {
+	defer metrics.ObserveQuery("PatchProgram", time.Now())
+	// Valores de las columnas que se pueden modificar parcialmente; las fechas vacías se guardan como NULL
+	columns := map[string]interface{}{
+		"title":       program.Title,
+		"description": program.Description,
+		"start_date":  sql.NullTime{Time: program.StartDate, Valid: !program.StartDate.IsZero()},
+		"end_date":    sql.NullTime{Time: program.EndDate, Valid: !program.EndDate.IsZero()},
+		"user_id":     program.UserID,
+	}
+	// Actualizar las columnas modificadas
+	return p.patch(ctx, "programs", id, program.Version, columns, fields, &program.CreatedAt, &program.UpdatedAt, &program.Version)
+}
+
+// InsertProgramParticipant es una función que inscribe un usuario, un reto o una empresa en un programa.
+func (p *PostgresRepositoy) InsertProgramParticipant(ctx context.Context, participant *models.ProgramParticipant) error {
+	defer metrics.ObserveQuery("InsertProgramParticipant", time.Now())
+	// Insertar la inscripción; la clave foránea rechaza un programa que no existe
+	_, err := p.queries.exec(ctx, p.db, "InsertProgramParticipant", participant.Id, participant.ProgramID, participant.EntityType, participant.EntityID)
+	return err
+}
+
+
+
+//********************************************************************************************************************
+//************************************************************* ATTACHMENT *******************************************
+//********************************************************************************************************************
+
+// InsertAttachment es una función que inserta un archivo adjunto de un reto activo.
+func (p *PostgresRepositoy) InsertAttachment(ctx context.Context, attachment *models.Attachment) error {
+	defer metrics.ObserveQuery("InsertAttachment", time.Now())
+	// Insertar solo si el reto existe y no fue eliminado
+	err := p.queries.queryRow(ctx, p.db, "InsertAttachment",
+		attachment.Id, attachment.ChallengeID, attachment.UserID, attachment.Filename, attachment.ContentType, attachment.Size, attachment.BlobKey).
+		Scan(&attachment.CreatedAt)
+	// El reto no existe o fue eliminado
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: no challenge found with id %s", repository.ErrNotFound, attachment.ChallengeID)
+	}
+	return err
+}
+
+// GetAttachments es una función que obtiene los archivos adjuntos de un reto, del más antiguo al más nuevo.
+func (p *PostgresRepositoy) GetAttachments(ctx context.Context, challengeID string) ([]*models.Attachment, error) {
+	defer metrics.ObserveQuery("GetAttachments", time.Now())
+	rows, err := p.queries.query(ctx, p.db, "GetAttachments", challengeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	attachments := []*models.Attachment{}
+	for rows.Next() {
+		var attachment models.Attachment
+		if err = rows.Scan(&attachment.Id, &attachment.ChallengeID, &attachment.UserID, &attachment.Filename, &attachment.ContentType, &attachment.Size, &attachment.BlobKey, &attachment.CreatedAt); err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, &attachment)
+	}
+	return attachments, rows.Err()
+}
+
+// GetAttachmentById es una función que obtiene un archivo adjunto de un reto por su ID.
+func (p *PostgresRepositoy) GetAttachmentById(ctx context.Context, challengeID string, id string) (*models.Attachment, error) {
+	defer metrics.ObserveQuery("GetAttachmentById", time.Now())
+	var attachment models.Attachment
+	err := p.queries.queryRow(ctx, p.db, "GetAttachmentById", challengeID, id).
+		Scan(&attachment.Id, &attachment.ChallengeID, &attachment.UserID, &attachment.Filename, &attachment.ContentType, &attachment.Size, &attachment.BlobKey, &attachment.CreatedAt)
+	// El archivo adjunto no existe
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: no attachment found with id %s", repository.ErrNotFound, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+// DeleteAttachment es una función que elimina un archivo adjunto de un reto.
+func (p *PostgresRepositoy) DeleteAttachment(ctx context.Context, challengeID string, id string) error {
+	defer metrics.ObserveQuery("DeleteAttachment", time.Now())
+	result, err := p.queries.exec(ctx, p.db, "DeleteAttachment", challengeID, id)
+	if err != nil {
+		return err
+	}
+	// El archivo adjunto no existe
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return fmt.Errorf("%w: no attachment found with id %s", repository.ErrNotFound, id)
+	}
+	return nil
+}
+
+
+
+//********************************************************************************************************************
+//************************************************************* RATE LIMIT *******************************************
+//********************************************************************************************************************
+
+// TakeToken es una función que toma una ficha de la cubeta de límite de solicitudes de la clave.
+// La fila de la cubeta se bloquea durante la transacción para que las instancias que comparten la base de datos
+// no tomen la misma ficha.
+func (p *PostgresRepositoy) TakeToken(ctx context.Context, key string, policy ratelimit.Policy, now time.Time) (ratelimit.Result, error) {
+	defer metrics.ObserveQuery("TakeToken", time.Now())
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return ratelimit.Result{}, err
+	}
+	// Deshacer la transacción si no se confirma
+	defer tx.Rollback()
+
+	// Crear la cubeta llena si no existe y bloquearla
+	if _, err = p.queries.exec(ctx, tx, "InsertRateLimit", key, policy.Limit, now); err != nil {
+		return ratelimit.Result{}, err
+	}
+	var bucket ratelimit.Bucket
+	if err = p.queries.queryRow(ctx, tx, "LockRateLimit", key).Scan(&bucket.Tokens, &bucket.UpdatedAt); err != nil {
+		return ratelimit.Result{}, err
+	}
+	// Tomar la ficha y guardar el nuevo estado, con el momento en que la cubeta vuelve a estar llena
+	result := bucket.Take(policy, now)
+	if _, err = p.queries.exec(ctx, tx, "UpdateRateLimit", key, bucket.Tokens, bucket.UpdatedAt, bucket.UpdatedAt.Add(result.Reset)); err != nil {
+		return ratelimit.Result{}, err
+	}
+	return result, tx.Commit()
 }
 
+// DeleteFullBuckets es una función que elimina las cubetas que ya estaban llenas en now: son iguales a una cubeta
+// nueva y sin limpiarlas la tabla crece con cada cliente nuevo.
+func (p *PostgresRepositoy) DeleteFullBuckets(ctx context.Context, now time.Time) (int64, error) {
+	defer metrics.ObserveQuery("DeleteFullBuckets", time.Now())
+	result, err := p.queries.exec(ctx, p.db, "DeleteFullRateLimits", now)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+
+
+//********************************************************************************************************************
+//************************************************************* PATCH ************************************************
+//********************************************************************************************************************
+
+// softDeleteTables son las tablas con borrado lógico, cuyas filas eliminadas no se pueden modificar
+var softDeleteTables = map[string]bool{"users": true, "challenges": true, "companies": true}
+
+// activeCondition es una función que devuelve la condición SQL que excluye las filas eliminadas de la tabla dada
+func activeCondition(table string) string {
+	if softDeleteTables[table] {
+		return " AND deleted_at IS NULL"
+	}
+	return ""
+}
+
+// patch es una función que actualiza solo las columnas dadas de una fila activa y lee en dest
+// su created_at, updated_at y version nuevos. columns contiene los valores de las columnas que se
+// pueden modificar; si version no es 0, solo se actualiza si coincide con la versión actual.
+func (p *PostgresRepositoy) patch(ctx context.Context, table string, id string, version int, columns map[string]interface{}, fields []string, dest ...interface{}) error {
+	query, args, err := patchQuery(table, id, version, columns, fields)
+	if err != nil {
+		return err
+	}
+	// Actualizar la fila; los triggers de la tabla actualizan updated_at y version
+	err = p.db.QueryRowContext(ctx, query, args...).Scan(dest...)
+	// La fila no existe, fue eliminada o cambió de versión
+	if errors.Is(err, sql.ErrNoRows) {
+		return p.rowError(ctx, table, id)
+	}
+	return err
+}
+
+// patchQuery es una función que construye la sentencia que actualiza solo las columnas dadas de una fila activa
+// y devuelve su created_at, updated_at y version nuevos, junto con sus argumentos
+func patchQuery(table string, id string, version int, columns map[string]interface{}, fields []string) (string, []interface{}, error) {
+	// Construir la lista de asignaciones solo con las columnas modificadas
+	var assignments []string
+	var args []interface{}
+	for _, field := range fields {
+		value, ok := columns[field]
+		if !ok {
+			return "", nil, fmt.Errorf("column %s of %s cannot be patched", field, table)
+		}
+		args = append(args, value)
+		assignments = append(assignments, fmt.Sprintf("%s = $%d", field, len(args)))
+	}
+	if len(assignments) == 0 {
+		return "", nil, errors.New("patch fields cannot be empty")
+	}
+	args = append(args, id, version)
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE id = $%d AND ($%d = 0 OR version = $%d)%s RETURNING created_at, updated_at, version",
+		table, strings.Join(assignments, ", "), len(args)-1, len(args), len(args), activeCondition(table))
+	return query, args, nil
+}
+
+// userColumns es una función que devuelve los valores de las columnas de un usuario que se pueden modificar parcialmente
+func userColumns(user *models.User) map[string]interface{} {
+	return map[string]interface{}{"fullname": user.Fullname, "email": user.Email, "avatar_path": user.AvatarPath}
+}
+
+// challengeColumns es una función que devuelve los valores de las columnas de un reto que se pueden modificar parcialmente
+func challengeColumns(challenge *models.Challenge) map[string]interface{} {
+	return map[string]interface{}{
+		"title":       challenge.Title,
+		"description": challenge.Description,
+		"difficulty":  challenge.Difficulty,
+		"user_id":     challenge.UserID,
+	}
+}
+
+// companyColumns es una función que devuelve los valores de las columnas de una empresa que se pueden modificar parcialmente
+func companyColumns(company *models.Company) map[string]interface{} {
+	return map[string]interface{}{
+		"name":       company.Name,
+		"image_path": company.ImagePath,
+		"location":   company.Location,
+		"industry":   company.Industry,
+		"user_id":    company.UserID,
+	}
+}
@@ -0,0 +1,62 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"talentpitchGo/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeRow es una fila con valores fijos; nil representa NULL
+type fakeRow []interface{}
+
+func (f fakeRow) Scan(dest ...interface{}) error {
+	if len(dest) != len(f) {
+		return fmt.Errorf("expected %d destination arguments, got %d", len(f), len(dest))
+	}
+	for i, value := range f {
+		switch d := dest[i].(type) {
+		case sql.Scanner:
+			if err := d.Scan(value); err != nil {
+				return err
+			}
+		case *string:
+			s, ok := value.(string)
+			if !ok {
+				return fmt.Errorf("converting NULL to string is unsupported")
+			}
+			*d = s
+		case *int:
+			*d = value.(int)
+		case *time.Time:
+			*d = value.(time.Time)
+		default:
+			return fmt.Errorf("unsupported destination %T", d)
+		}
+	}
+	return nil
+}
+
+func TestScanNullableColumns(t *testing.T) {
+	now := time.Now()
+
+	// Las columnas NULL de las empresas se leen como cadenas vacías
+	var company models.Company
+	err := scanCompany(fakeRow{"c1", "Acme", nil, nil, "Software", nil, now, now, 2}, &company)
+	assert.NoError(t, err)
+	assert.Equal(t, models.Company{Id: "c1", Name: "Acme", Industry: "Software", CreatedAt: now, UpdatedAt: now, Version: 2}, company)
+
+	// Lo mismo con los retos
+	var challenge models.Challenge
+	err = scanChallenge(fakeRow{"ch1", "Title", nil, 3, nil, now, now, 1}, &challenge)
+	assert.NoError(t, err)
+	assert.Equal(t, "ch1", challenge.Id)
+	assert.Equal(t, "Title", challenge.Title)
+	assert.Empty(t, challenge.Description)
+	assert.Empty(t, challenge.UserID)
+	assert.Equal(t, 3, challenge.Difficulty)
+}
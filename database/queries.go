@@ -0,0 +1,177 @@
+package database
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+)
+
+// QUERY_NAME_PREFIX es el comentario que empieza cada consulta de los archivos .sql
+const QUERY_NAME_PREFIX = "-- name:"
+
+// queryFiles contiene los archivos .sql con las consultas del repositorio
+//
+//go:embed queries/*.sql
+var queryFiles embed.FS
+
+// queries contiene el texto de las consultas de los archivos .sql por su nombre
+var queries = mustParseQueries(queryFiles)
+
+// mustParseQueries es una función que lee las consultas de los archivos .sql y falla si no son válidas.
+// Los archivos van dentro del binario, así que un error es un error de programación.
+func mustParseQueries(files fs.FS) map[string]string {
+	parsed, err := parseQueries(files)
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+// parseQueries es una función que lee las consultas de los archivos .sql. Cada consulta empieza con un comentario
+// "-- name: Nombre" y termina donde empieza la siguiente; los demás comentarios y el punto y coma final se quitan.
+func parseQueries(files fs.FS) (map[string]string, error) {
+	paths, err := fs.Glob(files, "queries/*.sql")
+	if err != nil {
+		return nil, err
+	}
+	parsed := map[string]string{}
+	for _, path := range paths {
+		content, err := fs.ReadFile(files, path)
+		if err != nil {
+			return nil, err
+		}
+		var name string
+		var lines []string
+		// add es una función que guarda la consulta leída hasta el momento
+		add := func() error {
+			query := strings.TrimSuffix(strings.TrimSpace(strings.Join(lines, "\n")), ";")
+			if name == "" {
+				if query != "" {
+					return fmt.Errorf("%s: query without name", path)
+				}
+				return nil
+			}
+			if query == "" {
+				return fmt.Errorf("%s: query %s is empty", path, name)
+			}
+			if _, ok := parsed[name]; ok {
+				return fmt.Errorf("%s: duplicate query %s", path, name)
+			}
+			parsed[name] = query
+			return nil
+		}
+		scanner := bufio.NewScanner(strings.NewReader(string(content)))
+		for scanner.Scan() {
+			line := scanner.Text()
+			trimmed := strings.TrimSpace(line)
+			if strings.HasPrefix(trimmed, QUERY_NAME_PREFIX) {
+				if err := add(); err != nil {
+					return nil, err
+				}
+				name, lines = strings.TrimSpace(strings.TrimPrefix(trimmed, QUERY_NAME_PREFIX)), nil
+				continue
+			}
+			if strings.HasPrefix(trimmed, "--") {
+				continue
+			}
+			lines = append(lines, line)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		if err := add(); err != nil {
+			return nil, err
+		}
+	}
+	return parsed, nil
+}
+
+// queryNames es una función que devuelve los nombres de las consultas en orden alfabético
+func queryNames() []string {
+	names := make([]string, 0, len(queries))
+	for name := range queries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// preparer es la interfaz de una conexión o transacción que puede ejecutar una sentencia preparada
+type preparer interface {
+	// stmt devuelve la sentencia preparada para ejecutarla en esta conexión o transacción
+	stmt(ctx context.Context, stmt *sql.Stmt) *sql.Stmt
+}
+
+// statements es el registro de las consultas del repositorio, preparadas una sola vez al crearlo
+type statements struct {
+	prepared map[string]*sql.Stmt
+}
+
+// prepareStatements es una función que prepara todas las consultas de los archivos .sql.
+// Falla si alguna no compila contra el esquema de la base de datos.
+func prepareStatements(ctx context.Context, db *sql.DB) (*statements, error) {
+	s := &statements{prepared: map[string]*sql.Stmt{}}
+	for _, name := range queryNames() {
+		stmt, err := db.PrepareContext(ctx, queries[name])
+		if err != nil {
+			s.Close()
+			return nil, fmt.Errorf("preparing query %s: %w", name, err)
+		}
+		s.prepared[name] = stmt
+	}
+	return s, nil
+}
+
+// Close es una función que libera las sentencias preparadas
+func (s *statements) Close() error {
+	var first error
+	for _, stmt := range s.prepared {
+		if err := stmt.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// get es una función que devuelve la sentencia preparada y el texto de la consulta con el nombre dado.
+// Un nombre desconocido es un error de programación.
+func (s *statements) get(name string) (*sql.Stmt, string) {
+	stmt, ok := s.prepared[name]
+	if !ok {
+		panic(fmt.Sprintf("database: unknown query %s", name))
+	}
+	return stmt, queries[name]
+}
+
+// exec es una función que ejecuta la sentencia con el nombre dado en la conexión o transacción c, dentro de un span
+func (s *statements) exec(ctx context.Context, c preparer, name string, args ...interface{}) (sql.Result, error) {
+	stmt, query := s.get(name)
+	ctx, span := startQuerySpan(ctx, query)
+	result, err := c.stmt(ctx, stmt).ExecContext(ctx, args...)
+	endQuerySpan(span, err)
+	return result, err
+}
+
+// query es una función que ejecuta la consulta con el nombre dado en la conexión o transacción c, dentro de un span
+func (s *statements) query(ctx context.Context, c preparer, name string, args ...interface{}) (*sql.Rows, error) {
+	stmt, query := s.get(name)
+	ctx, span := startQuerySpan(ctx, query)
+	rows, err := c.stmt(ctx, stmt).QueryContext(ctx, args...)
+	endQuerySpan(span, err)
+	return rows, err
+}
+
+// queryRow es una función que ejecuta la consulta de una fila con el nombre dado en la conexión o transacción c,
+// dentro de un span
+func (s *statements) queryRow(ctx context.Context, c preparer, name string, args ...interface{}) *sql.Row {
+	stmt, query := s.get(name)
+	ctx, span := startQuerySpan(ctx, query)
+	row := c.stmt(ctx, stmt).QueryRowContext(ctx, args...)
+	endQuerySpan(span, row.Err())
+	return row
+}
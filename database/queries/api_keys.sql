@@ -0,0 +1,16 @@
+-- name: InsertAPIKey
+INSERT INTO api_keys (id, user_id, name, prefix, key_hash, scopes) VALUES ($1, $2, $3, $4, $5, $6) RETURNING created_at;
+
+-- name: GetAPIKeysByUser
+SELECT id, user_id, name, prefix, scopes, last_used_at, created_at FROM api_keys WHERE user_id = $1 AND revoked_at IS NULL ORDER BY created_at;
+
+-- name: GetAPIKeyByHash
+-- Solo las keys no revocadas de usuarios no eliminados
+SELECT k.id, k.user_id, k.name, k.prefix, k.key_hash, k.scopes, k.last_used_at, k.created_at FROM api_keys k
+    JOIN users u ON u.id = k.user_id WHERE k.key_hash = $1 AND k.revoked_at IS NULL AND u.deleted_at IS NULL;
+
+-- name: RevokeAPIKey
+UPDATE api_keys SET revoked_at = CURRENT_TIMESTAMP WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL;
+
+-- name: TouchAPIKey
+UPDATE api_keys SET last_used_at = CURRENT_TIMESTAMP WHERE id = $1;
@@ -0,0 +1,13 @@
+-- name: InsertAttachment
+-- Inserta el archivo solo si el reto existe y no está eliminado
+INSERT INTO challenge_attachments (id, challenge_id, user_id, filename, content_type, size, blob_key)
+    SELECT $1, id, $3, $4, $5, $6, $7 FROM challenges WHERE id = $2 AND deleted_at IS NULL RETURNING created_at;
+
+-- name: GetAttachments
+SELECT id, challenge_id, user_id, filename, content_type, size, blob_key, created_at FROM challenge_attachments WHERE challenge_id = $1 ORDER BY created_at, id;
+
+-- name: GetAttachmentById
+SELECT id, challenge_id, user_id, filename, content_type, size, blob_key, created_at FROM challenge_attachments WHERE challenge_id = $1 AND id = $2;
+
+-- name: DeleteAttachment
+DELETE FROM challenge_attachments WHERE challenge_id = $1 AND id = $2;
@@ -0,0 +1,5 @@
+-- Las consultas del registro de auditoría con filtros se construyen en Go según los filtros dados.
+
+-- name: InsertAuditEntry
+INSERT INTO audit_log (id, actor_id, api_key_id, action, entity_type, entity_id, before, after, request_id, ip)
+    VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), $4, $5, $6, $7, $8, NULLIF($9, ''), NULLIF($10, '')) RETURNING created_at;
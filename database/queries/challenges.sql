@@ -0,0 +1,35 @@
+-- name: InsertChallenge
+INSERT INTO challenges (id, title, description, difficulty, user_id) VALUES ($1, $2, $3, $4, $5) RETURNING created_at, updated_at, version;
+
+-- name: UpdateChallenge
+-- Los triggers de la tabla actualizan updated_at y version
+UPDATE challenges SET title = $1, description = $2, difficulty = $3, user_id = $4 WHERE id = $5 AND deleted_at IS NULL AND ($6 = 0 OR version = $6) RETURNING created_at, updated_at, version;
+
+-- name: SoftDeleteChallenge
+UPDATE challenges SET deleted_at = CURRENT_TIMESTAMP WHERE id = $1 AND deleted_at IS NULL AND ($2 = 0 OR version = $2);
+
+-- name: RestoreChallenge
+-- Restaura el reto solo si su dueño no está eliminado
+UPDATE challenges t SET deleted_at = NULL WHERE t.id = $1 AND t.deleted_at IS NOT NULL
+    AND NOT EXISTS (SELECT 1 FROM users u WHERE u.id = t.user_id AND u.deleted_at IS NOT NULL);
+
+-- name: ListChallenges
+-- Filtros: $1 usuario ('' no filtra) y $2 niveles de dificultad (NULL o vacío no filtra)
+SELECT id, title, description, difficulty, user_id, created_at, updated_at, version FROM challenges
+WHERE deleted_at IS NULL AND ($1::text = '' OR user_id = $1) AND (COALESCE(cardinality($2::int[]), 0) = 0 OR difficulty = ANY($2))
+ORDER BY id LIMIT $3 OFFSET $4;
+
+-- name: CountChallenges
+SELECT COUNT(*) FROM challenges
+WHERE deleted_at IS NULL AND ($1::text = '' OR user_id = $1) AND (COALESCE(cardinality($2::int[]), 0) = 0 OR difficulty = ANY($2));
+
+-- name: GetChallengeStats
+SELECT difficulty, COUNT(*) FROM challenges
+WHERE deleted_at IS NULL AND ($1::text = '' OR user_id = $1) AND (COALESCE(cardinality($2::int[]), 0) = 0 OR difficulty = ANY($2))
+GROUP BY difficulty;
+
+-- name: GetChallengeById
+SELECT id, title, description, difficulty, user_id, created_at, updated_at, version FROM challenges WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: ChallengeIsActive
+SELECT EXISTS (SELECT 1 FROM challenges WHERE id = $1 AND deleted_at IS NULL);
@@ -0,0 +1,6 @@
+-- Consultas comunes a todos los repositorios.
+-- Cada consulta empieza con un comentario "-- name: Nombre"; el nombre es el que usan los métodos del repositorio.
+
+-- name: Ping
+-- Verifica que la base de datos está disponible
+SELECT 1;
@@ -0,0 +1,29 @@
+-- name: InsertCompany
+INSERT INTO companies (id, name, image_path, location, industry, user_id) VALUES ($1, $2, $3, $4, $5, $6) RETURNING created_at, updated_at, version;
+
+-- name: UpdateCompany
+-- Los triggers de la tabla actualizan updated_at y version; image_path solo lo asigna la subida del logo
+UPDATE companies SET name = $1, location = $2, industry = $3, user_id = $4 WHERE id = $5 AND deleted_at IS NULL AND ($6 = 0 OR version = $6) RETURNING created_at, updated_at, version;
+
+-- name: SoftDeleteCompany
+UPDATE companies SET deleted_at = CURRENT_TIMESTAMP WHERE id = $1 AND deleted_at IS NULL AND ($2 = 0 OR version = $2);
+
+-- name: RestoreCompany
+-- Restaura la empresa solo si su dueño no está eliminado
+UPDATE companies t SET deleted_at = NULL WHERE t.id = $1 AND t.deleted_at IS NOT NULL
+    AND NOT EXISTS (SELECT 1 FROM users u WHERE u.id = t.user_id AND u.deleted_at IS NOT NULL);
+
+-- name: ListCompanies
+-- Filtro: $1 usuario ('' no filtra)
+SELECT id, name, image_path, location, industry, user_id, created_at, updated_at, version FROM companies
+WHERE deleted_at IS NULL AND ($1::text = '' OR user_id = $1)
+ORDER BY id LIMIT $2 OFFSET $3;
+
+-- name: CountCompanies
+SELECT COUNT(*) FROM companies WHERE deleted_at IS NULL AND ($1::text = '' OR user_id = $1);
+
+-- name: GetCompanyById
+SELECT id, name, image_path, location, industry, user_id, created_at, updated_at, version FROM companies WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: CompanyIsActive
+SELECT EXISTS (SELECT 1 FROM companies WHERE id = $1 AND deleted_at IS NULL);
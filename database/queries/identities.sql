@@ -0,0 +1,5 @@
+-- name: GetIdentity
+SELECT id, user_id, provider, subject, email FROM identities WHERE provider = $1 AND subject = $2;
+
+-- name: InsertIdentity
+INSERT INTO identities (id, user_id, provider, subject, email) VALUES ($1, $2, $3, $4, $5);
@@ -0,0 +1,31 @@
+-- name: GetUserMFA
+SELECT user_id, secret, enabled FROM user_mfa WHERE user_id = $1;
+
+-- name: SaveUserMFASecret
+-- Inserta o reemplaza el secreto, dejándolo desactivado hasta que se verifique
+INSERT INTO user_mfa (user_id, secret, enabled) VALUES ($1, $2, FALSE)
+    ON CONFLICT (user_id) DO UPDATE SET secret = EXCLUDED.secret, enabled = FALSE, last_totp_step = NULL, updated_at = CURRENT_TIMESTAMP;
+
+-- name: EnableUserMFA
+UPDATE user_mfa SET enabled = TRUE, updated_at = CURRENT_TIMESTAMP WHERE user_id = $1;
+
+-- name: DeleteUserMFA
+DELETE FROM user_mfa WHERE user_id = $1;
+
+-- name: InsertRecoveryCode
+INSERT INTO user_recovery_codes (id, user_id, code_hash) VALUES ($1, $2, $3);
+
+-- name: DeleteRecoveryCodes
+DELETE FROM user_recovery_codes WHERE user_id = $1;
+
+-- name: GetRecoveryCodes
+SELECT id, user_id, code_hash FROM user_recovery_codes WHERE user_id = $1 AND used_at IS NULL;
+
+-- name: UseRecoveryCode
+-- Marca el código como usado solo si no se había usado antes
+UPDATE user_recovery_codes SET used_at = CURRENT_TIMESTAMP WHERE id = $1 AND used_at IS NULL;
+
+-- name: UseTOTPStep
+-- Guarda la ventana del código TOTP aceptado solo si es posterior a la del último, para que no se pueda repetir
+UPDATE user_mfa SET last_totp_step = $2, updated_at = CURRENT_TIMESTAMP
+    WHERE user_id = $1 AND (last_totp_step IS NULL OR last_totp_step < $2);
@@ -0,0 +1,8 @@
+-- name: GetProgramById
+SELECT id, title, description, start_date, end_date, user_id, created_at, updated_at, version FROM programs WHERE id = $1;
+
+-- name: ProgramExists
+SELECT EXISTS (SELECT 1 FROM programs WHERE id = $1);
+
+-- name: InsertProgramParticipant
+INSERT INTO program_participants (id, program_id, entity_type, entity_id) VALUES ($1, $2, $3, $4);
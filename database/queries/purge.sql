@@ -0,0 +1,50 @@
+-- Eliminación definitiva de las filas marcadas como eliminadas antes de la fecha $1, en el orden en que se ejecutan.
+-- Antes de eliminarlas se leen las claves de sus archivos, que se eliminan del almacén tras confirmar la transacción.
+-- Los usuarios purgados son los de "SELECT id FROM users WHERE deleted_at < $1".
+
+-- name: PurgedAttachmentKeys
+-- Archivos de los adjuntos de los retos que se van a purgar; las filas se eliminan en cascada con los retos
+SELECT blob_key FROM challenge_attachments
+WHERE challenge_id IN (SELECT id FROM challenges WHERE deleted_at < $1 OR user_id IN (SELECT id FROM users WHERE deleted_at < $1));
+
+-- name: PurgedCompanyLogos
+-- Logos de las empresas que se van a purgar
+SELECT id, image_path FROM companies
+WHERE (deleted_at < $1 OR user_id IN (SELECT id FROM users WHERE deleted_at < $1)) AND image_path IS NOT NULL AND image_path <> '';
+
+-- name: PurgedUserAvatars
+-- Avatares de los usuarios que se van a purgar
+SELECT id, avatar_path FROM users WHERE deleted_at < $1 AND avatar_path <> '';
+
+-- name: PurgeProgramParticipants
+-- Participaciones de los retos, empresas, usuarios y programas que se van a purgar
+DELETE FROM program_participants
+WHERE (entity_type = 'challenge' AND entity_id IN (SELECT id FROM challenges WHERE deleted_at < $1 OR user_id IN (SELECT id FROM users WHERE deleted_at < $1)))
+    OR (entity_type = 'company' AND entity_id IN (SELECT id FROM companies WHERE deleted_at < $1 OR user_id IN (SELECT id FROM users WHERE deleted_at < $1)))
+    OR (entity_type = 'user' AND entity_id IN (SELECT id FROM users WHERE deleted_at < $1))
+    OR program_id IN (SELECT id FROM programs WHERE user_id IN (SELECT id FROM users WHERE deleted_at < $1));
+
+-- name: PurgeChallenges
+-- Retos vencidos o de usuarios purgados
+DELETE FROM challenges WHERE deleted_at < $1 OR user_id IN (SELECT id FROM users WHERE deleted_at < $1);
+
+-- name: PurgeCompanies
+DELETE FROM companies WHERE deleted_at < $1 OR user_id IN (SELECT id FROM users WHERE deleted_at < $1);
+
+-- name: PurgePrograms
+DELETE FROM programs WHERE user_id IN (SELECT id FROM users WHERE deleted_at < $1);
+
+-- name: PurgeRecoveryCodes
+DELETE FROM user_recovery_codes WHERE user_id IN (SELECT id FROM users WHERE deleted_at < $1);
+
+-- name: PurgeUserMFA
+DELETE FROM user_mfa WHERE user_id IN (SELECT id FROM users WHERE deleted_at < $1);
+
+-- name: PurgeIdentities
+DELETE FROM identities WHERE user_id IN (SELECT id FROM users WHERE deleted_at < $1);
+
+-- name: PurgeAPIKeys
+DELETE FROM api_keys WHERE user_id IN (SELECT id FROM users WHERE deleted_at < $1);
+
+-- name: PurgeUsers
+DELETE FROM users WHERE deleted_at < $1;
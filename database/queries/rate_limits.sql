@@ -0,0 +1,13 @@
+-- name: InsertRateLimit
+-- Crea la cubeta llena si no existe
+INSERT INTO rate_limits (key, tokens, updated_at, full_at) VALUES ($1, $2, $3, $3) ON CONFLICT (key) DO NOTHING;
+
+-- name: LockRateLimit
+SELECT tokens, updated_at FROM rate_limits WHERE key = $1 FOR UPDATE;
+
+-- name: UpdateRateLimit
+UPDATE rate_limits SET tokens = $2, updated_at = $3, full_at = $4 WHERE key = $1;
+
+-- name: DeleteFullRateLimits
+-- Cubetas que ya se llenaron de nuevo; una cubeta que no existe empieza llena
+DELETE FROM rate_limits WHERE full_at <= $1;
@@ -0,0 +1,44 @@
+-- name: InsertUser
+INSERT INTO users (id, fullname, email, password) VALUES ($1, $2, $3, $4) RETURNING created_at, updated_at, version;
+
+-- name: UpdateUser
+-- Los triggers de la tabla actualizan updated_at y version
+UPDATE users SET fullname = $1, email = $2 WHERE id = $3 AND deleted_at IS NULL AND ($4 = 0 OR version = $4) RETURNING created_at, updated_at, version;
+
+-- name: SoftDeleteUser
+UPDATE users SET deleted_at = CURRENT_TIMESTAMP WHERE id = $1 AND deleted_at IS NULL AND ($2 = 0 OR version = $2) RETURNING deleted_at;
+
+-- name: SoftDeleteUserChallenges
+-- Elimina en cascada los retos activos del usuario con su misma fecha de eliminación
+UPDATE challenges SET deleted_at = $1 WHERE user_id = $2 AND deleted_at IS NULL;
+
+-- name: SoftDeleteUserCompanies
+UPDATE companies SET deleted_at = $1 WHERE user_id = $2 AND deleted_at IS NULL;
+
+-- name: LockDeletedUser
+SELECT deleted_at FROM users WHERE id = $1 AND deleted_at IS NOT NULL FOR UPDATE;
+
+-- name: RestoreUser
+UPDATE users SET deleted_at = NULL WHERE id = $1;
+
+-- name: RestoreUserChallenges
+-- Restaura solo los retos eliminados en cascada con el usuario, no los que se eliminaron antes por separado
+UPDATE challenges SET deleted_at = NULL WHERE user_id = $1 AND deleted_at = $2;
+
+-- name: RestoreUserCompanies
+UPDATE companies SET deleted_at = NULL WHERE user_id = $1 AND deleted_at = $2;
+
+-- name: ListUsers
+SELECT id, fullname, email, avatar_path, created_at, updated_at, version FROM users WHERE deleted_at IS NULL ORDER BY id LIMIT $1 OFFSET $2;
+
+-- name: CountUsers
+SELECT COUNT(*) FROM users WHERE deleted_at IS NULL;
+
+-- name: GetUserById
+SELECT id, fullname, email, role, avatar_path, created_at, updated_at, version FROM users WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: GetUserByEmail
+SELECT id, fullname, email, password, role, avatar_path, created_at, updated_at, version FROM users WHERE email = $1 AND deleted_at IS NULL;
+
+-- name: UserIsActive
+SELECT EXISTS (SELECT 1 FROM users WHERE id = $1 AND deleted_at IS NULL);
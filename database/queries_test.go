@@ -0,0 +1,180 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/joho/godotenv" // para cargar variables de entorno desde un archivo .env
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseQueries(t *testing.T) {
+	parsed, err := parseQueries(fstest.MapFS{
+		"queries/a.sql": {Data: []byte("-- Comentario del archivo\n\n-- name: First\n-- Comentario de la consulta\nSELECT 1\nFROM users;\n\n-- name: Second\nSELECT 2;\n")},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"First": "SELECT 1\nFROM users", "Second": "SELECT 2"}, parsed)
+
+	// Los nombres repetidos, las consultas vacías y las consultas sin nombre son errores
+	_, err = parseQueries(fstest.MapFS{
+		"queries/a.sql": {Data: []byte("-- name: First\nSELECT 1;\n")},
+		"queries/b.sql": {Data: []byte("-- name: First\nSELECT 2;\n")},
+	})
+	assert.ErrorContains(t, err, "duplicate query First")
+	_, err = parseQueries(fstest.MapFS{"queries/a.sql": {Data: []byte("-- name: Empty\n-- name: Other\nSELECT 1;\n")}})
+	assert.ErrorContains(t, err, "query Empty is empty")
+	_, err = parseQueries(fstest.MapFS{"queries/a.sql": {Data: []byte("SELECT 1;\n")}})
+	assert.ErrorContains(t, err, "query without name")
+}
+
+// queryCall son las llamadas al registro de consultas con el nombre de la consulta
+var queryCall = regexp.MustCompile(`queries\.(?:exec|query|queryRow)\(ctx, [\w.]+, "(\w+)"`)
+
+func TestQueryNamesAreUsed(t *testing.T) {
+	// Leer el código del repositorio sin las pruebas
+	paths, err := filepath.Glob("*.go")
+	assert.NoError(t, err)
+	var source strings.Builder
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		content, err := os.ReadFile(path)
+		assert.NoError(t, err)
+		source.Write(content)
+	}
+
+	// Cada consulta que se ejecuta existe en los archivos .sql
+	for _, match := range queryCall.FindAllStringSubmatch(source.String(), -1) {
+		assert.Contains(t, queries, match[1], "query %s is not defined in queries/*.sql", match[1])
+	}
+	for table, names := range tableQueries {
+		for _, name := range []string{names.softDelete, names.restore, names.isActive} {
+			if name != "" {
+				assert.Contains(t, queries, name, "query %s of table %s is not defined in queries/*.sql", name, table)
+			}
+		}
+	}
+	// Y cada consulta de los archivos .sql se usa en el código
+	for _, name := range queryNames() {
+		assert.Contains(t, source.String(), `"`+name+`"`, "query %s is never used", name)
+	}
+}
+
+// openTestDB es una función que abre la base de datos de .env_test. Si no está disponible, la prueba se omite.
+func openTestDB(t *testing.T, ctx context.Context) *sql.DB {
+	godotenv.Load("./../.env_test")
+	url := os.Getenv("DATABASE_URL")
+	if url == "" {
+		t.Skip("DATABASE_URL is not set")
+	}
+	db, err := sql.Open("postgres", url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.PingContext(ctx); err != nil {
+		t.Skipf("database is not available: %v", err)
+	}
+	return db
+}
+
+// execFile es una función que ejecuta las sentencias del archivo dado en la transacción
+func execFile(t *testing.T, ctx context.Context, tx *sql.Tx, path string) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.ExecContext(ctx, string(content)); err != nil {
+		t.Fatalf("applying %s: %v", path, err)
+	}
+}
+
+// prepareQueries es una función que prepara cada consulta en la transacción; un error de sintaxis, de tabla o de
+// columna falla la prueba
+func prepareQueries(t *testing.T, ctx context.Context, tx *sql.Tx) {
+	for _, name := range queryNames() {
+		stmt, err := tx.PrepareContext(ctx, queries[name])
+		if !assert.NoError(t, err, "query %s", name) {
+			// La transacción queda abortada tras un error
+			return
+		}
+		stmt.Close()
+	}
+}
+
+// TestQueriesCompile prepara cada consulta contra el esquema de up.sql, aplicado dentro de una transacción
+// que se deshace al terminar. Necesita la base de datos de .env_test; si no está disponible, se omite.
+func TestQueriesCompile(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	db := openTestDB(t, ctx)
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+
+	// Aplicar el esquema sin modificar la base de datos
+	execFile(t, ctx, tx, "up.sql")
+	prepareQueries(t, ctx, tx)
+}
+
+// TestSchemaMigratesExistingDatabase aplica up.sql dos veces sobre el esquema anterior a las migraciones, en un
+// esquema de Postgres propio dentro de una transacción que se deshace al terminar
+func TestSchemaMigratesExistingDatabase(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	db := openTestDB(t, ctx)
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, "CREATE SCHEMA migration_test; SET LOCAL search_path TO migration_test"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Una base de datos con datos del esquema anterior, incluidas dificultades vacías y fuera de la escala
+	execFile(t, ctx, tx, "testdata/baseline.sql")
+	_, err = tx.ExecContext(ctx, `INSERT INTO users (id, fullname, email, password) VALUES ('u1', 'User', 'u1@example.com', 'hash');
+		INSERT INTO challenges (id, title, difficulty, user_id, created_at, updated_at) VALUES
+			('c1', 'Sin dificultad', NULL, 'u1', NULL, NULL), ('c2', 'Fuera de la escala', 9, 'u1', CURRENT_TIMESTAMP, NULL)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Las migraciones se pueden aplicar varias veces
+	execFile(t, ctx, tx, "up.sql")
+	execFile(t, ctx, tx, "up.sql")
+
+	rows, err := tx.QueryContext(ctx, "SELECT id, difficulty, version FROM challenges WHERE created_at IS NOT NULL AND updated_at IS NOT NULL AND deleted_at IS NULL ORDER BY id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrated := map[string][2]int{}
+	for rows.Next() {
+		var id string
+		var difficulty, version int
+		assert.NoError(t, rows.Scan(&id, &difficulty, &version))
+		migrated[id] = [2]int{difficulty, version}
+	}
+	assert.NoError(t, rows.Err())
+	rows.Close()
+	assert.Equal(t, map[string][2]int{"c1": {3, 1}, "c2": {5, 1}}, migrated)
+
+	// La restricción de la escala existe
+	_, err = tx.ExecContext(ctx, "SAVEPOINT invalid_difficulty; UPDATE challenges SET difficulty = 6 WHERE id = 'c1'")
+	assert.ErrorContains(t, err, "challenges_difficulty_check")
+	if _, err := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT invalid_difficulty"); err != nil {
+		t.Fatal(err)
+	}
+	prepareQueries(t, ctx, tx)
+}
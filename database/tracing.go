@@ -0,0 +1,116 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"talentpitchGo/tracing"
+
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracedDB es la conexión a la base de datos que abre un span por cada consulta, como hijo del span de la solicitud.
+// Los métodos que no se redefinen, como Close, son los de sql.DB.
+type tracedDB struct {
+	*sql.DB
+}
+
+// tracedTx es una transacción que abre un span por cada consulta
+type tracedTx struct {
+	*sql.Tx
+}
+
+// ExecContext ejecuta una sentencia dentro de un span
+func (t *tracedDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, span := startQuerySpan(ctx, query)
+	result, err := t.DB.ExecContext(ctx, query, args...)
+	endQuerySpan(span, err)
+	return result, err
+}
+
+// QueryContext ejecuta una consulta dentro de un span; el span termina al recibir la respuesta, no al leer las filas
+func (t *tracedDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	ctx, span := startQuerySpan(ctx, query)
+	rows, err := t.DB.QueryContext(ctx, query, args...)
+	endQuerySpan(span, err)
+	return rows, err
+}
+
+// QueryRowContext ejecuta una consulta de una fila dentro de un span
+func (t *tracedDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	ctx, span := startQuerySpan(ctx, query)
+	row := t.DB.QueryRowContext(ctx, query, args...)
+	endQuerySpan(span, row.Err())
+	return row
+}
+
+// BeginTx inicia una transacción cuyas consultas también abren un span
+func (t *tracedDB) BeginTx(ctx context.Context, options *sql.TxOptions) (transaction, error) {
+	tx, err := t.DB.BeginTx(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+	return &tracedTx{Tx: tx}, nil
+}
+
+// stmt devuelve la sentencia preparada tal cual: se ejecuta en cualquier conexión del pool
+func (t *tracedDB) stmt(ctx context.Context, stmt *sql.Stmt) *sql.Stmt {
+	return stmt
+}
+
+// stmt devuelve la sentencia preparada para ejecutarla en la conexión de la transacción
+func (t *tracedTx) stmt(ctx context.Context, stmt *sql.Stmt) *sql.Stmt {
+	return t.Tx.StmtContext(ctx, stmt)
+}
+
+// ExecContext ejecuta una sentencia de la transacción dentro de un span
+func (t *tracedTx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, span := startQuerySpan(ctx, query)
+	result, err := t.Tx.ExecContext(ctx, query, args...)
+	endQuerySpan(span, err)
+	return result, err
+}
+
+// QueryContext ejecuta una consulta de la transacción dentro de un span
+func (t *tracedTx) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	ctx, span := startQuerySpan(ctx, query)
+	rows, err := t.Tx.QueryContext(ctx, query, args...)
+	endQuerySpan(span, err)
+	return rows, err
+}
+
+// QueryRowContext ejecuta una consulta de una fila de la transacción dentro de un span
+func (t *tracedTx) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	ctx, span := startQuerySpan(ctx, query)
+	row := t.Tx.QueryRowContext(ctx, query, args...)
+	endQuerySpan(span, row.Err())
+	return row
+}
+
+// startQuerySpan es una función que abre el span de una consulta, con el nombre de su operación (SELECT, UPDATE...)
+// y el texto de la consulta; los valores de los parámetros no se incluyen
+func startQuerySpan(ctx context.Context, query string) (context.Context, trace.Span) {
+	operation := strings.ToUpper(strings.SplitN(strings.TrimSpace(query), " ", 2)[0])
+	return tracing.Tracer().Start(ctx, operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBOperationName(operation),
+			semconv.DBQueryText(query),
+		),
+	)
+}
+
+// endQuerySpan es una función que cierra el span de una consulta y registra el error, si lo hubo.
+// sql.ErrNoRows no es un error de la consulta sino un resultado vacío.
+func endQuerySpan(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,164 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"talentpitchGo/logging"
+	"talentpitchGo/metrics"
+	"talentpitchGo/repository"
+)
+
+// Códigos de error de Postgres que se resuelven volviendo a ejecutar la transacción
+const (
+	SERIALIZATION_FAILURE = "40001"
+	DEADLOCK_DETECTED     = "40P01"
+)
+
+// txRetryBackoff es la espera antes del primer reintento de una transacción; crece con cada intento
+var txRetryBackoff = 20 * time.Millisecond
+
+// conn es la interfaz de la conexión sobre la que se ejecutan las consultas del repositorio:
+// el pool de conexiones o la transacción de una unidad de trabajo
+type conn interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	BeginTx(ctx context.Context, options *sql.TxOptions) (transaction, error)
+	Close() error
+	preparer
+}
+
+// transaction es la interfaz de una transacción o de un punto de guardado dentro de ella
+type transaction interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	Commit() error
+	Rollback() error
+	preparer
+}
+
+// txConn es la conexión de una unidad de trabajo. Las transacciones que abren los métodos del repositorio
+// se convierten en puntos de guardado, así que solo WithTx confirma o deshace la transacción.
+type txConn struct {
+	transaction
+	savepoints *int // Número de puntos de guardado creados, para darles nombres únicos
+}
+
+// BeginTx crea un punto de guardado en la transacción; las opciones se ignoran porque son las de la transacción
+func (c txConn) BeginTx(ctx context.Context, options *sql.TxOptions) (transaction, error) {
+	*c.savepoints++
+	name := fmt.Sprintf("sp_%d", *c.savepoints)
+	if _, err := c.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return nil, err
+	}
+	return &savepoint{transaction: c.transaction, ctx: ctx, name: name}, nil
+}
+
+// Close devuelve un error: la conexión de una unidad de trabajo no se puede cerrar
+func (c txConn) Close() error {
+	return errors.New("cannot close the connection of a unit of work")
+}
+
+// savepoint es un punto de guardado: Commit lo libera y Rollback deshace los cambios hechos desde que se creó
+type savepoint struct {
+	transaction
+	ctx  context.Context
+	name string
+	done bool
+}
+
+// Commit libera el punto de guardado
+func (s *savepoint) Commit() error {
+	if s.done {
+		return sql.ErrTxDone
+	}
+	s.done = true
+	_, err := s.ExecContext(s.ctx, "RELEASE SAVEPOINT "+s.name)
+	return err
+}
+
+// Rollback deshace los cambios hechos desde el punto de guardado
+func (s *savepoint) Rollback() error {
+	if s.done {
+		return sql.ErrTxDone
+	}
+	s.done = true
+	_, err := s.ExecContext(s.ctx, "ROLLBACK TO SAVEPOINT "+s.name)
+	return err
+}
+
+// WithTx es una función que ejecuta fn con un repositorio cuyas consultas van a una misma transacción.
+// La transacción se confirma si fn no devuelve error y se vuelve a ejecutar si falla por serialización o por un
+// interbloqueo. Dentro de otra unidad de trabajo, fn se ejecuta en un punto de guardado y no se reintenta:
+// la que reintenta es la unidad de trabajo exterior.
+func (p *PostgresRepositoy) WithTx(ctx context.Context, options repository.TxOptions, fn func(repos repository.Repositories) error) error {
+	defer metrics.ObserveQuery("WithTx", time.Now())
+	// Verificar que el repositorio no sea nil
+	if p == nil || p.db == nil {
+		return errors.New("repository cannot be nil")
+	}
+	if _, nested := p.db.(txConn); nested {
+		options.MaxRetries = -1
+	}
+	return retryTx(ctx, options.MaxRetries, func() error {
+		return p.runTx(ctx, options, fn)
+	})
+}
+
+// runTx es una función que ejecuta fn una vez en una transacción y la confirma si no hubo error
+func (p *PostgresRepositoy) runTx(ctx context.Context, options repository.TxOptions, fn func(repos repository.Repositories) error) error {
+	tx, err := p.db.BeginTx(ctx, &sql.TxOptions{Isolation: options.Isolation, ReadOnly: options.ReadOnly})
+	if err != nil {
+		return err
+	}
+	// Deshacer la transacción si no se confirma
+	defer tx.Rollback()
+
+	// Ejecutar las operaciones con un repositorio sobre la transacción
+	repos := &PostgresRepositoy{db: txConn{transaction: tx, savepoints: new(int)}, queries: p.queries}
+	if nested, ok := p.db.(txConn); ok {
+		// Compartir el contador para que los nombres de los puntos de guardado no se repitan
+		repos.db = txConn{transaction: tx, savepoints: nested.savepoints}
+	}
+	if err = fn(repos); err != nil {
+		return err
+	}
+	// Confirmar la transacción
+	return tx.Commit()
+}
+
+// retryTx es una función que ejecuta run y lo vuelve a ejecutar, como mucho maxRetries veces, mientras falle
+// por un error que se resuelve repitiendo la transacción. maxRetries 0 usa TX_MAX_RETRIES y negativo no reintenta.
+func retryTx(ctx context.Context, maxRetries int, run func() error) error {
+	if maxRetries == 0 {
+		maxRetries = repository.TX_MAX_RETRIES
+	}
+	for attempt := 0; ; attempt++ {
+		err := run()
+		if err == nil || attempt >= maxRetries || !isRetryableTxError(err) {
+			return err
+		}
+		logging.FromContext(ctx).Warn("retrying transaction", "attempt", attempt+1, "error", err)
+		// Esperar antes de reintentar para no volver a chocar con la misma transacción
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(attempt+1) * txRetryBackoff):
+		}
+	}
+}
+
+// isRetryableTxError es una función que indica si la transacción falló por serialización o por un interbloqueo
+func isRetryableTxError(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	return pqErr.Code == SERIALIZATION_FAILURE || pqErr.Code == DEADLOCK_DETECTED
+}
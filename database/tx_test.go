@@ -0,0 +1,106 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeTx es una transacción que guarda las sentencias ejecutadas
+type fakeTx struct {
+	statements []string
+}
+
+func (f *fakeTx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	f.statements = append(f.statements, query)
+	return nil, nil
+}
+
+func (f *fakeTx) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (f *fakeTx) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return nil
+}
+
+func (f *fakeTx) stmt(ctx context.Context, stmt *sql.Stmt) *sql.Stmt {
+	return stmt
+}
+
+func (f *fakeTx) Commit() error   { return nil }
+func (f *fakeTx) Rollback() error { return nil }
+
+func TestRetryTx(t *testing.T) {
+	txRetryBackoff = 0
+	ctx := context.Background()
+	serialization := &pq.Error{Code: SERIALIZATION_FAILURE}
+
+	// Los fallos de serialización se reintentan hasta que la transacción se confirma
+	attempts := 0
+	err := retryTx(ctx, 0, func() error {
+		attempts++
+		if attempts < 3 {
+			return fmt.Errorf("insert company: %w", serialization)
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+
+	// Al agotar los reintentos se devuelve el último error
+	attempts = 0
+	err = retryTx(ctx, 2, func() error {
+		attempts++
+		return &pq.Error{Code: DEADLOCK_DETECTED}
+	})
+	assert.True(t, isRetryableTxError(err))
+	assert.Equal(t, 3, attempts)
+
+	// Los demás errores no se reintentan
+	attempts = 0
+	err = retryTx(ctx, 0, func() error {
+		attempts++
+		return &pq.Error{Code: "23505"}
+	})
+	assert.Error(t, err)
+	assert.False(t, isRetryableTxError(err))
+	assert.Equal(t, 1, attempts)
+
+	// Con reintentos negativos se ejecuta una sola vez
+	attempts = 0
+	retryTx(ctx, -1, func() error {
+		attempts++
+		return serialization
+	})
+	assert.Equal(t, 1, attempts)
+}
+
+func TestTxConnSavepoints(t *testing.T) {
+	ctx := context.Background()
+	tx := &fakeTx{}
+	conn := txConn{transaction: tx, savepoints: new(int)}
+
+	// Las transacciones de los métodos del repositorio son puntos de guardado con nombres únicos
+	first, err := conn.BeginTx(ctx, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, first.Commit())
+	assert.ErrorIs(t, first.Rollback(), sql.ErrTxDone)
+	second, err := conn.BeginTx(ctx, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, second.Rollback())
+	assert.Equal(t, []string{
+		"SAVEPOINT sp_1",
+		"RELEASE SAVEPOINT sp_1",
+		"SAVEPOINT sp_2",
+		"ROLLBACK TO SAVEPOINT sp_2",
+	}, tx.statements)
+
+	// La conexión de una unidad de trabajo no se cierra
+	assert.Error(t, conn.Close())
+}
@@ -4,21 +4,42 @@ CREATE TABLE IF NOT EXISTS users (
   FULLName VARCHAR(255) NOT NULL,
   email VARCHAR(255) NOT NULL,
   password VARCHAR(255) NOT NULL,
+  role VARCHAR(20) NOT NULL DEFAULT 'user', -- 'user' o 'admin'
+  avatar_path VARCHAR(255) NOT NULL DEFAULT '', -- URL del avatar subido; la escribe la subida de imágenes
   created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
-  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  version INT NOT NULL DEFAULT 1, -- Se incrementa en cada modificación; se expone como ETag
+  deleted_at TIMESTAMP NULL -- Borrado lógico; el proceso de purga elimina la fila al vencer la retención
 );  
 
 CREATE TABLE IF NOT EXISTS challenges (
     id VARCHAR(255) PRIMARY KEY,
     title VARCHAR(255),
     description TEXT,
-    difficulty INT,
+    difficulty INT NOT NULL CONSTRAINT challenges_difficulty_check CHECK (difficulty BETWEEN 1 AND 5), -- Escala de models.DIFFICULTIES
     user_id VARCHAR(255),
     FOREIGN KEY (user_id) REFERENCES users(id),
-    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP 
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    version INT NOT NULL DEFAULT 1, -- Se incrementa en cada modificación; se expone como ETag
+    deleted_at TIMESTAMP NULL
+);
+
+-- Archivos adjuntos de los retos; el contenido se guarda en el almacén de archivos con la clave blob_key
+CREATE TABLE IF NOT EXISTS challenge_attachments (
+    id VARCHAR(255) PRIMARY KEY,
+    challenge_id VARCHAR(255) NOT NULL,
+    FOREIGN KEY (challenge_id) REFERENCES challenges(id) ON DELETE CASCADE,
+    user_id VARCHAR(255) NOT NULL, -- Usuario que subió el archivo
+    filename VARCHAR(255) NOT NULL,
+    content_type VARCHAR(255) NOT NULL,
+    size BIGINT NOT NULL,
+    blob_key VARCHAR(512) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
 );
 
+CREATE INDEX IF NOT EXISTS challenge_attachments_challenge_idx ON challenge_attachments (challenge_id, created_at);
+
 CREATE TABLE IF NOT EXISTS companies (
     id VARCHAR(255) PRIMARY KEY,
     name VARCHAR(255),
@@ -27,8 +48,10 @@ CREATE TABLE IF NOT EXISTS companies (
     industry VARCHAR(255),
     user_id VARCHAR(255),
     FOREIGN KEY (user_id) REFERENCES users(id),
-    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP 
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    version INT NOT NULL DEFAULT 1, -- Se incrementa en cada modificación; se expone como ETag
+    deleted_at TIMESTAMP NULL
 );
  
 CREATE TABLE IF NOT EXISTS programs (
@@ -39,8 +62,9 @@ CREATE TABLE IF NOT EXISTS programs (
     end_date DATE,
     user_id VARCHAR(255),
     FOREIGN KEY (user_id) REFERENCES users(id),
-    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP 
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    version INT NOT NULL DEFAULT 1 -- Se incrementa en cada modificación; se expone como ETag
 );
 
 
@@ -57,3 +81,168 @@ CREATE TABLE IF NOT EXISTS program_participants (
  
 
 
+
+-- Migraciones de las bases de datos creadas con un esquema anterior: CREATE TABLE IF NOT EXISTS no cambia las
+-- tablas que ya existen, así que sus columnas y restricciones nuevas se agregan aquí. Cada sentencia se puede
+-- ejecutar varias veces; van antes de los triggers, que usan updated_at y version.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'user';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS avatar_path VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP NULL;
+
+ALTER TABLE challenges ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
+ALTER TABLE challenges ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP NULL;
+ALTER TABLE companies ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
+ALTER TABLE companies ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP NULL;
+ALTER TABLE programs ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
+
+-- created_at y updated_at eran opcionales en challenges, companies y programs
+UPDATE challenges SET created_at = COALESCE(created_at, CURRENT_TIMESTAMP), updated_at = COALESCE(updated_at, created_at, CURRENT_TIMESTAMP)
+    WHERE created_at IS NULL OR updated_at IS NULL;
+UPDATE companies SET created_at = COALESCE(created_at, CURRENT_TIMESTAMP), updated_at = COALESCE(updated_at, created_at, CURRENT_TIMESTAMP)
+    WHERE created_at IS NULL OR updated_at IS NULL;
+UPDATE programs SET created_at = COALESCE(created_at, CURRENT_TIMESTAMP), updated_at = COALESCE(updated_at, created_at, CURRENT_TIMESTAMP)
+    WHERE created_at IS NULL OR updated_at IS NULL;
+ALTER TABLE challenges ALTER COLUMN created_at SET NOT NULL, ALTER COLUMN updated_at SET NOT NULL;
+ALTER TABLE companies ALTER COLUMN created_at SET NOT NULL, ALTER COLUMN updated_at SET NOT NULL;
+ALTER TABLE programs ALTER COLUMN created_at SET NOT NULL, ALTER COLUMN updated_at SET NOT NULL;
+
+-- La dificultad era un entero libre: los retos sin dificultad quedan en el nivel intermedio y los niveles
+-- fuera de la escala se llevan al extremo más cercano antes de exigirla
+UPDATE challenges SET difficulty = LEAST(GREATEST(COALESCE(difficulty, 3), 1), 5)
+    WHERE difficulty IS NULL OR difficulty NOT BETWEEN 1 AND 5;
+ALTER TABLE challenges ALTER COLUMN difficulty SET NOT NULL;
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'challenges_difficulty_check' AND conrelid = 'challenges'::regclass) THEN
+        ALTER TABLE challenges ADD CONSTRAINT challenges_difficulty_check CHECK (difficulty BETWEEN 1 AND 5);
+    END IF;
+END;
+$$;
+
+-- updated_at se actualiza en cada modificación de la fila
+CREATE OR REPLACE FUNCTION set_updated_at() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.updated_at = CURRENT_TIMESTAMP;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- version se incrementa en cada modificación de la fila, para el control de concurrencia optimista
+CREATE OR REPLACE FUNCTION bump_version() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.version = OLD.version + 1;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS users_updated_at ON users;
+CREATE TRIGGER users_updated_at BEFORE UPDATE ON users
+    FOR EACH ROW EXECUTE PROCEDURE set_updated_at();
+DROP TRIGGER IF EXISTS challenges_updated_at ON challenges;
+CREATE TRIGGER challenges_updated_at BEFORE UPDATE ON challenges
+    FOR EACH ROW EXECUTE PROCEDURE set_updated_at();
+DROP TRIGGER IF EXISTS companies_updated_at ON companies;
+CREATE TRIGGER companies_updated_at BEFORE UPDATE ON companies
+    FOR EACH ROW EXECUTE PROCEDURE set_updated_at();
+DROP TRIGGER IF EXISTS programs_updated_at ON programs;
+CREATE TRIGGER programs_updated_at BEFORE UPDATE ON programs
+    FOR EACH ROW EXECUTE PROCEDURE set_updated_at();
+DROP TRIGGER IF EXISTS users_version ON users;
+CREATE TRIGGER users_version BEFORE UPDATE ON users
+    FOR EACH ROW EXECUTE PROCEDURE bump_version();
+DROP TRIGGER IF EXISTS challenges_version ON challenges;
+CREATE TRIGGER challenges_version BEFORE UPDATE ON challenges
+    FOR EACH ROW EXECUTE PROCEDURE bump_version();
+DROP TRIGGER IF EXISTS companies_version ON companies;
+CREATE TRIGGER companies_version BEFORE UPDATE ON companies
+    FOR EACH ROW EXECUTE PROCEDURE bump_version();
+DROP TRIGGER IF EXISTS programs_version ON programs;
+CREATE TRIGGER programs_version BEFORE UPDATE ON programs
+    FOR EACH ROW EXECUTE PROCEDURE bump_version();
+
+
+
+CREATE TABLE IF NOT EXISTS user_mfa (
+    user_id VARCHAR(255) PRIMARY KEY,
+    secret VARCHAR(255) NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    last_totp_step BIGINT, -- ventana del último código TOTP aceptado, para rechazar su reutilización
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE user_mfa ADD COLUMN IF NOT EXISTS last_totp_step BIGINT;
+
+CREATE TABLE IF NOT EXISTS user_recovery_codes (
+    id VARCHAR(255) PRIMARY KEY,
+    user_id VARCHAR(255) NOT NULL,
+    code_hash VARCHAR(255) NOT NULL, -- hash bcrypt del código, el código en claro solo se muestra una vez
+    used_at TIMESTAMP NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS identities (
+    id VARCHAR(255) PRIMARY KEY,
+    user_id VARCHAR(255) NOT NULL,
+    provider VARCHAR(50) NOT NULL, -- Nombre del proveedor: 'google', 'github', 'linkedin'
+    subject VARCHAR(255) NOT NULL, -- ID del usuario en el proveedor
+    email VARCHAR(255),
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    UNIQUE (provider, subject),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id VARCHAR(255) PRIMARY KEY,
+    user_id VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    prefix VARCHAR(20) NOT NULL, -- Primeros caracteres de la key, para reconocerla en los listados
+    key_hash VARCHAR(64) NOT NULL UNIQUE, -- SHA-256 de la key, la key en claro solo se muestra al crearla
+    scopes TEXT[] NOT NULL DEFAULT '{}',
+    last_used_at TIMESTAMP NULL,
+    revoked_at TIMESTAMP NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS audit_log (
+    id VARCHAR(255) PRIMARY KEY,
+    actor_id VARCHAR(255) NULL, -- Usuario que hizo el cambio; sin FK para conservar el registro tras la purga
+    api_key_id VARCHAR(255) NULL,
+    action VARCHAR(20) NOT NULL, -- 'create', 'update', 'delete' o 'restore'
+    entity_type VARCHAR(50) NOT NULL, -- 'user', 'challenge' o 'company'
+    entity_id VARCHAR(255) NOT NULL,
+    before JSONB NULL, -- Campos modificados antes del cambio
+    after JSONB NULL, -- Campos modificados después del cambio
+    request_id VARCHAR(255) NULL,
+    ip VARCHAR(64) NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS audit_log_entity_idx ON audit_log (entity_type, entity_id, created_at);
+CREATE INDEX IF NOT EXISTS audit_log_actor_idx ON audit_log (actor_id, created_at);
+
+-- El registro de auditoría es de solo inserción
+CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_log_append_only ON audit_log;
+CREATE TRIGGER audit_log_append_only BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE PROCEDURE audit_log_append_only();
+
+-- Cubetas del límite de solicitudes, compartidas entre las instancias de la API
+CREATE TABLE IF NOT EXISTS rate_limits (
+    key VARCHAR(255) PRIMARY KEY, -- Política y cliente (usuario, API key o IP)
+    tokens DOUBLE PRECISION NOT NULL, -- Fichas disponibles en updated_at
+    updated_at TIMESTAMPTZ NOT NULL,
+    full_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP -- Momento en que la cubeta vuelve a estar llena; después se puede eliminar
+);
+
+ALTER TABLE rate_limits ADD COLUMN IF NOT EXISTS full_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP;
+CREATE INDEX IF NOT EXISTS rate_limits_full_at_idx ON rate_limits (full_at);
@@ -0,0 +1,354 @@
+// El paquete docs genera la especificación OpenAPI 3 de la API a partir de los tipos de los controladores
+// y sirve la especificación y su interfaz interactiva.
+package docs
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"talentpitchGo/handlers" // controladores de rutas HTTP
+)
+
+// OPENAPI_VERSION es la versión de OpenAPI de la especificación.
+// API_TITLE y API_VERSION son el título y la versión de la API.
+const (
+	OPENAPI_VERSION = "3.0.3"
+	API_TITLE       = "TalentPitch API"
+	API_VERSION     = "1.0.0"
+)
+
+// Operation es la estructura de la documentación de una operación de la API
+type Operation struct {
+	Method      string      // Método HTTP
+	Path        string      // Ruta tal como se registra en BindRoutes, con sus variables ({id}, ...)
+	Legacy      string      // Ruta anterior sin versión, registrada como alias obsoleto; vacía si no tiene
+	Tag         string      // Grupo de la operación en la interfaz
+	Summary     string      // Descripción corta de la operación
+	Public      bool        // La operación no necesita autenticación
+	Scope       string      // Permiso que necesita una API key; vacío si solo acepta tokens JWT
+	Query       []string    // Parámetros de la URL
+	IfMatch     bool        // La operación acepta la cabecera If-Match
+	ContentType string      // Tipo del cuerpo de la solicitud; application/json si está vacío
+	Request     interface{} // Valor del tipo del cuerpo de la solicitud; nil si no tiene cuerpo
+	Status      int         // Código de la respuesta correcta
+	Response    interface{} // Valor del tipo de la respuesta correcta; nil si no tiene cuerpo
+	Errors      []int       // Códigos de error que puede devolver, con el mensaje en texto plano
+	Validated   bool        // Los campos del cuerpo se validan; responde 422 con los errores de los campos
+	RateLimited bool        // La ruta tiene una política de límite de solicitudes; responde 429 al superarla
+}
+
+// OneOf es el tipo de una respuesta que puede tener una de varias formas
+type OneOf []interface{}
+
+// HTML es el tipo de una respuesta en text/html
+type HTML string
+
+// Text es el tipo de una respuesta en text/plain
+type Text string
+
+// Binary es el tipo de un archivo en un formulario multipart
+type Binary []byte
+
+// pathParam es la expresión regular de las variables de una ruta
+var pathParam = regexp.MustCompile(`\{(\w+)\}`)
+
+// Spec es una función que construye la especificación OpenAPI de las operaciones dadas.
+// Los tipos de las solicitudes y respuestas se describen en components/schemas.
+func Spec(operations []Operation) map[string]interface{} {
+	schemas := map[string]interface{}{}
+	paths := map[string]map[string]interface{}{}
+	for _, operation := range operations {
+		// Agregar la operación en su ruta y, si tiene, en la ruta obsoleta
+		for _, path := range []string{operation.Path, operation.Legacy} {
+			if path == "" {
+				continue
+			}
+			if paths[path] == nil {
+				paths[path] = map[string]interface{}{}
+			}
+			paths[path][strings.ToLower(operation.Method)] = operation.document(path, path == operation.Legacy, schemas)
+		}
+	}
+	return map[string]interface{}{
+		"openapi": OPENAPI_VERSION,
+		"info": map[string]interface{}{
+			"title":   API_TITLE,
+			"version": API_VERSION,
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": schemas,
+			"securitySchemes": map[string]interface{}{
+				"bearerAuth": map[string]interface{}{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+				"apiKeyAuth": map[string]interface{}{"type": "apiKey", "in": "header", "name": "X-API-Key"},
+			},
+		},
+	}
+}
+
+// document es una función que construye el objeto OpenAPI de la operación en la ruta dada
+func (o Operation) document(path string, deprecated bool, schemas map[string]interface{}) map[string]interface{} {
+	document := map[string]interface{}{
+		"summary":     o.Summary,
+		"operationId": operationID(o.Method, path),
+		"tags":        []string{o.Tag},
+	}
+	if deprecated {
+		document["deprecated"] = true
+		document["description"] = "Alias obsoleto de `" + o.Method + " " + o.Path + "`."
+	}
+	// Describir la autenticación de la operación
+	switch {
+	case o.Public:
+		document["security"] = []interface{}{}
+	case o.Scope != "":
+		document["security"] = []interface{}{
+			map[string][]string{"bearerAuth": {}},
+			map[string][]string{"apiKeyAuth": {o.Scope}},
+		}
+	default:
+		document["security"] = []interface{}{map[string][]string{"bearerAuth": {}}}
+	}
+	// Describir los parámetros de la ruta, de la URL y las cabeceras
+	var parameters []interface{}
+	for _, match := range pathParam.FindAllStringSubmatch(path, -1) {
+		parameters = append(parameters, map[string]interface{}{
+			"name": match[1], "in": "path", "required": true, "schema": map[string]string{"type": "string"},
+		})
+	}
+	for _, name := range o.Query {
+		parameters = append(parameters, map[string]interface{}{
+			"name": name, "in": "query", "schema": map[string]string{"type": "string"},
+		})
+	}
+	if o.IfMatch {
+		parameters = append(parameters, map[string]interface{}{
+			"name": "If-Match", "in": "header", "description": "ETag leída del recurso", "schema": map[string]string{"type": "string"},
+		})
+	}
+	if parameters != nil {
+		document["parameters"] = parameters
+	}
+	// Describir el cuerpo de la solicitud
+	if o.Request != nil {
+		contentType := o.ContentType
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		document["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content":  map[string]interface{}{contentType: map[string]interface{}{"schema": schemaOf(o.Request, schemas)}},
+		}
+	}
+	// Describir las respuestas
+	status := o.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := map[string]interface{}{"description": http.StatusText(status)}
+	switch response := o.Response.(type) {
+	case nil:
+	case HTML:
+		success["content"] = map[string]interface{}{"text/html": map[string]interface{}{"schema": map[string]string{"type": "string"}}}
+	case Text:
+		success["content"] = map[string]interface{}{"text/plain": map[string]interface{}{"schema": map[string]string{"type": "string"}}}
+	case Binary:
+		success["content"] = map[string]interface{}{"application/octet-stream": map[string]interface{}{"schema": map[string]string{"type": "string", "format": "binary"}}}
+	default:
+		success["content"] = map[string]interface{}{"application/json": map[string]interface{}{"schema": schemaOf(response, schemas)}}
+	}
+	responses := map[string]interface{}{fmt.Sprint(status): success}
+	errorCodes := o.Errors
+	// Los cuerpos de las solicitudes tienen un tamaño máximo
+	if o.Request != nil {
+		errorCodes = append([]int{http.StatusRequestEntityTooLarge}, errorCodes...)
+	}
+	for _, code := range errorCodes {
+		responses[fmt.Sprint(code)] = map[string]interface{}{
+			"description": http.StatusText(code),
+			"content":     map[string]interface{}{"text/plain": map[string]interface{}{"schema": map[string]string{"type": "string"}}},
+		}
+	}
+	if o.Validated {
+		responses[fmt.Sprint(http.StatusUnprocessableEntity)] = map[string]interface{}{
+			"description": http.StatusText(http.StatusUnprocessableEntity),
+			"content":     map[string]interface{}{"application/json": map[string]interface{}{"schema": schemaOf(handlers.ValidationErrorResponse{}, schemas)}},
+		}
+	}
+	if o.RateLimited {
+		responses[fmt.Sprint(http.StatusTooManyRequests)] = map[string]interface{}{
+			"description": http.StatusText(http.StatusTooManyRequests),
+			"headers": map[string]interface{}{
+				"Retry-After":           map[string]interface{}{"schema": map[string]string{"type": "integer"}, "description": "Segundos hasta la siguiente solicitud permitida"},
+				"X-RateLimit-Limit":     map[string]interface{}{"schema": map[string]string{"type": "integer"}, "description": "Solicitudes seguidas que se permiten"},
+				"X-RateLimit-Remaining": map[string]interface{}{"schema": map[string]string{"type": "integer"}, "description": "Solicitudes que quedan"},
+				"X-RateLimit-Reset":     map[string]interface{}{"schema": map[string]string{"type": "integer"}, "description": "Segundos hasta recuperar el límite completo"},
+			},
+			"content": map[string]interface{}{"text/plain": map[string]interface{}{"schema": map[string]string{"type": "string"}}},
+		}
+	}
+	document["responses"] = responses
+	return document
+}
+
+// operationID es una función que construye un identificador único de la operación con el método y la ruta
+func operationID(method string, path string) string {
+	id := strings.ToLower(method)
+	for _, part := range strings.FieldsFunc(path, func(r rune) bool { return !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9') }) {
+		id += strings.ToUpper(part[:1]) + part[1:]
+	}
+	return id
+}
+
+// timeType es el tipo de las fechas, que se codifican como texto RFC 3339
+var timeType = reflect.TypeOf(time.Time{})
+
+// rawMessageType es el tipo de los documentos JSON sin estructura fija
+var rawMessageType = reflect.TypeOf(json.RawMessage{})
+
+// binaryType es el tipo de los archivos de los formularios multipart
+var binaryType = reflect.TypeOf(Binary{})
+
+// schemaOf es una función que describe el tipo del valor dado como un esquema JSON.
+// Las estructuras con nombre se agregan a schemas y se referencian con $ref.
+func schemaOf(value interface{}, schemas map[string]interface{}) interface{} {
+	if alternatives, ok := value.(OneOf); ok {
+		var oneOf []interface{}
+		for _, alternative := range alternatives {
+			oneOf = append(oneOf, schemaOf(alternative, schemas))
+		}
+		return map[string]interface{}{"oneOf": oneOf}
+	}
+	return schemaOfType(reflect.TypeOf(value), schemas)
+}
+
+// schemaOfType es una función que describe un tipo de Go como un esquema JSON
+func schemaOfType(t reflect.Type, schemas map[string]interface{}) interface{} {
+	switch {
+	case t == timeType:
+		return map[string]string{"type": "string", "format": "date-time"}
+	case t == rawMessageType:
+		return map[string]interface{}{}
+	case t == binaryType:
+		return map[string]string{"type": "string", "format": "binary"}
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		schema := schemaOfType(t.Elem(), schemas)
+		if object, ok := schema.(map[string]string); ok {
+			nullable := map[string]interface{}{"nullable": true}
+			for key, value := range object {
+				nullable[key] = value
+			}
+			return nullable
+		}
+		return schema
+	case reflect.String:
+		return map[string]string{"type": "string"}
+	case reflect.Bool:
+		return map[string]string{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]string{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]string{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": schemaOfType(t.Elem(), schemas)}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": schemaOfType(t.Elem(), schemas)}
+	case reflect.Struct:
+		// Las estructuras con nombre se describen una sola vez en components/schemas
+		if t.Name() != "" {
+			if _, ok := schemas[t.Name()]; !ok {
+				// Reservar el nombre antes de describir los campos, por si la estructura se referencia a sí misma
+				schemas[t.Name()] = map[string]interface{}{}
+				schemas[t.Name()] = structSchema(t, schemas)
+			}
+			return map[string]string{"$ref": "#/components/schemas/" + t.Name()}
+		}
+		return structSchema(t, schemas)
+	}
+	return map[string]interface{}{}
+}
+
+// structSchema es una función que describe los campos JSON de una estructura
+func structSchema(t reflect.Type, schemas map[string]interface{}) map[string]interface{} {
+	properties := map[string]interface{}{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		// Obtener el nombre JSON del campo; los campos sin exportar o con "-" no se codifican
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+		// Los campos de las estructuras embebidas sin nombre JSON se codifican como campos propios
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				for key, value := range structSchema(embedded, schemas)["properties"].(map[string]interface{}) {
+					if _, ok := properties[key]; !ok {
+						properties[key] = value
+					}
+				}
+				continue
+			}
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = schemaOfType(field.Type, schemas)
+	}
+	return map[string]interface{}{"type": "object", "properties": properties}
+}
+
+// SpecHandler es el controlador que sirve la especificación OpenAPI de las operaciones dadas
+func SpecHandler(operations []Operation) http.HandlerFunc {
+	// Codificar la especificación una sola vez
+	spec, err := json.Marshal(Spec(operations))
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err != nil {
+			// Retornar un error interno del servidor
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		// Retornar la respuesta
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(spec)
+	}
+}
+
+// UI_TEMPLATE es la página de Swagger UI que carga la especificación
+const UI_TEMPLATE = `<!DOCTYPE html>
+<html lang="es">
+<head>
+  <meta charset="utf-8">
+  <title>%s</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({ url: %q, dom_id: "#swagger-ui" });
+  </script>
+</body>
+</html>
+`
+
+// UIHandler es el controlador que sirve la interfaz interactiva de la especificación publicada en specURL
+func UIHandler(specURL string) http.HandlerFunc {
+	page := fmt.Sprintf(UI_TEMPLATE, API_TITLE, specURL)
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Retornar la respuesta
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(page))
+	}
+}
@@ -0,0 +1,198 @@
+package docs
+
+import (
+	"net/http"
+
+	"talentpitchGo/apikey"   // permisos de las API keys
+	"talentpitchGo/handlers" // controladores de rutas HTTP
+	"talentpitchGo/models"   // modelos de datos
+	"talentpitchGo/server"   // configuración del servidor
+)
+
+// Message es la estructura de las respuestas que solo contienen un mensaje
+type Message struct {
+	Message string `json:"message"`
+}
+
+// UserList es la estructura de la respuesta del listado de usuarios
+type UserList struct {
+	Users []models.User `json:"users"`
+	Total int           `json:"total"`
+}
+
+// ChallengeList es la estructura de la respuesta de los listados de retos; description_html solo se incluye con ?render=html
+type ChallengeList struct {
+	Challenges []handlers.RenderedChallenge `json:"challenges"`
+	Total      int                          `json:"total"`
+}
+
+// CompanyList es la estructura de la respuesta de los listados de empresas
+type CompanyList struct {
+	Companies []models.Company `json:"companies"`
+	Total     int              `json:"total"`
+}
+
+// APIKeyList es la estructura de la respuesta del listado de API keys
+type APIKeyList struct {
+	APIKeys []models.APIKey `json:"api_keys"`
+}
+
+// AuditList es la estructura de la respuesta de la consulta del registro de auditoría
+type AuditList struct {
+	Entries []models.AuditEntry `json:"entries"`
+	Total   int                 `json:"total"`
+}
+
+// ImageUpload es el formulario multipart de la subida de una imagen PNG, JPEG o GIF de hasta 5 MB
+type ImageUpload struct {
+	File Binary `json:"file"`
+}
+
+// FileUpload es el formulario multipart de la subida de un archivo adjunto
+type FileUpload struct {
+	File Binary `json:"file"`
+}
+
+// API es el prefijo de las rutas versionadas
+const API = server.API_V1_PREFIX
+
+// Parámetros de la URL comunes
+var (
+	PAGINATION = []string{"page", "pageSize"}
+	// CHALLENGE_FILTERS son los parámetros de los listados de retos; difficulty acepta números o nombres separados por comas
+	CHALLENGE_FILTERS = []string{"page", "pageSize", "difficulty", "render"}
+)
+
+// Códigos de error comunes de las modificaciones con control de versión
+var (
+	UPDATE_ERRORS = []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound, http.StatusPreconditionFailed, http.StatusPreconditionRequired}
+	PATCH_ERRORS  = []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound, http.StatusPreconditionFailed, http.StatusUnsupportedMediaType, http.StatusPreconditionRequired}
+	UPLOAD_ERRORS = []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound, http.StatusPreconditionFailed, http.StatusRequestEntityTooLarge, http.StatusUnsupportedMediaType, http.StatusPreconditionRequired}
+	DELETE_ERRORS = []int{http.StatusUnauthorized, http.StatusNotFound, http.StatusPreconditionFailed, http.StatusPreconditionRequired}
+	READ_ERRORS   = []int{http.StatusUnauthorized, http.StatusNotFound}
+)
+
+// OPERATIONS es la lista de las operaciones de la API, una por cada ruta registrada en BindRoutes
+var OPERATIONS = []Operation{
+	//****************************************************************************************************************
+	//***************************************************** HOME *****************************************************
+	//****************************************************************************************************************
+	{Method: http.MethodGet, Path: "/", Tag: "home", Summary: "Página de inicio", Public: true, Response: handlers.Home{}},
+	{Method: http.MethodGet, Path: "/openapi.json", Tag: "docs", Summary: "Especificación OpenAPI de la API", Public: true, Response: map[string]interface{}{}},
+	{Method: http.MethodGet, Path: "/docs", Tag: "docs", Summary: "Interfaz interactiva de la especificación", Public: true, Response: HTML("")},
+	//****************************************************************************************************************
+	//***************************************************** AUTH *****************************************************
+	//****************************************************************************************************************
+	{Method: http.MethodPost, Path: API + "/signup", Legacy: "/signup", Tag: "auth", Summary: "Registrar un usuario", Public: true, RateLimited: true,
+		Validated: true, Request: handlers.SingUpRequest{}, Response: handlers.SingUpResponse{}, Errors: []int{http.StatusBadRequest}},
+	{Method: http.MethodPost, Path: API + "/login", Legacy: "/login", Tag: "auth", Summary: "Iniciar sesión; con 2FA activo devuelve un token de desafío", Public: true, RateLimited: true,
+		Validated: true, Request: handlers.SingUpLoginRequest{}, Response: OneOf{handlers.LoginResponse{}, handlers.MFAChallengeResponse{}}, Errors: []int{http.StatusBadRequest, http.StatusUnauthorized}},
+	{Method: http.MethodPost, Path: API + "/login/mfa", Tag: "auth", Summary: "Completar el inicio de sesión con un código TOTP o de recuperación", Public: true, RateLimited: true,
+		Validated: true, Request: handlers.MFALoginRequest{}, Response: handlers.LoginResponse{}, Errors: []int{http.StatusBadRequest, http.StatusUnauthorized}},
+	{Method: http.MethodGet, Path: API + "/auth/{provider}/login", Tag: "auth", Summary: "Redirigir al proveedor externo (código de autorización + PKCE)", Public: true, RateLimited: true,
+		Status: http.StatusFound, Errors: []int{http.StatusNotFound}},
+	{Method: http.MethodGet, Path: API + "/auth/{provider}/callback", Tag: "auth", Summary: "Retorno del proveedor externo", Public: true, RateLimited: true,
+		Query: []string{"code", "state", "error"}, Response: OneOf{handlers.LoginResponse{}, handlers.MFAChallengeResponse{}}, Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound}},
+	//****************************************************************************************************************
+	//***************************************************** USER *****************************************************
+	//****************************************************************************************************************
+	{Method: http.MethodGet, Path: API + "/users", Legacy: "/users", Tag: "users", Summary: "Listar los usuarios", Scope: apikey.SCOPE_USERS_READ, RateLimited: true,
+		Query: PAGINATION, Response: UserList{}, Errors: []int{http.StatusUnauthorized}},
+	{Method: http.MethodPut, Path: API + "/users/{id}", Legacy: "/updateUser/{id}", Tag: "users", Summary: "Actualizar un usuario", IfMatch: true,
+		Validated: true, Request: handlers.UpdateUserRequest{}, Response: handlers.SingUpResponse{}, Errors: UPDATE_ERRORS},
+	{Method: http.MethodPatch, Path: API + "/users/{id}", Tag: "users", Summary: "Actualizar parcialmente un usuario (JSON Merge Patch)", IfMatch: true,
+		ContentType: handlers.MERGE_PATCH_CONTENT_TYPE, Validated: true, Request: models.User{}, Response: models.User{}, Errors: PATCH_ERRORS},
+	{Method: http.MethodDelete, Path: API + "/users/{id}", Legacy: "/deleteUser/{id}", Tag: "users", Summary: "Eliminar un usuario", IfMatch: true,
+		Response: Message{}, Errors: DELETE_ERRORS},
+	{Method: http.MethodPost, Path: API + "/users/{id}/avatar", Tag: "users", Summary: "Subir el avatar de un usuario y generar sus miniaturas", IfMatch: true,
+		ContentType: "multipart/form-data", Validated: true, Request: ImageUpload{}, Response: handlers.ImageUploadResponse{}, Errors: UPLOAD_ERRORS},
+	{Method: http.MethodGet, Path: API + "/users/{id}/challenges", Tag: "users", Summary: "Listar los retos de un usuario", Scope: apikey.SCOPE_CHALLENGES_READ, RateLimited: true,
+		Query: CHALLENGE_FILTERS, Response: ChallengeList{}, Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound}},
+	{Method: http.MethodGet, Path: API + "/users/{id}/companies", Tag: "users", Summary: "Listar las empresas de un usuario", Scope: apikey.SCOPE_COMPANIES_READ, RateLimited: true,
+		Query: PAGINATION, Response: CompanyList{}, Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound}},
+	{Method: http.MethodGet, Path: API + "/me", Legacy: "/me", Tag: "users", Summary: "Obtener el usuario autenticado",
+		Response: models.User{}, Errors: []int{http.StatusUnauthorized}},
+	//****************************************************************************************************************
+	//***************************************************** MFA ******************************************************
+	//****************************************************************************************************************
+	{Method: http.MethodPost, Path: API + "/mfa/totp/enroll", Tag: "mfa", Summary: "Generar un secreto TOTP pendiente de verificación",
+		Response: handlers.TOTPEnrollResponse{}, Errors: []int{http.StatusUnauthorized, http.StatusConflict}},
+	{Method: http.MethodPost, Path: API + "/mfa/totp/verify", Tag: "mfa", Summary: "Activar el 2FA y obtener los códigos de recuperación",
+		Validated: true, Request: handlers.MFACodeRequest{}, Response: handlers.RecoveryCodesResponse{}, Errors: []int{http.StatusBadRequest, http.StatusUnauthorized}},
+	{Method: http.MethodPost, Path: API + "/mfa/totp/disable", Tag: "mfa", Summary: "Desactivar el 2FA",
+		Validated: true, Request: handlers.MFACodeRequest{}, Response: Message{}, Errors: []int{http.StatusBadRequest, http.StatusUnauthorized}},
+	//****************************************************************************************************************
+	//***************************************************** API KEYS *************************************************
+	//****************************************************************************************************************
+	{Method: http.MethodPost, Path: API + "/api-keys", Tag: "api-keys", Summary: "Crear una API key; la key solo se muestra en esta respuesta",
+		Validated: true, Request: handlers.APIKeyRequest{}, Status: http.StatusCreated, Response: handlers.APIKeyResponse{}, Errors: []int{http.StatusBadRequest, http.StatusUnauthorized}},
+	{Method: http.MethodGet, Path: API + "/api-keys", Tag: "api-keys", Summary: "Listar las API keys del usuario autenticado",
+		Response: APIKeyList{}, Errors: []int{http.StatusUnauthorized}},
+	{Method: http.MethodDelete, Path: API + "/api-keys/{id}", Tag: "api-keys", Summary: "Revocar una API key",
+		Response: Message{}, Errors: READ_ERRORS},
+	//****************************************************************************************************************
+	//***************************************************** ADMIN ****************************************************
+	//****************************************************************************************************************
+	{Method: http.MethodPost, Path: API + "/admin/users/{id}/restore", Tag: "admin", Summary: "Restaurar un usuario eliminado",
+		Response: Message{}, Errors: []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound}},
+	{Method: http.MethodPost, Path: API + "/admin/challenges/{id}/restore", Tag: "admin", Summary: "Restaurar un reto eliminado",
+		Response: Message{}, Errors: []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound}},
+	{Method: http.MethodPost, Path: API + "/admin/companies/{id}/restore", Tag: "admin", Summary: "Restaurar una empresa eliminada",
+		Response: Message{}, Errors: []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound}},
+	{Method: http.MethodGet, Path: API + "/admin/audit", Tag: "admin", Summary: "Consultar el registro de auditoría",
+		Query: append([]string{"entity_type", "entity_id", "actor_id", "from", "to"}, PAGINATION...), Response: AuditList{}, Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden}},
+	//****************************************************************************************************************
+	//***************************************************** CHALLENGE ************************************************
+	//****************************************************************************************************************
+	{Method: http.MethodPost, Path: API + "/challenges", Legacy: "/challenges", Tag: "challenges", Summary: "Crear un reto", Scope: apikey.SCOPE_CHALLENGES_WRITE,
+		Validated: true, Request: handlers.ChallengeRequest{}, Response: handlers.ChallengeResponse{}, Errors: []int{http.StatusBadRequest, http.StatusUnauthorized}},
+	{Method: http.MethodGet, Path: API + "/challenges", Legacy: "/challenges", Tag: "challenges", Summary: "Listar los retos", Scope: apikey.SCOPE_CHALLENGES_READ, RateLimited: true,
+		Query: CHALLENGE_FILTERS, Response: ChallengeList{}, Errors: []int{http.StatusBadRequest, http.StatusUnauthorized}},
+	{Method: http.MethodGet, Path: API + "/challenges/difficulties", Tag: "challenges", Summary: "Listar la escala de dificultad de los retos", Scope: apikey.SCOPE_CHALLENGES_READ,
+		Response: handlers.DifficultiesResponse{}, Errors: []int{http.StatusUnauthorized}},
+	{Method: http.MethodGet, Path: API + "/challenges/stats", Tag: "challenges", Summary: "Contar los retos por nivel de dificultad", Scope: apikey.SCOPE_CHALLENGES_READ,
+		Query: []string{"user_id", "difficulty"}, Response: handlers.ChallengeStatsResponse{}, Errors: []int{http.StatusBadRequest, http.StatusUnauthorized}},
+	{Method: http.MethodGet, Path: API + "/challenges/{id}", Legacy: "/challenges/{id}", Tag: "challenges", Summary: "Obtener un reto; con render=html incluye description_html", Scope: apikey.SCOPE_CHALLENGES_READ,
+		Query: []string{"render"}, Response: OneOf{models.Challenge{}, handlers.RenderedChallenge{}}, Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound}},
+	{Method: http.MethodPut, Path: API + "/challenges/{id}", Legacy: "/updateChallenge/{id}", Tag: "challenges", Summary: "Actualizar un reto", Scope: apikey.SCOPE_CHALLENGES_WRITE, IfMatch: true,
+		Validated: true, Request: handlers.ChallengeRequest{}, Response: handlers.ChallengeResponse{}, Errors: UPDATE_ERRORS},
+	{Method: http.MethodPatch, Path: API + "/challenges/{id}", Tag: "challenges", Summary: "Actualizar parcialmente un reto (JSON Merge Patch)", Scope: apikey.SCOPE_CHALLENGES_WRITE, IfMatch: true,
+		ContentType: handlers.MERGE_PATCH_CONTENT_TYPE, Validated: true, Request: models.Challenge{}, Response: models.Challenge{}, Errors: PATCH_ERRORS},
+	{Method: http.MethodDelete, Path: API + "/challenges/{id}", Legacy: "/deleteChallenge/{id}", Tag: "challenges", Summary: "Eliminar un reto", Scope: apikey.SCOPE_CHALLENGES_WRITE, IfMatch: true,
+		Response: Message{}, Errors: DELETE_ERRORS},
+	{Method: http.MethodGet, Path: API + "/challenges/{id}/attachments", Tag: "challenges", Summary: "Listar los archivos adjuntos de un reto", Scope: apikey.SCOPE_CHALLENGES_READ,
+		Response: handlers.AttachmentList{}, Errors: READ_ERRORS},
+	{Method: http.MethodPost, Path: API + "/challenges/{id}/attachments", Tag: "challenges", Summary: "Adjuntar un archivo de hasta 25 MB a un reto (solo el dueño)", Scope: apikey.SCOPE_CHALLENGES_WRITE,
+		ContentType: "multipart/form-data", Validated: true, Request: FileUpload{}, Status: http.StatusCreated, Response: models.Attachment{},
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusRequestEntityTooLarge}},
+	{Method: http.MethodGet, Path: API + "/challenges/{id}/attachments/{attachmentId}", Tag: "challenges", Summary: "Descargar un archivo adjunto de un reto", Scope: apikey.SCOPE_CHALLENGES_READ,
+		Response: Binary{}, Errors: READ_ERRORS},
+	{Method: http.MethodDelete, Path: API + "/challenges/{id}/attachments/{attachmentId}", Tag: "challenges", Summary: "Eliminar un archivo adjunto de un reto (solo el dueño)", Scope: apikey.SCOPE_CHALLENGES_WRITE,
+		Response: Message{}, Errors: []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound}},
+	//****************************************************************************************************************
+	//***************************************************** COMPANY **************************************************
+	//****************************************************************************************************************
+	{Method: http.MethodPost, Path: API + "/companies", Legacy: "/companies", Tag: "companies", Summary: "Crear una empresa", Scope: apikey.SCOPE_COMPANIES_WRITE,
+		Validated: true, Request: handlers.CompanyRequest{}, Response: handlers.CompanyResponse{}, Errors: []int{http.StatusBadRequest, http.StatusUnauthorized}},
+	{Method: http.MethodGet, Path: API + "/companies", Legacy: "/companies", Tag: "companies", Summary: "Listar las empresas", Scope: apikey.SCOPE_COMPANIES_READ, RateLimited: true,
+		Query: PAGINATION, Response: CompanyList{}, Errors: []int{http.StatusBadRequest, http.StatusUnauthorized}},
+	{Method: http.MethodGet, Path: API + "/companies/{id}", Legacy: "/companies/{id}", Tag: "companies", Summary: "Obtener una empresa", Scope: apikey.SCOPE_COMPANIES_READ,
+		Response: models.Company{}, Errors: READ_ERRORS},
+	{Method: http.MethodPut, Path: API + "/companies/{id}", Legacy: "/updateCompany/{id}", Tag: "companies", Summary: "Actualizar una empresa", Scope: apikey.SCOPE_COMPANIES_WRITE, IfMatch: true,
+		Validated: true, Request: handlers.CompanyRequest{}, Response: models.Company{}, Errors: UPDATE_ERRORS},
+	{Method: http.MethodPatch, Path: API + "/companies/{id}", Tag: "companies", Summary: "Actualizar parcialmente una empresa (JSON Merge Patch)", Scope: apikey.SCOPE_COMPANIES_WRITE, IfMatch: true,
+		ContentType: handlers.MERGE_PATCH_CONTENT_TYPE, Validated: true, Request: models.Company{}, Response: models.Company{}, Errors: PATCH_ERRORS},
+	{Method: http.MethodDelete, Path: API + "/companies/{id}", Legacy: "/deleteCompany/{id}", Tag: "companies", Summary: "Eliminar una empresa", Scope: apikey.SCOPE_COMPANIES_WRITE, IfMatch: true,
+		Response: "", Errors: DELETE_ERRORS},
+	{Method: http.MethodPost, Path: API + "/companies/{id}/logo", Tag: "companies", Summary: "Subir el logo de una empresa, generar sus miniaturas y asignar image_path", Scope: apikey.SCOPE_COMPANIES_WRITE, IfMatch: true,
+		ContentType: "multipart/form-data", Validated: true, Request: ImageUpload{}, Response: handlers.ImageUploadResponse{}, Errors: UPLOAD_ERRORS},
+	{Method: http.MethodGet, Path: API + "/companies/{id}/challenges", Tag: "companies", Summary: "Listar los retos publicados por el dueño de la empresa", Scope: apikey.SCOPE_CHALLENGES_READ, RateLimited: true,
+		Query: CHALLENGE_FILTERS, Response: ChallengeList{}, Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound}},
+	//****************************************************************************************************************
+	//***************************************************** PROGRAM **************************************************
+	//****************************************************************************************************************
+	{Method: http.MethodGet, Path: API + "/programs/{id}", Tag: "programs", Summary: "Obtener un programa",
+		Response: models.Program{}, Errors: READ_ERRORS},
+	{Method: http.MethodPatch, Path: API + "/programs/{id}", Tag: "programs", Summary: "Actualizar parcialmente un programa (JSON Merge Patch)", IfMatch: true,
+		ContentType: handlers.MERGE_PATCH_CONTENT_TYPE, Validated: true, Request: models.Program{}, Response: models.Program{}, Errors: PATCH_ERRORS},
+}
@@ -0,0 +1,164 @@
+// El paquete handlers contiene los controladores de las rutas HTTP.
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"talentpitchGo/audit"      // registro de auditoría
+	"talentpitchGo/middleware" // autenticación de la solicitud
+	"talentpitchGo/models"     // modelos de datos
+	"talentpitchGo/repository" // operaciones de base de datos
+	"talentpitchGo/server"     // configuración del servidor
+
+	"github.com/gorilla/mux" // enrutador HTTP
+)
+
+// requireAdmin es una función que verifica que la solicitud venga de un usuario administrador.
+// Si no es así responde con el error correspondiente y devuelve falso.
+func requireAdmin(s server.Server, w http.ResponseWriter, r *http.Request) bool {
+	// Obtener los claims del token
+	claims, err := requestClaims(s, r)
+	if err != nil {
+		// Retornar un error de no autorizado
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return false
+	}
+	// Obtener el usuario para consultar su rol actual
+	user, err := repository.GetUserById(r.Context(), claims.UserId)
+	if err != nil || user.Role != models.ROLE_ADMIN {
+		// Retornar un error de acceso prohibido
+		http.Error(w, "Admin role required", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// isOwnerOrAdmin es una función que verifica que el usuario autenticado sea el dueño del recurso o un administrador.
+// Si no, responde 403 y devuelve false.
+func isOwnerOrAdmin(w http.ResponseWriter, r *http.Request, ownerID string, message string) bool {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return false
+	}
+	if principal.UserID == ownerID {
+		return true
+	}
+	// Los administradores pueden modificar cualquier recurso
+	user, err := repository.GetUserById(r.Context(), principal.UserID)
+	if err != nil || user.Role != models.ROLE_ADMIN {
+		http.Error(w, message, http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// restoreHandler es una función que construye un controlador de administración que restaura un recurso eliminado
+func restoreHandler(s server.Server, restore func(ctx context.Context, id string) error, entityType string, notFound string, message string) http.HandlerFunc {
+	// Retornar la función del controlador
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Verificar que el usuario sea administrador
+		if !requireAdmin(s, w, r) {
+			return
+		}
+		// Restaurar el recurso
+		id := mux.Vars(r)["id"]
+		err := restore(r.Context(), id)
+		if err != nil {
+			// Si hay un error, verifica si es porque el recurso no fue encontrado
+			if errors.Is(err, repository.ErrNotFound) {
+				http.Error(w, notFound, http.StatusNotFound)
+			} else {
+				// Si es otro error, maneja ese error
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		// Registrar la restauración en el registro de auditoría
+		audit.Record(r, models.AUDIT_RESTORE, entityType, id, nil, nil)
+		// Retornar la respuesta
+		w.Header().Set("Content-Type", "application/json")
+		// Codificar la respuesta
+		json.NewEncoder(w).Encode(map[string]string{"message": message})
+	}
+}
+
+// RestoreUserHandler es el controlador de administración que restaura un usuario eliminado junto con sus recursos
+func RestoreUserHandler(s server.Server) http.HandlerFunc {
+	return restoreHandler(s, repository.RestoreUser, models.ENTITY_USER, "Usuario eliminado no encontrado", "User restored")
+}
+
+// RestoreChallengeHandler es el controlador de administración que restaura un reto eliminado
+func RestoreChallengeHandler(s server.Server) http.HandlerFunc {
+	return restoreHandler(s, repository.RestoreChallenge, models.ENTITY_CHALLENGE, "Reto eliminado no encontrado", "Challenge restored")
+}
+
+// RestoreCompanyHandler es el controlador de administración que restaura una empresa eliminada
+func RestoreCompanyHandler(s server.Server) http.HandlerFunc {
+	return restoreHandler(s, repository.RestoreCompany, models.ENTITY_COMPANY, "Empresa eliminada no encontrada", "Company restored")
+}
+
+// AUDIT_PAGE_SIZE es el tamaño de página por defecto de la consulta del registro de auditoría
+const AUDIT_PAGE_SIZE = 50
+
+// GetAuditLogHandler es el controlador de administración que consulta el registro de auditoría.
+// Filtra por entity_type, entity_id, actor_id y el rango de fechas from/to (RFC 3339).
+func GetAuditLogHandler(s server.Server) http.HandlerFunc {
+	// Retornar la función del controlador
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Verificar que el usuario sea administrador
+		if !requireAdmin(s, w, r) {
+			return
+		}
+		query := r.URL.Query()
+		// Construir el filtro con los parámetros de la URL
+		filter := models.AuditFilter{
+			EntityType: query.Get("entity_type"),
+			EntityID:   query.Get("entity_id"),
+			ActorID:    query.Get("actor_id"),
+		}
+		for name, target := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+			if value := query.Get(name); value != "" {
+				parsed, err := time.Parse(time.RFC3339, value)
+				if err != nil {
+					// Retornar un error de solicitud incorrecta
+					http.Error(w, "Invalid '"+name+"' date, expected RFC 3339", http.StatusBadRequest)
+					return
+				}
+				*target = &parsed
+			}
+		}
+		// Obtener la página y su tamaño, con valores por defecto
+		page, pageSize, err := pageParams(r, AUDIT_PAGE_SIZE)
+		if err != nil {
+			// Retornar un error de solicitud incorrecta
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if filter.From != nil && filter.To != nil && filter.To.Before(*filter.From) {
+			// Retornar un error de solicitud incorrecta
+			http.Error(w, "'to' cannot be before 'from'", http.StatusBadRequest)
+			return
+		}
+		// Consultar el registro
+		entries, total, err := repository.GetAuditEntries(r.Context(), filter, page, pageSize)
+		if err != nil {
+			// Retornar un error interno del servidor
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		// Crear un mapa con las entradas y el total
+		response := map[string]interface{}{
+			"entries": entries,
+			"total":   total,
+		}
+		// Retornar la respuesta
+		w.Header().Set("Content-Type", "application/json")
+		// Codificar la respuesta
+		json.NewEncoder(w).Encode(response)
+	}
+}
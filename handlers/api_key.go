@@ -0,0 +1,158 @@
+// El paquete handlers contiene los controladores de las rutas HTTP.
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"talentpitchGo/apikey"     // generación y permisos de API keys
+	"talentpitchGo/models"     // modelos de datos
+	"talentpitchGo/repository" // operaciones de base de datos
+	"talentpitchGo/server"     // configuración del servidor
+	"talentpitchGo/validation" // validación de las solicitudes
+
+	"github.com/gorilla/mux"     // enrutador HTTP
+	"github.com/segmentio/ksuid" // para generar IDs únicos
+)
+
+// APIKeyRequest es la estructura de los datos necesarios para crear una API key.
+type APIKeyRequest struct {
+	Name   string   `json:"name" validate:"required,max=255"`
+	Scopes []string `json:"scopes" validate:"required,min=1"`
+}
+
+// APIKeyResponse es la estructura de la respuesta de creación de una API key; la key solo se muestra aquí.
+type APIKeyResponse struct {
+	Id        string    `json:"id"`
+	Name      string    `json:"name"`
+	Prefix    string    `json:"prefix"`
+	Scopes    []string  `json:"scopes"`
+	Key       string    `json:"key"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreateAPIKeyHandler es el controlador que crea una API key para el usuario autenticado
+func CreateAPIKeyHandler(s server.Server) http.HandlerFunc {
+	// Retornar la función del controlador
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Obtener los claims del token
+		claims, err := requestClaims(s, r)
+		if err != nil {
+			// Retornar un error de no autorizado
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		// Decodificar el cuerpo de la solicitud
+		var request APIKeyRequest
+		err = decodeJSON(w, r, &request)
+		if err != nil {
+			// Retornar el error de decodificación
+			writeDecodeError(w, err)
+			return
+		}
+		// Validar los campos de la solicitud
+		if !validateRequest(w, &request) {
+			return
+		}
+		// Verificar que los permisos existan
+		for _, scope := range request.Scopes {
+			if !apikey.ValidScope(scope) {
+				writeValidationError(w, validation.Errors{{Field: "scopes", Code: "scope", Message: "invalid scope " + scope}})
+				return
+			}
+		}
+		// Generar la key
+		key, prefix, err := apikey.Generate()
+		if err != nil {
+			// Retornar un error interno del servidor
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		// Crear una nueva estructura de API key con el hash de la key
+		apiKey := models.APIKey{
+			Id:      ksuid.New().String(),
+			UserID:  claims.UserId,
+			Name:    request.Name,
+			Prefix:  prefix,
+			KeyHash: apikey.Hash(key),
+			Scopes:  request.Scopes,
+		}
+		// Guardar la key en la base de datos
+		err = repository.InsertAPIKey(r.Context(), &apiKey)
+		if err != nil {
+			// Retornar un error interno del servidor
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		// Retornar la respuesta
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		// Codificar la respuesta
+		json.NewEncoder(w).Encode(APIKeyResponse{
+			Id:        apiKey.Id,
+			Name:      apiKey.Name,
+			Prefix:    apiKey.Prefix,
+			Scopes:    apiKey.Scopes,
+			Key:       key,
+			CreatedAt: apiKey.CreatedAt,
+		})
+	}
+}
+
+// ListAPIKeysHandler es el controlador que lista las API keys activas del usuario autenticado
+func ListAPIKeysHandler(s server.Server) http.HandlerFunc {
+	// Retornar la función del controlador
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Obtener los claims del token
+		claims, err := requestClaims(s, r)
+		if err != nil {
+			// Retornar un error de no autorizado
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		// Obtener las keys del usuario
+		keys, err := repository.GetAPIKeysByUser(r.Context(), claims.UserId)
+		if err != nil {
+			// Retornar un error interno del servidor
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		// Retornar la respuesta
+		w.Header().Set("Content-Type", "application/json")
+		// Codificar la respuesta
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"api_keys": keys,
+		})
+	}
+}
+
+// RevokeAPIKeyHandler es el controlador que revoca una API key del usuario autenticado
+func RevokeAPIKeyHandler(s server.Server) http.HandlerFunc {
+	// Retornar la función del controlador
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Obtener los claims del token
+		claims, err := requestClaims(s, r)
+		if err != nil {
+			// Retornar un error de no autorizado
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		// Revocar la key
+		err = repository.RevokeAPIKey(r.Context(), claims.UserId, mux.Vars(r)["id"])
+		if errors.Is(err, repository.ErrAPIKeyNotFound) {
+			http.Error(w, "API key no encontrada", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			// Retornar un error interno del servidor
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		// Retornar la respuesta
+		w.Header().Set("Content-Type", "application/json")
+		// Codificar la respuesta
+		json.NewEncoder(w).Encode(map[string]string{"message": "API key revoked"})
+	}
+}
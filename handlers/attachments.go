@@ -0,0 +1,255 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"talentpitchGo/audit"      // registro de auditoría
+	"talentpitchGo/logging"    // logger de la solicitud
+	"talentpitchGo/middleware" // usuario autenticado
+	"talentpitchGo/models"     // modelos de datos
+	"talentpitchGo/repository" // operaciones de base de datos
+	"talentpitchGo/server"     // configuración del servidor
+	"talentpitchGo/storage"    // almacén de archivos
+
+	"github.com/gorilla/mux"     // enrutador HTTP
+	"github.com/segmentio/ksuid" // para generar IDs únicos
+)
+
+// MAX_ATTACHMENT_SIZE es el tamaño máximo de un archivo adjunto: 25 MB
+const MAX_ATTACHMENT_SIZE = 25 << 20
+
+// AttachmentList es la respuesta del listado de los archivos adjuntos de un reto.
+type AttachmentList struct {
+	Attachments []*models.Attachment `json:"attachments"`
+	Total       int                  `json:"total"`
+}
+
+// attachmentURL es una función que devuelve la ruta de descarga de un archivo adjunto.
+func attachmentURL(attachment *models.Attachment) string {
+	return server.API_V1_PREFIX + "/challenges/" + attachment.ChallengeID + "/attachments/" + attachment.Id
+}
+
+// ownedChallenge es una función que obtiene el reto de la ruta y verifica que el usuario autenticado sea su dueño.
+// Si no, responde el error y devuelve false.
+func ownedChallenge(w http.ResponseWriter, r *http.Request) (*models.Challenge, bool) {
+	challenge, ok := routeChallenge(w, r)
+	if !ok {
+		return nil, false
+	}
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return nil, false
+	}
+	if principal.UserID != challenge.UserID {
+		http.Error(w, "Only the owner of the challenge can change its attachments", http.StatusForbidden)
+		return nil, false
+	}
+	return challenge, true
+}
+
+// routeChallenge es una función que obtiene el reto del ID de la ruta. Si no existe, responde 404 y devuelve false.
+func routeChallenge(w http.ResponseWriter, r *http.Request) (*models.Challenge, bool) {
+	challenge, err := repository.GetChallengeById(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			http.Error(w, "Reto no encontrado", http.StatusNotFound)
+		} else {
+			// Retornar un error interno del servidor
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return nil, false
+	}
+	return challenge, true
+}
+
+// cleanFilename es una función que deja solo el nombre base del archivo, sin rutas ni caracteres de control.
+func cleanFilename(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == '"' {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+	if name == "" || name == "." || name == "/" {
+		return "attachment"
+	}
+	// Recortar los nombres largos conservando la extensión
+	if len(name) > 255 {
+		extension := filepath.Ext(name)
+		if len(extension) > 32 {
+			extension = ""
+		}
+		name = strings.ToValidUTF8(name[:255-len(extension)], "") + extension
+	}
+	return name
+}
+
+// attachmentType es una función que obtiene el tipo del archivo por su extensión o, si no se conoce, por su contenido.
+func attachmentType(filename string, data []byte) string {
+	if contentType := mime.TypeByExtension(strings.ToLower(filepath.Ext(filename))); contentType != "" {
+		return contentType
+	}
+	return http.DetectContentType(data)
+}
+
+// UploadAttachmentHandler es el controlador que agrega un archivo adjunto a un reto. Solo lo puede usar el dueño del reto.
+func UploadAttachmentHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Obtener el reto y verificar que el usuario sea su dueño
+		challenge, ok := ownedChallenge(w, r)
+		if !ok {
+			return
+		}
+		// Leer el archivo del formulario
+		data, filename, ok := readUpload(w, r, MAX_ATTACHMENT_SIZE)
+		if !ok {
+			return
+		}
+		principal, _ := middleware.PrincipalFromContext(r.Context())
+		attachment := &models.Attachment{
+			Id:          ksuid.New().String(),
+			ChallengeID: challenge.Id,
+			UserID:      principal.UserID,
+			Filename:    cleanFilename(filename),
+			Size:        int64(len(data)),
+		}
+		attachment.ContentType = attachmentType(attachment.Filename, data)
+		// La clave no incluye el nombre del cliente, que puede tener caracteres no permitidos. Es privada para que el
+		// archivo solo se descargue con este controlador, que verifica el acceso y evita que se muestre como página.
+		attachment.BlobKey = storage.PRIVATE_PREFIX + "challenges/" + challenge.Id + "/attachments/" + attachment.Id
+		// Guardar el contenido y después la fila
+		if _, err := storage.Put(r.Context(), attachment.BlobKey, attachment.ContentType, data); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := repository.InsertAttachment(r.Context(), attachment); err != nil {
+			// No dejar el archivo sin fila
+			deleteBlobs(r.Context(), []string{attachment.BlobKey})
+			if errors.Is(err, repository.ErrNotFound) {
+				http.Error(w, "Reto no encontrado", http.StatusNotFound)
+			} else if !writeValidationError(w, err) {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		// Registrar el alta en el registro de auditoría
+		audit.Record(r, models.AUDIT_CREATE, models.ENTITY_ATTACHMENT, attachment.Id, nil, attachment)
+		attachment.DownloadURL = attachmentURL(attachment)
+		// Retornar la respuesta
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Location", attachment.DownloadURL)
+		w.WriteHeader(http.StatusCreated)
+		// Codificar el archivo adjunto
+		json.NewEncoder(w).Encode(attachment)
+	}
+}
+
+// ListAttachmentsHandler es el controlador que lista los archivos adjuntos de un reto.
+func ListAttachmentsHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Verificar que el reto exista
+		challenge, ok := routeChallenge(w, r)
+		if !ok {
+			return
+		}
+		// Obtener los archivos adjuntos del reto
+		attachments, err := repository.GetAttachments(r.Context(), challenge.Id)
+		if err != nil {
+			// Retornar un error interno del servidor
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		for _, attachment := range attachments {
+			attachment.DownloadURL = attachmentURL(attachment)
+		}
+		// Retornar la respuesta
+		w.Header().Set("Content-Type", "application/json")
+		// Codificar la respuesta
+		json.NewEncoder(w).Encode(AttachmentList{Attachments: attachments, Total: len(attachments)})
+	}
+}
+
+// DownloadAttachmentHandler es el controlador que descarga el contenido de un archivo adjunto.
+func DownloadAttachmentHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Verificar que el reto exista
+		challenge, ok := routeChallenge(w, r)
+		if !ok {
+			return
+		}
+		// Obtener el archivo adjunto
+		attachment, err := repository.GetAttachmentById(r.Context(), challenge.Id, mux.Vars(r)["attachmentId"])
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				http.Error(w, "Archivo adjunto no encontrado", http.StatusNotFound)
+			} else {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		// Abrir el contenido en el almacén
+		body, err := storage.Get(r.Context(), attachment.BlobKey)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				http.Error(w, "Archivo adjunto no encontrado", http.StatusNotFound)
+			} else {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		defer body.Close()
+		// Descargar siempre como archivo: el contenido lo sube un usuario y no se debe mostrar como una página de la API
+		w.Header().Set("Content-Type", attachment.ContentType)
+		w.Header().Set("Content-Length", strconv.FormatInt(attachment.Size, 10))
+		w.Header().Set("Content-Disposition", "attachment; filename*=UTF-8''"+url.PathEscape(attachment.Filename))
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("Content-Security-Policy", "sandbox")
+		if _, err := io.Copy(w, body); err != nil {
+			logging.FromContext(r.Context()).Warn("error sending attachment", "attachment_id", attachment.Id, "error", err)
+		}
+	}
+}
+
+// DeleteAttachmentHandler es el controlador que elimina un archivo adjunto de un reto. Solo lo puede usar el dueño del reto.
+func DeleteAttachmentHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Obtener el reto y verificar que el usuario sea su dueño
+		challenge, ok := ownedChallenge(w, r)
+		if !ok {
+			return
+		}
+		// Obtener el archivo adjunto
+		attachment, err := repository.GetAttachmentById(r.Context(), challenge.Id, mux.Vars(r)["attachmentId"])
+		if err == nil {
+			err = repository.DeleteAttachment(r.Context(), challenge.Id, attachment.Id)
+		}
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				http.Error(w, "Archivo adjunto no encontrado", http.StatusNotFound)
+			} else {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		// Eliminar el contenido; si falla, la fila ya no existe y el archivo queda inaccesible
+		deleteBlobs(r.Context(), []string{attachment.BlobKey})
+		// Registrar la eliminación en el registro de auditoría
+		audit.Record(r, models.AUDIT_DELETE, models.ENTITY_ATTACHMENT, attachment.Id, attachment, nil)
+		// Retornar la respuesta
+		w.Header().Set("Content-Type", "application/json")
+		// Codificar la respuesta
+		json.NewEncoder(w).Encode(map[string]string{"message": "Attachment deleted"})
+	}
+}
@@ -3,13 +3,16 @@ package handlers
 
 import (
 	"encoding/json"
-	"log"
 	"net/http"
-	"strconv"
+	"strings"
 	"fmt"
 	"errors"
+	"time"
 	
 
+	"talentpitchGo/audit"      // registro de auditoría
+	"talentpitchGo/markdown"   // descripciones en markdown
+	"talentpitchGo/metrics"    // métricas de Prometheus
 	"talentpitchGo/models"     // modelos de datos
 	"talentpitchGo/repository" // operaciones de base de datos
 	"talentpitchGo/server"     // configuración del servidor
@@ -22,15 +25,17 @@ import (
 
 type ChallengeRequest  struct {
 	Id          string `json:"id"`
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	Difficulty  int    `json:"difficulty"`
-	UserID      string `json:"user_id"`
+	Title       string `json:"title" validate:"required,max=255"`
+	Description string `json:"description" validate:"required"`
+	Difficulty  int    `json:"difficulty" validate:"required,difficulty"`
+	UserID      string `json:"user_id" validate:"required"`
 }
 
 type ChallengeResponse struct {
 	Id          string `json:"id"`
 	Title       string `json:"title"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
 }
 
 
@@ -42,25 +47,20 @@ func CreateChallengeHandler(s server.Server) http.HandlerFunc {
 			var request ChallengeRequest
 			
 			// Decodificar el cuerpo de la solicitud
-			err := json.NewDecoder(r.Body).Decode(&request)
+			err := decodeJSON(w, r, &request)
 			// Verificar si hubo un error decodificando el cuerpo de la solicitud
 			if err != nil {
-				// Retornar un error de solicitud incorrecta
-				http.Error(w, err.Error(), http.StatusBadRequest)
+				// Retornar el error de decodificación
+				writeDecodeError(w, err)
+				return
+			}
+			// Validar los campos de la solicitud
+			if !validateRequest(w, &request) {
 				return
 			}
 	
-			_, err = repository.GetUserById(r.Context(), request.UserID)
-			// Verificar si hubo un error obteniendo el usuario
-			// Verificar si hubo un error obteniendo el usuario
-			if err != nil {
-				// Si hay un error, verifica si es porque el usuario no fue encontrado
-				if errors.Is(err, fmt.Errorf("no user found with id %s", request.UserID)) {
-					http.Error(w, "Usuario no encontrado", http.StatusNotFound)
-				} else {
-					// Si es otro error, maneja ese error
-					http.Error(w, err.Error(), http.StatusInternalServerError)
-				}
+			// Verificar que el usuario exista
+			if !checkUserExists(w, r, request.UserID) {
 				return
 			}
 			
@@ -89,12 +89,18 @@ func CreateChallengeHandler(s server.Server) http.HandlerFunc {
 				http.Error(w, err.Error(), http.StatusInternalServerError)
 				return
 			}
+			// Registrar el alta en el registro de auditoría
+			audit.Record(r, models.AUDIT_CREATE, models.ENTITY_CHALLENGE, challenge.Id, nil, &challenge)
+			metrics.CHALLENGES_CREATED.Inc()
 			// Retornar la respuesta
 			w.Header().Set("Content-Type", "application/json")
+			setETag(w, challenge.Version)
 			// Codificar la respuesta
 			json.NewEncoder(w).Encode(ChallengeResponse{
 				Id: challenge.Id, 
 				Title: challenge.Title,
+				CreatedAt: challenge.CreatedAt,
+				UpdatedAt: challenge.UpdatedAt,
 			})
 	}
 }
@@ -105,15 +111,39 @@ func UpdateChallengeHandler(s server.Server) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Decodificar el cuerpo de la solicitud
 		var request ChallengeRequest
-		err := json.NewDecoder(r.Body).Decode(&request)
+		err := decodeJSON(w, r, &request)
 		// Verificar si hubo un error decodificando el cuerpo de la solicitud
 		if err != nil {
-			// Retornar un error de solicitud incorrecta
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			// Retornar el error de decodificación
+			writeDecodeError(w, err)
+			return
+		}
+		// Validar los campos de la solicitud
+		if !validateRequest(w, &request) {
 			return
 		}
 		// Obtener el ID del usuario de los parámetros de la ruta
 		id := mux.Vars(r)["id"]
+		// Obtener el reto antes del cambio para el registro de auditoría
+		before, err := repository.GetChallengeById(r.Context(), id)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				http.Error(w, "Reto no encontrado", http.StatusNotFound)
+			} else {
+				// Si es otro error, maneja ese error
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		// Validar la versión esperada con la cabecera If-Match
+		version, ok := checkIfMatch(s, w, r, before.Version)
+		if !ok {
+			return
+		}
+		// Verificar que el cambio de dueño sea del dueño actual o de un administrador y que exista el nuevo dueño
+		if !checkOwnerChange(w, r, before.UserID, request.UserID, "Only the owner of the challenge can change its owner") {
+			return
+		}
 		// Crear una nueva estructura de usuario
 		var challenge = models.Challenge{
 			Id:       id,
@@ -121,21 +151,34 @@ func UpdateChallengeHandler(s server.Server) http.HandlerFunc {
 			Description: request.Description,
 			Difficulty: request.Difficulty,
 			UserID: request.UserID,
+			Version: version,
 		}
 		// Actualizar el usuario en la base de datos
 		err = repository.UpdateChallenge(r.Context(), id, &challenge)
 		// Verificar si hubo un error actualizando el usuario
 		if err != nil {
-			// Retornar un error interno del servidor
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			if errors.Is(err, repository.ErrVersionConflict) {
+				// El recurso cambió desde la versión indicada en If-Match
+				http.Error(w, "Precondition Failed: the resource was modified", http.StatusPreconditionFailed)
+			} else if errors.Is(err, repository.ErrNotFound) {
+				http.Error(w, "Reto no encontrado", http.StatusNotFound)
+			} else {
+				// Retornar un error interno del servidor
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
 			return
 		}
+		// Registrar el cambio en el registro de auditoría
+		audit.Record(r, models.AUDIT_UPDATE, models.ENTITY_CHALLENGE, id, before, &challenge)
 		// Retornar la respuesta
 		w.Header().Set("Content-Type", "application/json")
+		setETag(w, challenge.Version)
 		// Codificar la respuesta
 		json.NewEncoder(w).Encode(ChallengeResponse{
 			Id: challenge.Id, 
 			Title: challenge.Title,
+			CreatedAt: challenge.CreatedAt,
+			UpdatedAt: challenge.UpdatedAt,
 		})
 	}
 }
@@ -146,11 +189,23 @@ func DeleteChallengeHandler(s server.Server) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Obtener el ID del usuario de los parámetros de la ruta
 		id := mux.Vars(r)["id"]
-		// Verificar si el usuario existe en la base de datos
-		_, err := repository.GetChallengeById(r.Context(), id)
-		// Verificar si hubo un error obteniendo el usuario
+		// Obtener el reto antes de eliminarlo para el registro de auditoría
+		before, err := repository.GetChallengeById(r.Context(), id)
+		if err == nil {
+			// Validar la versión esperada con la cabecera If-Match
+			version, ok := checkIfMatch(s, w, r, before.Version)
+			if !ok {
+				return
+			}
+			// Eliminar lógicamente el reto de la base de datos
+			err = repository.DeleteChallenge(r.Context(), id, version)
+		}
+		// Verificar si hubo un error eliminando el reto
 		if err != nil {
-			if errors.Is(err, fmt.Errorf("no challenge found with id %s", id)) {
+			if errors.Is(err, repository.ErrVersionConflict) {
+				// El recurso cambió desde la versión indicada en If-Match
+				http.Error(w, "Precondition Failed: the resource was modified", http.StatusPreconditionFailed)
+			} else if errors.Is(err, repository.ErrNotFound) {
 				http.Error(w, "Reto no encontrado", http.StatusNotFound)
 			} else {
 				// Si es otro error, maneja ese error
@@ -158,16 +213,11 @@ func DeleteChallengeHandler(s server.Server) http.HandlerFunc {
 			}
 			return
 		}
-		// Eliminar el usuario de la base de datos
-		err = repository.DeleteChallenge(r.Context(), id)
-		// Verificar si hubo un error eliminando el usuario
-		if err != nil {
-			// Retornar un error interno del servidor
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
 		
 
+		// Registrar la eliminación en el registro de auditoría
+		audit.Record(r, models.AUDIT_DELETE, models.ENTITY_CHALLENGE, id, before, nil)
+
 		// Retornar la respuesta
 		w.Header().Set("Content-Type", "application/json")
 		// Codificar la respuesta
@@ -179,24 +229,27 @@ func DeleteChallengeHandler(s server.Server) http.HandlerFunc {
 // list es un controlador que maneja la obtención de una lista de usuarios.
 func ListChallengesHandler(s server.Server) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		// Obtener la página de la URL
-		page := r.URL.Query().Get("page")
-		// Convertir page a int
-		pageNum, err := strconv.Atoi(page)
+		// Obtener la página y su tamaño de la URL
+		pageNum, pageSizeNum, err := pageParams(r, DEFAULT_PAGE_SIZE)
 		if err != nil {
-			log.Print("Error converting page to int")
-			// Manejar el error
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
 		}
-		// Obtener el tamaño de la página de la URL
-		pageSize := r.URL.Query().Get("pageSize")
-		// Convertir pageSize a int
-		pageSizeNum, err := strconv.Atoi(pageSize)
+		// Obtener los niveles de dificultad solicitados
+		difficulties, err := difficultyParam(r)
 		if err != nil {
-			log.Print("Error converting pageSize to int")
-			// Manejar el error
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
 		}
+		// Obtener el formato de las descripciones
+		html, err := renderParam(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		filter := models.ChallengeFilter{Difficulties: difficulties}
 		// Obtener la lista de usuarios de la base de datos
-		challenges, total, err := repository.GetChallenges(r.Context(), pageNum, pageSizeNum)
+		challenges, total, err := repository.GetChallenges(r.Context(), filter, pageNum, pageSizeNum)
 		// Verificar si hubo un error obteniendo la lista de usuarios
 		if err != nil {
 			// Retornar un error interno del servidor
@@ -205,7 +258,7 @@ func ListChallengesHandler(s server.Server) http.HandlerFunc {
 		}
 		// Crear una estructura de respuesta
 		response := map[string]interface{} {
-			"challenges": challenges,
+			"challenges": challengeViews(challenges, html),
 			"total": total,
 		}
 		// Retornar la respuesta
@@ -221,9 +274,148 @@ func GetChallengeHandler(s server.Server) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Obtener el ID del usuario de los parámetros de la ruta
 		id := mux.Vars(r)["id"]
+		// Obtener el formato de la descripción
+		html, err := renderParam(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		// Obtener el usuario de la base de datos
 		challenge, err := repository.GetChallengeById(r.Context(), id)
 		// Verificar si hubo un error obteniendo el usuario
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				http.Error(w, "Reto no encontrado", http.StatusNotFound)
+			} else {
+				// Retornar un error interno del servidor
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		// Responder 304 si el cliente ya tiene esta versión
+		if notModified(w, r, challenge.Version) {
+			return
+		}
+		// Retornar la respuesta
+		w.Header().Set("Content-Type", "application/json")
+		setETag(w, challenge.Version)
+		// Codificar la respuesta
+		if html {
+			json.NewEncoder(w).Encode(RenderedChallenge{Challenge: challenge, DescriptionHTML: markdown.ToHTML(challenge.Description)})
+			return
+		}
+		json.NewEncoder(w).Encode(challenge)
+	}
+}
+
+// PatchChallengeHandler es el controlador que actualiza parcialmente el reto con JSON Merge Patch (RFC 7396).
+// Solo se actualizan en la base de datos las columnas que cambian.
+func PatchChallengeHandler(s server.Server) http.HandlerFunc {
+	// Retornar la función del controlador
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Obtener el ID de la URL
+		id := mux.Vars(r)["id"]
+		// Obtener el reto actual
+		before, err := repository.GetChallengeById(r.Context(), id)
+		if err != nil {
+			// Si hay un error, verifica si es porque no fue encontrado
+			if errors.Is(err, repository.ErrNotFound) {
+				http.Error(w, "Reto no encontrado", http.StatusNotFound)
+			} else {
+				// Si es otro error, maneja ese error
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		// Validar la versión esperada con la cabecera If-Match
+		version, ok := checkIfMatch(s, w, r, before.Version)
+		if !ok {
+			return
+		}
+		// Aplicar el parche sobre el reto actual
+		var challenge models.Challenge
+		fields, err := applyMergePatch(r, before, &challenge, CHALLENGE_PATCH_FIELDS)
+		if err != nil {
+			writePatchError(w, err)
+			return
+		}
+		if len(fields) > 0 {
+			// Validar el resultado combinado
+			if err := repository.ValidateChallenge(&challenge); err != nil {
+				// Responder 422 con los errores de los campos
+				if !writeValidationError(w, err) {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+				}
+				return
+			}
+			// Verificar que el cambio de dueño sea del dueño actual o de un administrador y que exista el nuevo dueño
+			if !checkOwnerChange(w, r, before.UserID, challenge.UserID, "Only the owner of the challenge can change its owner") {
+				return
+			}
+			// Actualizar solo las columnas modificadas
+			challenge.Version = version
+			err = repository.PatchChallenge(r.Context(), id, &challenge, fields)
+			if err != nil {
+				if errors.Is(err, repository.ErrVersionConflict) {
+					// El recurso cambió desde la versión indicada en If-Match
+					http.Error(w, "Precondition Failed: the resource was modified", http.StatusPreconditionFailed)
+				} else if errors.Is(err, repository.ErrNotFound) {
+					http.Error(w, "Reto no encontrado", http.StatusNotFound)
+				} else {
+					// Retornar un error interno del servidor
+					http.Error(w, err.Error(), http.StatusInternalServerError)
+				}
+				return
+			}
+			// Registrar el cambio en el registro de auditoría
+			audit.Record(r, models.AUDIT_UPDATE, models.ENTITY_CHALLENGE, id, before, &challenge)
+		} else {
+			// Sin cambios no se toca la base de datos
+			challenge = *before
+		}
+		// Retornar la respuesta
+		w.Header().Set("Content-Type", "application/json")
+		setETag(w, challenge.Version)
+		// Codificar el reto actualizado
+		json.NewEncoder(w).Encode(challenge)
+	}
+}
+
+// ListUserChallengesHandler es el controlador que obtiene los retos de un usuario.
+func ListUserChallengesHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Obtener el ID del usuario de los parámetros de la ruta
+		id := mux.Vars(r)["id"]
+		// Obtener la página y su tamaño
+		page, pageSize, err := pageParams(r, DEFAULT_PAGE_SIZE)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		// Obtener los niveles de dificultad solicitados
+		difficulties, err := difficultyParam(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		// Obtener el formato de las descripciones
+		html, err := renderParam(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		// Verificar que el usuario exista
+		if _, err := repository.GetUserById(r.Context(), id); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				http.Error(w, "Usuario no encontrado", http.StatusNotFound)
+			} else {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		// Obtener los retos del usuario
+		filter := models.ChallengeFilter{UserID: id, Difficulties: difficulties}
+		challenges, total, err := repository.GetChallenges(r.Context(), filter, page, pageSize)
 		if err != nil {
 			// Retornar un error interno del servidor
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -232,6 +424,120 @@ func GetChallengeHandler(s server.Server) http.HandlerFunc {
 		// Retornar la respuesta
 		w.Header().Set("Content-Type", "application/json")
 		// Codificar la respuesta
-		json.NewEncoder(w).Encode(challenge)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"challenges": challengeViews(challenges, html),
+			"total":      total,
+		})
 	}
-}
\ No newline at end of file
+}
+
+
+//*****************************************************************************************************
+// Dificultad
+
+// DifficultiesResponse es la respuesta con la escala de dificultad de los retos.
+type DifficultiesResponse struct {
+	Difficulties []models.Difficulty `json:"difficulties"`
+}
+
+// ChallengeStatsResponse es la respuesta con la cantidad de retos por nivel de dificultad.
+type ChallengeStatsResponse struct {
+	Stats []models.DifficultyStats `json:"stats"`
+	Total int                      `json:"total"`
+}
+
+// difficultyParam es una función que obtiene los niveles de dificultad del parámetro difficulty.
+// Acepta valores repetidos o separados por comas, como números (2) o nombres (easy).
+func difficultyParam(r *http.Request) ([]int, error) {
+	var levels []int
+	for _, value := range r.URL.Query()["difficulty"] {
+		for _, item := range strings.Split(value, ",") {
+			// Ignorar los valores vacíos
+			item = strings.TrimSpace(item)
+			if item == "" {
+				continue
+			}
+			level, ok := models.ParseDifficulty(item)
+			if !ok {
+				return nil, fmt.Errorf("Invalid difficulty %q", item)
+			}
+			levels = append(levels, level)
+		}
+	}
+	return levels, nil
+}
+
+// ListDifficultiesHandler es el controlador que devuelve la escala de dificultad de los retos.
+func ListDifficultiesHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Retornar la respuesta
+		w.Header().Set("Content-Type", "application/json")
+		// Codificar la respuesta
+		json.NewEncoder(w).Encode(DifficultiesResponse{Difficulties: models.DIFFICULTIES})
+	}
+}
+
+// ChallengeStatsHandler es el controlador que devuelve la cantidad de retos por nivel de dificultad.
+// Acepta los parámetros user_id y difficulty para restringir el conteo.
+func ChallengeStatsHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Obtener los niveles de dificultad solicitados
+		difficulties, err := difficultyParam(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		filter := models.ChallengeFilter{UserID: r.URL.Query().Get("user_id"), Difficulties: difficulties}
+		// Contar los retos por nivel
+		counts, err := repository.GetChallengeStats(r.Context(), filter)
+		if err != nil {
+			// Retornar un error interno del servidor
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		// Incluir todos los niveles de la escala, aunque no tengan retos
+		response := ChallengeStatsResponse{Stats: []models.DifficultyStats{}}
+		for _, difficulty := range models.DIFFICULTIES {
+			response.Stats = append(response.Stats, models.DifficultyStats{Difficulty: difficulty, Count: counts[difficulty.Level]})
+			response.Total += counts[difficulty.Level]
+		}
+		// Retornar la respuesta
+		w.Header().Set("Content-Type", "application/json")
+		// Codificar la respuesta
+		json.NewEncoder(w).Encode(response)
+	}
+}
+
+
+//*****************************************************************************************************
+// Markdown
+
+// RenderedChallenge es un reto con su descripción en markdown convertida a HTML seguro, como se devuelve con ?render=html.
+type RenderedChallenge struct {
+	*models.Challenge
+	DescriptionHTML string `json:"description_html"`
+}
+
+// renderParam es una función que indica si la solicitud pide las descripciones en HTML con ?render=html.
+func renderParam(r *http.Request) (bool, error) {
+	switch render := r.URL.Query().Get("render"); render {
+	case "":
+		return false, nil
+	case "html":
+		return true, nil
+	default:
+		return false, fmt.Errorf("Invalid render %q: only \"html\" is supported", render)
+	}
+}
+
+// challengeViews es una función que devuelve los retos tal cual o, si html es true, con sus descripciones en HTML.
+func challengeViews(challenges []*models.Challenge, html bool) interface{} {
+	if !html {
+		return challenges
+	}
+	views := make([]RenderedChallenge, 0, len(challenges))
+	for _, challenge := range challenges {
+		views = append(views, RenderedChallenge{Challenge: challenge, DescriptionHTML: markdown.ToHTML(challenge.Description)})
+	}
+	return views
+}
@@ -3,16 +3,18 @@ package handlers
 
 import (
 	"encoding/json"
-	"log"
-    "strconv"
 	"net/http"
 
 	"errors"
-	"fmt"
+	"time"
 
+	"talentpitchGo/audit"      // registro de auditoría
+	"talentpitchGo/logging"    // logger de la solicitud
+	"talentpitchGo/metrics"    // métricas de Prometheus
 	"talentpitchGo/models"     // modelos de datos
 	"talentpitchGo/repository" // operaciones de base de datos
 	"talentpitchGo/server"     // configuración del servidor
+	"talentpitchGo/validation" // errores de validación de los campos
     "github.com/gorilla/mux" // enrutador HTTP
 	"github.com/segmentio/ksuid" // para generar IDs únicos
 )
@@ -20,17 +22,22 @@ import (
 // CompanyRequest es una estructura que representa la solicitud de creación de una empresa.
 type CompanyRequest  struct {
 	Id          string `json:"id"`
-	Name        string `json:"name"`
-	ImagePath   string `json:"image_path"`
-	Location    string `json:"location"`
-	Industry    string `json:"industry"`
-	UserID      string `json:"user_id"`
+	Name        string `json:"name" validate:"required,max=255"`
+	Location    string `json:"location" validate:"required,max=255"`
+	Industry    string `json:"industry" validate:"required,max=255"`
+	UserID      string `json:"user_id" validate:"required"`
+	ProgramID   string `json:"program_id"` // Programa en el que se inscribe la empresa al crearla (opcional)
 }
 
+// errProgramNotFound es el error de la unidad de trabajo cuando el programa de la empresa no existe
+var errProgramNotFound = errors.New("program not found")
+
 // CompanyResponse es una estructura que representa la respuesta de creación de una empresa.
 type CompanyResponse struct {
 	Id          string `json:"id"`
 	Name        string `json:"name"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
 }
 
 // CreateCompanyHandler es un controlador que maneja la creación de una nueva empresa.
@@ -41,27 +48,22 @@ func CreateCompanyHandler(s server.Server) http.HandlerFunc {
 		var request CompanyRequest
 		
 		// Decodificar el cuerpo de la solicitud
-		err := json.NewDecoder(r.Body).Decode(&request)
+		err := decodeJSON(w, r, &request)
 		// Verificar si hubo un error decodificando el cuerpo de la solicitud
 		if err != nil {
 			// Loggear el error
-			log.Printf("Error decoding request: %v", err)
-			// Retornar un error de solicitud incorrecta
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			logging.FromContext(r.Context()).Warn("error decoding company request", "error", err)
+			// Retornar el error de decodificación
+			writeDecodeError(w, err)
+			return
+		}
+		// Validar los campos de la solicitud
+		if !validateRequest(w, &request) {
 			return
 		}
 
-		_, err = repository.GetUserById(r.Context(), request.UserID)
-		// Verificar si hubo un error obteniendo el usuario
-		// Verificar si hubo un error obteniendo el usuario
-		if err != nil {
-			// Si hay un error, verifica si es porque el usuario no fue encontrado
-			if errors.Is(err, fmt.Errorf("no user found with id %s", request.UserID)) {
-				http.Error(w, "Usuario no encontrado", http.StatusNotFound)
-			} else {
-				// Si hay un error diferente, retornar un error interno del servidor
-				http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
-			}
+		// Verificar que el usuario exista
+		if !checkUserExists(w, r, request.UserID) {
 			return
 		}
 
@@ -69,30 +71,64 @@ func CreateCompanyHandler(s server.Server) http.HandlerFunc {
 		company := models.Company{
 			Id:        ksuid.New().String(),
 			Name:      request.Name,
-			ImagePath: request.ImagePath,
 			Location:  request.Location,
 			Industry:  request.Industry,
 			UserID:    request.UserID,
 		}
 
-		// Guardar la empresa en la base de datos
-		err = repository.InsertCompany(r.Context(), &company)
+		// Verificar los campos de la empresa antes de abrir la transacción
+		if err := repository.ValidateCompany(&company); err != nil {
+			// Responder 422 con los errores de los campos
+			if !writeValidationError(w, err) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			}
+			return
+		}
+		// Guardar la empresa e inscribirla en el programa indicado en la misma transacción
+		err = repository.WithTx(r.Context(), func(repos repository.Repositories) error {
+			if request.ProgramID != "" {
+				if _, err := repos.GetProgramById(r.Context(), request.ProgramID); errors.Is(err, repository.ErrNotFound) {
+					return errProgramNotFound
+				} else if err != nil {
+					return err
+				}
+			}
+			if err := repos.InsertCompany(r.Context(), &company); err != nil || request.ProgramID == "" {
+				return err
+			}
+			return repos.InsertProgramParticipant(r.Context(), &models.ProgramParticipant{
+				Id:         ksuid.New().String(),
+				ProgramID:  request.ProgramID,
+				EntityType: models.ENTITY_COMPANY,
+				EntityID:   company.Id,
+			})
+		})
+		if errors.Is(err, errProgramNotFound) {
+			writeValidationError(w, validation.Errors{{Field: "program_id", Code: validation.CODE_NOT_FOUND, Message: "program not found"}})
+			return
+		}
 		// Verificar si hubo un error guardando la empresa en la base de datos
 		if err != nil {
 			// Loggear el error
-			log.Printf("Error inserting company: %v", err)
+			logging.FromContext(r.Context()).Error("error inserting company", "company_id", company.Id, "error", err)
 			// Retornar un error interno del servidor
 			http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
 			return
 		}
+		// Registrar el alta en el registro de auditoría
+		audit.Record(r, models.AUDIT_CREATE, models.ENTITY_COMPANY, company.Id, nil, &company)
+		metrics.COMPANIES_CREATED.Inc()
 
 		
 		// Retornar la respuesta
 		w.Header().Set("Content-Type", "application/json")
+		setETag(w, company.Version)
 		// Codificar la respuesta
 		json.NewEncoder(w).Encode(CompanyResponse{
 			Id: company.Id, 
 			Name: company.Name,
+			CreatedAt: company.CreatedAt,
+			UpdatedAt: company.UpdatedAt,
 		})
 	}
 }
@@ -115,7 +151,7 @@ func GetCompanyHandler(s server.Server) http.HandlerFunc {
 		// Verificar si hubo un error obteniendo la empresa
 		if err != nil {
 			// Si hay un error, verifica si es porque la empresa no fue encontrada
-			if errors.Is(err, fmt.Errorf("no company found with id %s", id)) {
+			if errors.Is(err, repository.ErrNotFound) {
 				http.Error(w, "Empresa no encontrada", http.StatusNotFound)
 			} else {
 				// Si hay un error diferente, retornar un error interno del servidor
@@ -124,8 +160,14 @@ func GetCompanyHandler(s server.Server) http.HandlerFunc {
 			return
 		}
 
+		// Responder 304 si el cliente ya tiene esta versión
+		if notModified(w, r, company.Version) {
+			return
+		}
+
 		// Retornar la respuesta
 		w.Header().Set("Content-Type", "application/json")
+		setETag(w, company.Version)
 		// Codificar la respuesta
 		json.NewEncoder(w).Encode(company)
 	}
@@ -138,13 +180,17 @@ func UpdateCompanyHandler(s server.Server) http.HandlerFunc {
 		// Decodificar el cuerpo de la solicitud
 		var request CompanyRequest
 		// Decodificar el cuerpo de la solicitud
-		err := json.NewDecoder(r.Body).Decode(&request)
+		err := decodeJSON(w, r, &request)
 		// Verificar si hubo un error decodificando el cuerpo de la solicitud
 		if err != nil {
 			// Loggear el error
-			log.Printf("Error decoding request: %v", err)
-			// Retornar un error de solicitud incorrecta
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			logging.FromContext(r.Context()).Warn("error decoding company request", "error", err)
+			// Retornar el error de decodificación
+			writeDecodeError(w, err)
+			return
+		}
+		// Validar los campos de la solicitud
+		if !validateRequest(w, &request) {
 			return
 		}
 		// Obtener el ID del usuario de la URL
@@ -155,27 +201,36 @@ func UpdateCompanyHandler(s server.Server) http.HandlerFunc {
 		// Verificar si hubo un error obteniendo la empresa
 		if err != nil {
 			// Si hay un error, verifica si es porque la empresa no fue encontrada
-			if errors.Is(err, fmt.Errorf("no company found with id %s", request.Id)) {
-				log.Printf("Company not found: %v", err)
+			if errors.Is(err, repository.ErrNotFound) {
+				logging.FromContext(r.Context()).Info("company not found", "company_id", id)
 				http.Error(w, "Empresa no encontrada", http.StatusNotFound)
 			} else {
-				log.Printf("Error getting company: %v", err)
+				logging.FromContext(r.Context()).Error("error getting company", "company_id", id, "error", err)
 				// Si hay un error diferente, retornar un error interno del servidor
 				http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
 			}
 			return
 		}
 
-		
+		// Validar la versión esperada con la cabecera If-Match
+		version, ok := checkIfMatch(s, w, r, company.Version)
+		if !ok {
+			return
+		}
+		// Verificar que el cambio de dueño sea del dueño actual o de un administrador y que exista el nuevo dueño
+		if !checkOwnerChange(w, r, company.UserID, request.UserID, "Only the owner of the company can change its owner") {
+			return
+		}
 
 		// Crear una nueva estructura de empresa con los datos actualizados
 		companyReq := models.Company{
 			Id:        request.Id,
 			Name:      request.Name,
-			ImagePath: request.ImagePath,
+			ImagePath: company.ImagePath, // El logo solo se cambia con UploadCompanyLogoHandler
 			Location:  request.Location,
 			Industry:  request.Industry,
 			UserID:    request.UserID,
+			Version:   version,
 		}
 
 		
@@ -184,16 +239,28 @@ func UpdateCompanyHandler(s server.Server) http.HandlerFunc {
 		// Verificar si hubo un error guardando la empresa en la base de datos
 		if err != nil {
 			// Loggear el error
-			log.Printf("Error updating company: %v", err)
-			// Retornar un error interno del servidor
-			http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
+			logging.FromContext(r.Context()).Error("error updating company", "company_id", id, "error", err)
+			if errors.Is(err, repository.ErrVersionConflict) {
+				// El recurso cambió desde la versión indicada en If-Match
+				http.Error(w, "Precondition Failed: the resource was modified", http.StatusPreconditionFailed)
+			} else if errors.Is(err, repository.ErrNotFound) {
+				http.Error(w, "Empresa no encontrada", http.StatusNotFound)
+			} else {
+				// Retornar un error interno del servidor
+				http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
+			}
 			return
 		}
+		// Registrar el cambio en el registro de auditoría
+		after := companyReq
+		after.Id = id
+		audit.Record(r, models.AUDIT_UPDATE, models.ENTITY_COMPANY, id, company, &after)
 
 		// Retornar la respuesta
 		w.Header().Set("Content-Type", "application/json")
-		// Codificar la respuesta
-		json.NewEncoder(w).Encode(company)
+		setETag(w, after.Version)
+		// Codificar la empresa actualizada
+		json.NewEncoder(w).Encode(after)
 	}
 }
 
@@ -210,12 +277,23 @@ func DeleteCompanyHandler(s server.Server) http.HandlerFunc {
 			return
 		}
 
-		// Eliminar la empresa por su ID
-		err := repository.DeleteCompany(r.Context(), id)
+		// Obtener la empresa antes de eliminarla para el registro de auditoría
+		before, err := repository.GetCompanyById(r.Context(), id)
+		if err == nil {
+			// Validar la versión esperada con la cabecera If-Match
+			version, ok := checkIfMatch(s, w, r, before.Version)
+			if !ok {
+				return
+			}
+			// Eliminar lógicamente la empresa por su ID
+			err = repository.DeleteCompany(r.Context(), id, version)
+		}
 		// Verificar si hubo un error eliminando la empresa
 		if err != nil {
-			// Si hay un error, verifica si es porque la empresa no fue encontrada
-			if errors.Is(err, fmt.Errorf("no company found with id %s", id)) {
+			if errors.Is(err, repository.ErrVersionConflict) {
+				// El recurso cambió desde la versión indicada en If-Match
+				http.Error(w, "Precondition Failed: the resource was modified", http.StatusPreconditionFailed)
+			} else if errors.Is(err, repository.ErrNotFound) {
 				http.Error(w, "Empresa no encontrada", http.StatusNotFound)
 			} else {
 				// Si hay un error diferente, retornar un error interno del servidor
@@ -224,6 +302,9 @@ func DeleteCompanyHandler(s server.Server) http.HandlerFunc {
 			return
 		}
 
+		// Registrar la eliminación en el registro de auditoría
+		audit.Record(r, models.AUDIT_DELETE, models.ENTITY_COMPANY, id, before, nil)
+
 		// Retornar la respuesta
 		w.Header().Set("Content-Type", "application/json")
 		// Codificar la respuesta
@@ -236,21 +317,11 @@ func DeleteCompanyHandler(s server.Server) http.HandlerFunc {
 func ListCompaniesHandler(s server.Server) http.HandlerFunc {
 // Retornar la función del controlador
 return func(w http.ResponseWriter, r *http.Request) {
-	// Obtener la página de la URL
-	page := r.URL.Query().Get("page")
-	// Convertir page a int
-	pageNum, err := strconv.Atoi(page)
+	// Obtener la página y su tamaño de la URL
+	pageNum, pageSizeNum, err := pageParams(r, DEFAULT_PAGE_SIZE)
 	if err != nil {
-		log.Print("Error converting page to int")
-		// Manejar el error
-	}
-	// Obtener el tamaño de la página de la URL
-	pageSize := r.URL.Query().Get("pageSize")
-	// Convertir pageSize a int
-	pageSizeNum, err := strconv.Atoi(pageSize)
-	if err != nil {
-		log.Print("Error converting pageSize to int")
-		// Manejar el error
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 	// Obtener la lista de usuarios
 	companies, total, err := repository.GetCompanies(r.Context(), pageNum, pageSizeNum )
@@ -270,4 +341,166 @@ return func(w http.ResponseWriter, r *http.Request) {
 	// Codificar la respuesta
 	json.NewEncoder(w).Encode(response)
 }
-}
\ No newline at end of file
+}
+
+// PatchCompanyHandler es el controlador que actualiza parcialmente la empresa con JSON Merge Patch (RFC 7396).
+// Solo se actualizan en la base de datos las columnas que cambian.
+func PatchCompanyHandler(s server.Server) http.HandlerFunc {
+	// Retornar la función del controlador
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Obtener el ID de la URL
+		id := mux.Vars(r)["id"]
+		// Obtener la empresa actual
+		before, err := repository.GetCompanyById(r.Context(), id)
+		if err != nil {
+			// Si hay un error, verifica si es porque no fue encontrado
+			if errors.Is(err, repository.ErrNotFound) {
+				http.Error(w, "Empresa no encontrada", http.StatusNotFound)
+			} else {
+				// Si es otro error, maneja ese error
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		// Validar la versión esperada con la cabecera If-Match
+		version, ok := checkIfMatch(s, w, r, before.Version)
+		if !ok {
+			return
+		}
+		// Aplicar el parche sobre la empresa actual
+		var company models.Company
+		fields, err := applyMergePatch(r, before, &company, COMPANY_PATCH_FIELDS)
+		if err != nil {
+			writePatchError(w, err)
+			return
+		}
+		if len(fields) > 0 {
+			// Validar el resultado combinado
+			if err := repository.ValidateCompany(&company); err != nil {
+				// Responder 422 con los errores de los campos
+				if !writeValidationError(w, err) {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+				}
+				return
+			}
+			// Verificar que el cambio de dueño sea del dueño actual o de un administrador y que exista el nuevo dueño
+			if !checkOwnerChange(w, r, before.UserID, company.UserID, "Only the owner of the company can change its owner") {
+				return
+			}
+			// Actualizar solo las columnas modificadas
+			company.Version = version
+			err = repository.PatchCompany(r.Context(), id, &company, fields)
+			if err != nil {
+				if errors.Is(err, repository.ErrVersionConflict) {
+					// El recurso cambió desde la versión indicada en If-Match
+					http.Error(w, "Precondition Failed: the resource was modified", http.StatusPreconditionFailed)
+				} else if errors.Is(err, repository.ErrNotFound) {
+					http.Error(w, "Empresa no encontrada", http.StatusNotFound)
+				} else {
+					// Retornar un error interno del servidor
+					http.Error(w, err.Error(), http.StatusInternalServerError)
+				}
+				return
+			}
+			// Registrar el cambio en el registro de auditoría
+			audit.Record(r, models.AUDIT_UPDATE, models.ENTITY_COMPANY, id, before, &company)
+		} else {
+			// Sin cambios no se toca la base de datos
+			company = *before
+		}
+		// Retornar la respuesta
+		w.Header().Set("Content-Type", "application/json")
+		setETag(w, company.Version)
+		// Codificar la empresa actualizada
+		json.NewEncoder(w).Encode(company)
+	}
+}
+
+// ListUserCompaniesHandler es el controlador que obtiene las empresas de un usuario.
+func ListUserCompaniesHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Obtener el ID del usuario de los parámetros de la ruta
+		id := mux.Vars(r)["id"]
+		// Obtener la página y su tamaño
+		page, pageSize, err := pageParams(r, DEFAULT_PAGE_SIZE)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		// Verificar que el usuario exista
+		if _, err := repository.GetUserById(r.Context(), id); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				http.Error(w, "Usuario no encontrado", http.StatusNotFound)
+			} else {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		// Obtener las empresas del usuario
+		companies, total, err := repository.GetCompaniesByUser(r.Context(), id, page, pageSize)
+		if err != nil {
+			// Retornar un error interno del servidor
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		// Retornar la respuesta
+		w.Header().Set("Content-Type", "application/json")
+		// Codificar la respuesta
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"companies": companies,
+			"total":     total,
+		})
+	}
+}
+
+// ListCompanyChallengesHandler es el controlador que obtiene los retos de una empresa,
+// es decir, los retos publicados por el usuario dueño de la empresa.
+func ListCompanyChallengesHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Obtener el ID de la empresa de los parámetros de la ruta
+		id := mux.Vars(r)["id"]
+		// Obtener la página y su tamaño
+		page, pageSize, err := pageParams(r, DEFAULT_PAGE_SIZE)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		// Obtener los niveles de dificultad solicitados
+		difficulties, err := difficultyParam(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		// Obtener el formato de las descripciones
+		html, err := renderParam(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		// Obtener la empresa
+		company, err := repository.GetCompanyById(r.Context(), id)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				http.Error(w, "Empresa no encontrada", http.StatusNotFound)
+			} else {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		// Obtener los retos del dueño de la empresa
+		filter := models.ChallengeFilter{UserID: company.UserID, Difficulties: difficulties}
+		challenges, total, err := repository.GetChallenges(r.Context(), filter, page, pageSize)
+		if err != nil {
+			// Retornar un error interno del servidor
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		// Retornar la respuesta
+		w.Header().Set("Content-Type", "application/json")
+		// Codificar la respuesta
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"challenges": challengeViews(challenges, html),
+			"total":      total,
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// MAX_JSON_BODY_SIZE es el tamaño máximo del cuerpo JSON de una solicitud: 1 MB
+const MAX_JSON_BODY_SIZE = 1 << 20
+
+// errBodyTooLarge es el error que se devuelve cuando el cuerpo de la solicitud supera MAX_JSON_BODY_SIZE
+var errBodyTooLarge = errors.New("request body too large: the maximum size is 1 MB")
+
+// decodeJSON es una función que decodifica el cuerpo JSON de la solicitud en dst de forma estricta:
+// limita su tamaño a MAX_JSON_BODY_SIZE y rechaza los campos desconocidos y los datos después del objeto.
+func decodeJSON(w http.ResponseWriter, r *http.Request, dst interface{}) error {
+	r.Body = http.MaxBytesReader(w, r.Body, MAX_JSON_BODY_SIZE)
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(dst)
+	if err == nil {
+		// Solo se acepta un valor JSON en el cuerpo
+		if decoder.Decode(&struct{}{}) != io.EOF {
+			err = errors.New("request body must contain a single JSON value")
+		}
+	}
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return errBodyTooLarge
+	}
+	return err
+}
+
+// writeDecodeError es una función que responde con el error de decodificar el cuerpo de la solicitud:
+// 413 si es demasiado grande y 400 en los demás casos
+func writeDecodeError(w http.ResponseWriter, err error) {
+	if errors.Is(err, errBodyTooLarge) {
+		// Retornar un error de cuerpo demasiado grande
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+	// Retornar un error de solicitud incorrecta
+	http.Error(w, err.Error(), http.StatusBadRequest)
+}
@@ -0,0 +1,72 @@
+// El paquete handlers contiene los controladores de las rutas HTTP.
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"talentpitchGo/server" // configuración del servidor
+)
+
+// etag es una función que construye la ETag de un recurso a partir de su versión
+func etag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// setETag es una función que agrega la cabecera ETag con la versión del recurso
+func setETag(w http.ResponseWriter, version int) {
+	w.Header().Set("ETag", etag(version))
+}
+
+// matchETag es una función que indica si una lista de ETags de las cabeceras If-Match o If-None-Match
+// contiene la ETag del recurso. Con weak se aceptan también las ETags débiles (W/"...").
+func matchETag(header string, version int, weak bool) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return true
+		}
+		if weak {
+			tag = strings.TrimPrefix(tag, "W/")
+		}
+		if tag == etag(version) {
+			return true
+		}
+	}
+	return false
+}
+
+// notModified es una función que responde 304 Not Modified si la cabecera If-None-Match
+// contiene la ETag del recurso, y devuelve verdadero en ese caso.
+func notModified(w http.ResponseWriter, r *http.Request, version int) bool {
+	header := r.Header.Get("If-None-Match")
+	if header == "" || !matchETag(header, version, true) {
+		return false
+	}
+	setETag(w, version)
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// checkIfMatch es una función que valida la cabecera If-Match de una modificación contra la versión actual
+// del recurso y devuelve la versión que se debe exigir al repositorio (0 si no se exige ninguna).
+// Si la precondición no se cumple responde con el error correspondiente y devuelve falso.
+func checkIfMatch(s server.Server, w http.ResponseWriter, r *http.Request, version int) (int, bool) {
+	header := r.Header.Get("If-Match")
+	if header == "" {
+		// Sin If-Match solo se permite la modificación si la configuración no lo exige
+		if s.Config().RequireIfMatch {
+			http.Error(w, "If-Match header required", http.StatusPreconditionRequired)
+			return 0, false
+		}
+		return 0, true
+	}
+	// If-Match usa la comparación fuerte de ETags
+	if !matchETag(header, version, false) {
+		http.Error(w, "Precondition Failed: the resource was modified", http.StatusPreconditionFailed)
+		return 0, false
+	}
+	// Exigir la versión al repositorio por si el recurso cambia antes de guardarlo
+	return version, true
+}
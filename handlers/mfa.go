@@ -0,0 +1,367 @@
+// El paquete handlers contiene los controladores de las rutas HTTP.
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"talentpitchGo/metrics"    // métricas de Prometheus
+	"talentpitchGo/mfa"        // utilidades TOTP y códigos de recuperación
+	"talentpitchGo/models"     // modelos de datos
+	"talentpitchGo/repository" // operaciones de base de datos
+	"talentpitchGo/server"     // configuración del servidor
+
+	"github.com/segmentio/ksuid" // para generar IDs únicos
+	"golang.org/x/crypto/bcrypt" // para hashear los códigos de recuperación
+)
+
+// MFA_ISSUER es el emisor que muestran las aplicaciones autenticadoras.
+const (
+	MFA_ISSUER = "TalentPitch"
+)
+
+// MFACodeRequest es la estructura de los datos necesarios para verificar o desactivar el 2FA.
+type MFACodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// MFALoginRequest es la estructura de los datos necesarios para completar un inicio de sesión con 2FA.
+type MFALoginRequest struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+// TOTPEnrollResponse es la estructura de la respuesta del registro de TOTP.
+type TOTPEnrollResponse struct {
+	Secret     string `json:"secret"`
+	OtpauthURI string `json:"otpauth_uri"`
+}
+
+// RecoveryCodesResponse es la estructura de la respuesta con los códigos de recuperación, que solo se muestran una vez.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// EnrollTOTPHandler es el controlador que genera un secreto TOTP pendiente de verificación para el usuario autenticado
+func EnrollTOTPHandler(s server.Server) http.HandlerFunc {
+	// Retornar la función del controlador
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Obtener los claims del token
+		claims, err := requestClaims(s, r)
+		if err != nil {
+			// Retornar un error de no autorizado
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		// Obtener la configuración 2FA actual
+		mfaConfig, err := repository.GetUserMFA(r.Context(), claims.UserId)
+		if err != nil {
+			// Retornar un error interno del servidor
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		// Verificar que el 2FA no esté activo
+		if mfaConfig != nil && mfaConfig.Enabled {
+			http.Error(w, "MFA already enabled", http.StatusConflict)
+			return
+		}
+		// Obtener el usuario para construir la etiqueta de la URI
+		user, err := repository.GetUserById(r.Context(), claims.UserId)
+		if err != nil {
+			// Retornar un error interno del servidor
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		// Generar un nuevo secreto
+		secret, err := mfa.GenerateSecret()
+		if err != nil {
+			// Retornar un error interno del servidor
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		// Guardar el secreto pendiente de verificación
+		err = repository.SaveUserMFASecret(r.Context(), claims.UserId, secret)
+		if err != nil {
+			// Retornar un error interno del servidor
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		// Retornar la respuesta
+		w.Header().Set("Content-Type", "application/json")
+		// Codificar la respuesta
+		json.NewEncoder(w).Encode(TOTPEnrollResponse{
+			Secret:     secret,
+			OtpauthURI: mfa.URI(MFA_ISSUER, user.Email, secret),
+		})
+	}
+}
+
+// VerifyTOTPHandler es el controlador que verifica el primer código TOTP, activa el 2FA y genera los códigos de recuperación
+func VerifyTOTPHandler(s server.Server) http.HandlerFunc {
+	// Retornar la función del controlador
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Obtener los claims del token
+		claims, err := requestClaims(s, r)
+		if err != nil {
+			// Retornar un error de no autorizado
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		// Decodificar el cuerpo de la solicitud
+		var request MFACodeRequest
+		err = decodeJSON(w, r, &request)
+		if err != nil {
+			// Retornar el error de decodificación
+			writeDecodeError(w, err)
+			return
+		}
+		// Validar los campos de la solicitud
+		if !validateRequest(w, &request) {
+			return
+		}
+		// Obtener la configuración 2FA pendiente
+		mfaConfig, err := repository.GetUserMFA(r.Context(), claims.UserId)
+		if err != nil {
+			// Retornar un error interno del servidor
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		// Verificar que exista un registro pendiente
+		if mfaConfig == nil {
+			http.Error(w, "MFA enrollment not started", http.StatusBadRequest)
+			return
+		}
+		if mfaConfig.Enabled {
+			http.Error(w, "MFA already enabled", http.StatusConflict)
+			return
+		}
+		// Verificar el código TOTP, que ya no se podrá usar para iniciar sesión
+		ok, err := verifyTOTPCode(r.Context(), mfaConfig, request.Code)
+		if err != nil {
+			// Retornar un error interno del servidor
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if !ok {
+			http.Error(w, "Invalid code", http.StatusUnauthorized)
+			return
+		}
+		// Generar los códigos de recuperación
+		plainCodes, codes, err := newRecoveryCodes(claims.UserId)
+		if err != nil {
+			// Retornar un error interno del servidor
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		// Activar el 2FA y guardar los hashes de los códigos
+		err = repository.EnableUserMFA(r.Context(), claims.UserId, codes)
+		if err != nil {
+			// Retornar un error interno del servidor
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		// Retornar la respuesta
+		w.Header().Set("Content-Type", "application/json")
+		// Codificar la respuesta
+		json.NewEncoder(w).Encode(RecoveryCodesResponse{
+			RecoveryCodes: plainCodes,
+		})
+	}
+}
+
+// DisableTOTPHandler es el controlador que desactiva el 2FA del usuario autenticado con un código TOTP o de recuperación
+func DisableTOTPHandler(s server.Server) http.HandlerFunc {
+	// Retornar la función del controlador
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Obtener los claims del token
+		claims, err := requestClaims(s, r)
+		if err != nil {
+			// Retornar un error de no autorizado
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		// Decodificar el cuerpo de la solicitud
+		var request MFACodeRequest
+		err = decodeJSON(w, r, &request)
+		if err != nil {
+			// Retornar el error de decodificación
+			writeDecodeError(w, err)
+			return
+		}
+		// Validar los campos de la solicitud
+		if !validateRequest(w, &request) {
+			return
+		}
+		// Obtener la configuración 2FA
+		mfaConfig, err := repository.GetUserMFA(r.Context(), claims.UserId)
+		if err != nil {
+			// Retornar un error interno del servidor
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		// Verificar que el 2FA esté activo
+		if mfaConfig == nil || !mfaConfig.Enabled {
+			http.Error(w, "MFA not enabled", http.StatusBadRequest)
+			return
+		}
+		// Verificar el código
+		ok, err := verifyMFACode(r.Context(), mfaConfig, request.Code)
+		if err != nil {
+			// Retornar un error interno del servidor
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if !ok {
+			http.Error(w, "Invalid code", http.StatusUnauthorized)
+			return
+		}
+		// Desactivar el 2FA
+		err = repository.DisableUserMFA(r.Context(), claims.UserId)
+		if err != nil {
+			// Retornar un error interno del servidor
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		// Retornar la respuesta
+		w.Header().Set("Content-Type", "application/json")
+		// Codificar la respuesta
+		json.NewEncoder(w).Encode(map[string]string{"message": "MFA disabled"})
+	}
+}
+
+// LoginMFAHandler es el controlador que intercambia un token de desafío 2FA y un código por el token de acceso
+func LoginMFAHandler(s server.Server) http.HandlerFunc {
+	// Retornar la función del controlador
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Decodificar el cuerpo de la solicitud
+		var request MFALoginRequest
+		err := decodeJSON(w, r, &request)
+		if err != nil {
+			// Retornar el error de decodificación
+			writeDecodeError(w, err)
+			return
+		}
+		// Validar los campos de la solicitud
+		if !validateRequest(w, &request) {
+			return
+		}
+		// Validar el token de desafío
+		claims, err := parseToken(s, request.MFAToken)
+		if err != nil || !claims.MFAPending {
+			// Retornar un error de no autorizado
+			http.Error(w, "Invalid MFA token", http.StatusUnauthorized)
+			return
+		}
+		// Obtener la configuración 2FA del usuario
+		mfaConfig, err := repository.GetUserMFA(r.Context(), claims.UserId)
+		if err != nil {
+			// Retornar un error interno del servidor
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if mfaConfig == nil || !mfaConfig.Enabled {
+			http.Error(w, "Invalid MFA token", http.StatusUnauthorized)
+			return
+		}
+		// Verificar el código TOTP o de recuperación
+		ok, err := verifyMFACode(r.Context(), mfaConfig, request.Code)
+		if err != nil {
+			// Retornar un error interno del servidor
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if !ok {
+			http.Error(w, "Invalid code", http.StatusUnauthorized)
+			return
+		}
+		// Firmar el token de acceso
+		tokenString, err := signToken(s, claims.UserId, TOKEN_TTL, false)
+		if err != nil {
+			// Retornar un error interno del servidor
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		// Contar el inicio de sesión
+		metrics.LOGINS.WithLabelValues(metrics.LOGIN_MFA).Inc()
+		// Retornar la respuesta
+		w.Header().Set("Content-Type", "application/json")
+		// Codificar la respuesta
+		json.NewEncoder(w).Encode(LoginResponse{
+			Token: tokenString,
+		})
+	}
+}
+
+// verifyMFACode es una función que verifica un código TOTP o, si tiene el formato correspondiente,
+// un código de recuperación, marcándolo como usado. Un código ya usado no es válido.
+func verifyMFACode(ctx context.Context, mfaConfig *models.UserMFA, code string) (bool, error) {
+	// Verificar un código TOTP
+	if !mfa.IsRecoveryCode(code) {
+		return verifyTOTPCode(ctx, mfaConfig, code)
+	}
+	// Obtener los códigos de recuperación sin usar
+	codes, err := repository.GetRecoveryCodes(ctx, mfaConfig.UserID)
+	if err != nil {
+		return false, err
+	}
+	// Buscar el código cuyo hash coincida
+	normalized := mfa.NormalizeRecoveryCode(code)
+	for _, recoveryCode := range codes {
+		if bcrypt.CompareHashAndPassword([]byte(recoveryCode.CodeHash), []byte(normalized)) == nil {
+			// Marcar el código como usado para que no se pueda reutilizar; otra solicitud pudo usarlo antes
+			err := repository.UseRecoveryCode(ctx, recoveryCode.Id)
+			if errors.Is(err, repository.ErrCodeUsed) {
+				return false, nil
+			}
+			if err != nil {
+				return false, err
+			}
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// verifyTOTPCode es una función que verifica un código TOTP y guarda su ventana, para que ni ese código ni uno
+// de una ventana anterior se acepten otra vez mientras siguen dentro del desfase permitido.
+func verifyTOTPCode(ctx context.Context, mfaConfig *models.UserMFA, code string) (bool, error) {
+	step, ok := mfa.ValidateStep(mfaConfig.Secret, code, time.Now())
+	if !ok {
+		return false, nil
+	}
+	err := repository.UseTOTPStep(ctx, mfaConfig.UserID, step)
+	if errors.Is(err, repository.ErrCodeUsed) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// newRecoveryCodes es una función que genera códigos de recuperación y devuelve tanto
+// los códigos en claro (para mostrarlos una vez) como sus hashes para guardarlos.
+func newRecoveryCodes(userId string) ([]string, []*models.RecoveryCode, error) {
+	// Generar los códigos en claro
+	plainCodes, err := mfa.GenerateRecoveryCodes(mfa.RECOVERY_CODES)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Hashear cada código
+	codes := make([]*models.RecoveryCode, 0, len(plainCodes))
+	for _, plain := range plainCodes {
+		hash, err := bcrypt.GenerateFromPassword([]byte(plain), HASH_COST)
+		if err != nil {
+			return nil, nil, err
+		}
+		codes = append(codes, &models.RecoveryCode{
+			Id:       ksuid.New().String(),
+			UserID:   userId,
+			CodeHash: string(hash),
+		})
+	}
+	return plainCodes, codes, nil
+}
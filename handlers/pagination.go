@@ -0,0 +1,34 @@
+// El paquete handlers contiene los controladores de las rutas HTTP.
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+// DEFAULT_PAGE_SIZE es el tamaño de página por defecto de los listados
+const DEFAULT_PAGE_SIZE = 20
+
+// pageParams es una función que obtiene los parámetros page y pageSize de la URL.
+// Si faltan se usan la primera página y el tamaño dado; si no son números positivos devuelve un error.
+func pageParams(r *http.Request, defaultSize int) (int, int, error) {
+	query := r.URL.Query()
+	// Obtener la página y su tamaño, con valores por defecto
+	page, pageSize := 1, defaultSize
+	var err error
+	if value := query.Get("page"); value != "" {
+		if page, err = strconv.Atoi(value); err != nil {
+			return 0, 0, errors.New("Invalid page number or page size")
+		}
+	}
+	if value := query.Get("pageSize"); value != "" {
+		if pageSize, err = strconv.Atoi(value); err != nil {
+			return 0, 0, errors.New("Invalid page number or page size")
+		}
+	}
+	if page < 1 || pageSize < 1 {
+		return 0, 0, errors.New("Invalid page number or page size")
+	}
+	return page, pageSize, nil
+}
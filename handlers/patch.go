@@ -0,0 +1,126 @@
+// El paquete handlers contiene los controladores de las rutas HTTP.
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"sort"
+
+	"talentpitchGo/mergepatch" // JSON Merge Patch
+)
+
+// MERGE_PATCH_CONTENT_TYPE es el tipo de contenido de JSON Merge Patch (RFC 7396).
+// Las rutas PATCH también aceptan application/json.
+const MERGE_PATCH_CONTENT_TYPE = "application/merge-patch+json"
+
+// Campos que se pueden modificar con PATCH en cada recurso
+var (
+	USER_PATCH_FIELDS      = []string{"fullname", "email"}
+	CHALLENGE_PATCH_FIELDS = []string{"title", "description", "difficulty", "user_id"}
+	COMPANY_PATCH_FIELDS   = []string{"name", "location", "industry", "user_id"}
+	PROGRAM_PATCH_FIELDS   = []string{"title", "description", "start_date", "end_date", "user_id"}
+)
+
+// errUnsupportedMediaType es el error que se devuelve cuando el cuerpo de un PATCH no es JSON
+var errUnsupportedMediaType = errors.New("PATCH body must be " + MERGE_PATCH_CONTENT_TYPE + " or application/json")
+
+// applyMergePatch es una función que aplica el JSON Merge Patch del cuerpo de la solicitud al recurso actual,
+// guarda el recurso combinado en result y devuelve los campos que cambiaron. Solo se pueden modificar los
+// campos editables; cambiar cualquier otro es un error.
+func applyMergePatch(r *http.Request, current interface{}, result interface{}, editable []string) ([]string, error) {
+	// Verificar el tipo de contenido
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil || (mediaType != MERGE_PATCH_CONTENT_TYPE && mediaType != "application/json") {
+			return nil, errUnsupportedMediaType
+		}
+	}
+	// Leer el parche, hasta MAX_JSON_BODY_SIZE
+	patch, err := io.ReadAll(io.LimitReader(r.Body, MAX_JSON_BODY_SIZE+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(patch) > MAX_JSON_BODY_SIZE {
+		return nil, errBodyTooLarge
+	}
+	// Combinar el recurso actual con el parche
+	document, err := json.Marshal(current)
+	if err != nil {
+		return nil, err
+	}
+	merged, err := mergepatch.Apply(document, patch)
+	if err != nil {
+		return nil, fmt.Errorf("invalid merge patch: %w", err)
+	}
+	// Comparar los campos antes y después del parche
+	before, err := jsonFields(document)
+	if err != nil {
+		return nil, err
+	}
+	after, err := jsonFields(merged)
+	if err != nil {
+		return nil, errors.New("merge patch must be a JSON object")
+	}
+	isEditable := map[string]bool{}
+	for _, field := range editable {
+		isEditable[field] = true
+	}
+	var changed []string
+	for _, field := range unionKeys(before, after) {
+		if reflect.DeepEqual(before[field], after[field]) {
+			continue
+		}
+		if !isEditable[field] {
+			return nil, fmt.Errorf("field %s cannot be modified", field)
+		}
+		changed = append(changed, field)
+	}
+	// Decodificar el recurso combinado; los campos eliminados con null quedan vacíos
+	if err := json.Unmarshal(merged, result); err != nil {
+		return nil, fmt.Errorf("invalid merge patch: %w", err)
+	}
+	return changed, nil
+}
+
+// jsonFields es una función que decodifica un objeto JSON en un mapa de campos, conservando los números
+func jsonFields(data []byte) (map[string]interface{}, error) {
+	var fields map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// unionKeys es una función que devuelve ordenadas las claves presentes en cualquiera de los dos mapas
+func unionKeys(a map[string]interface{}, b map[string]interface{}) []string {
+	seen := map[string]bool{}
+	var keys []string
+	for _, fields := range []map[string]interface{}{a, b} {
+		for key := range fields {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// writePatchError es una función que responde con el error de aplicar un JSON Merge Patch
+func writePatchError(w http.ResponseWriter, err error) {
+	if errors.Is(err, errUnsupportedMediaType) {
+		// Retornar un error de tipo de contenido no soportado
+		http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+		return
+	}
+	writeDecodeError(w, err)
+}
@@ -0,0 +1,116 @@
+// El paquete handlers contiene los controladores de las rutas HTTP.
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"talentpitchGo/audit"      // registro de auditoría
+	"talentpitchGo/models"     // modelos de datos
+	"talentpitchGo/repository" // operaciones de base de datos
+	"talentpitchGo/server"     // configuración del servidor
+
+	"github.com/gorilla/mux" // enrutador HTTP
+)
+
+// GetProgramHandler es el controlador para obtener un programa por su ID.
+func GetProgramHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Obtener el ID del programa de los parámetros de la ruta
+		id := mux.Vars(r)["id"]
+		// Obtener el programa de la base de datos
+		program, err := repository.GetProgramById(r.Context(), id)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				http.Error(w, "Programa no encontrado", http.StatusNotFound)
+			} else {
+				// Retornar un error interno del servidor
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		// Responder 304 si el cliente ya tiene esta versión
+		if notModified(w, r, program.Version) {
+			return
+		}
+		// Retornar la respuesta
+		w.Header().Set("Content-Type", "application/json")
+		setETag(w, program.Version)
+		// Codificar la respuesta
+		json.NewEncoder(w).Encode(program)
+	}
+}
+
+// PatchProgramHandler es el controlador que actualiza parcialmente el programa con JSON Merge Patch (RFC 7396).
+// Solo se actualizan en la base de datos las columnas que cambian.
+func PatchProgramHandler(s server.Server) http.HandlerFunc {
+	// Retornar la función del controlador
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Obtener el ID de la URL
+		id := mux.Vars(r)["id"]
+		// Obtener el programa actual
+		before, err := repository.GetProgramById(r.Context(), id)
+		if err != nil {
+			// Si hay un error, verifica si es porque no fue encontrado
+			if errors.Is(err, repository.ErrNotFound) {
+				http.Error(w, "Programa no encontrado", http.StatusNotFound)
+			} else {
+				// Si es otro error, maneja ese error
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		// Validar la versión esperada con la cabecera If-Match
+		version, ok := checkIfMatch(s, w, r, before.Version)
+		if !ok {
+			return
+		}
+		// Aplicar el parche sobre el programa actual
+		var program models.Program
+		fields, err := applyMergePatch(r, before, &program, PROGRAM_PATCH_FIELDS)
+		if err != nil {
+			writePatchError(w, err)
+			return
+		}
+		if len(fields) > 0 {
+			// Validar el resultado combinado
+			if err := repository.ValidateProgram(&program); err != nil {
+				// Responder 422 con los errores de los campos
+				if !writeValidationError(w, err) {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+				}
+				return
+			}
+			// Verificar que el cambio de dueño sea del dueño actual o de un administrador y que exista el nuevo dueño
+			if !checkOwnerChange(w, r, before.UserID, program.UserID, "Only the owner of the program can change its owner") {
+				return
+			}
+			// Actualizar solo las columnas modificadas
+			program.Version = version
+			err = repository.PatchProgram(r.Context(), id, &program, fields)
+			if err != nil {
+				if errors.Is(err, repository.ErrVersionConflict) {
+					// El recurso cambió desde la versión indicada en If-Match
+					http.Error(w, "Precondition Failed: the resource was modified", http.StatusPreconditionFailed)
+				} else if errors.Is(err, repository.ErrNotFound) {
+					http.Error(w, "Programa no encontrado", http.StatusNotFound)
+				} else {
+					// Retornar un error interno del servidor
+					http.Error(w, err.Error(), http.StatusInternalServerError)
+				}
+				return
+			}
+			// Registrar el cambio en el registro de auditoría
+			audit.Record(r, models.AUDIT_UPDATE, models.ENTITY_PROGRAM, id, before, &program)
+		} else {
+			// Sin cambios no se toca la base de datos
+			program = *before
+		}
+		// Retornar la respuesta
+		w.Header().Set("Content-Type", "application/json")
+		setETag(w, program.Version)
+		// Codificar el programa actualizado
+		json.NewEncoder(w).Encode(program)
+	}
+}
@@ -0,0 +1,217 @@
+// El paquete handlers contiene los controladores de las rutas HTTP.
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"talentpitchGo/audit"      // registro de auditoría
+	"talentpitchGo/metrics"    // métricas de Prometheus
+	"talentpitchGo/models"     // modelos de datos
+	"talentpitchGo/repository" // operaciones de base de datos
+	"talentpitchGo/server"     // configuración del servidor
+	"talentpitchGo/social"     // proveedores de inicio de sesión externos
+
+	"github.com/golang-jwt/jwt"  // para firmar la cookie de estado
+	"github.com/gorilla/mux"     // enrutador HTTP
+	"github.com/segmentio/ksuid" // para generar IDs únicos
+	"golang.org/x/crypto/bcrypt" // para hashear la contraseña aleatoria de los usuarios nuevos
+)
+
+// OAUTH_STATE_COOKIE es el nombre de la cookie que guarda el state y el code_verifier.
+// OAUTH_STATE_TTL es el tiempo máximo para completar el inicio de sesión en el proveedor.
+// OAUTH_COOKIE_PATH es la ruta de la cookie; cubre la URL de retorno aunque el login empiece en una ruta sin versión.
+const (
+	OAUTH_STATE_COOKIE = "oauth_state"
+	OAUTH_STATE_TTL    = time.Minute * 10
+	OAUTH_COOKIE_PATH  = server.API_V1_PREFIX + "/auth/"
+)
+
+// errUnverifiedEmail es el error que se devuelve cuando el proveedor no entrega un correo verificado.
+// errDeletedAccount es el error que se devuelve cuando la identidad pertenece a un usuario eliminado.
+var (
+	errUnverifiedEmail = errors.New("the provider did not return a verified email")
+	errDeletedAccount  = errors.New("the linked account has been deleted")
+)
+
+// SocialLoginHandler es el controlador que redirige al proveedor externo con un state y un reto PKCE
+func SocialLoginHandler(s server.Server) http.HandlerFunc {
+	// Retornar la función del controlador
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Obtener el proveedor de la URL
+		provider, ok := social.GetProvider(mux.Vars(r)["provider"])
+		if !ok {
+			http.Error(w, "Proveedor no encontrado", http.StatusNotFound)
+			return
+		}
+		// Generar el state y el code_verifier
+		state, err := randomString(32)
+		if err != nil {
+			// Retornar un error interno del servidor
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		verifier := social.GenerateVerifier()
+		// Guardar el state y el verifier en una cookie firmada
+		claims := models.OAuthStateClaims{
+			Provider: provider.Name(),
+			State:    state,
+			Verifier: verifier,
+			StandardClaims: jwt.StandardClaims{
+				ExpiresAt: time.Now().Add(OAUTH_STATE_TTL).Unix(),
+			},
+		}
+		cookie, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(s.Config().JWTSecret))
+		if err != nil {
+			// Retornar un error interno del servidor
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		http.SetCookie(w, &http.Cookie{
+			Name:     OAUTH_STATE_COOKIE,
+			Value:    cookie,
+			Path:     OAUTH_COOKIE_PATH,
+			MaxAge:   int(OAUTH_STATE_TTL.Seconds()),
+			HttpOnly: true,
+			Secure:   r.TLS != nil,
+			SameSite: http.SameSiteLaxMode,
+		})
+		// Redirigir al proveedor
+		http.Redirect(w, r, provider.AuthCodeURL(state, verifier), http.StatusFound)
+	}
+}
+
+// SocialCallbackHandler es el controlador que recibe el código del proveedor, vincula o crea el usuario
+// y responde igual que el login
+func SocialCallbackHandler(s server.Server) http.HandlerFunc {
+	// Retornar la función del controlador
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Obtener el proveedor de la URL
+		provider, ok := social.GetProvider(mux.Vars(r)["provider"])
+		if !ok {
+			http.Error(w, "Proveedor no encontrado", http.StatusNotFound)
+			return
+		}
+		// Verificar si el proveedor devolvió un error
+		if errorCode := r.URL.Query().Get("error"); errorCode != "" {
+			http.Error(w, errorCode, http.StatusUnauthorized)
+			return
+		}
+		// Leer y validar la cookie de estado
+		cookie, err := r.Cookie(OAUTH_STATE_COOKIE)
+		if err != nil {
+			http.Error(w, "Missing OAuth state", http.StatusBadRequest)
+			return
+		}
+		claims := &models.OAuthStateClaims{}
+		_, err = jwt.ParseWithClaims(cookie.Value, claims, func(token *jwt.Token) (interface{}, error) {
+			// Retornar el secreto JWT de la configuración del servidor
+			return []byte(s.Config().JWTSecret), nil
+		})
+		if err != nil || claims.Provider != provider.Name() ||
+			subtle.ConstantTimeCompare([]byte(claims.State), []byte(r.URL.Query().Get("state"))) != 1 {
+			http.Error(w, "Invalid OAuth state", http.StatusBadRequest)
+			return
+		}
+		// Eliminar la cookie, el state es de un solo uso
+		http.SetCookie(w, &http.Cookie{Name: OAUTH_STATE_COOKIE, Value: "", Path: OAUTH_COOKIE_PATH, MaxAge: -1})
+
+		// Intercambiar el código por la identidad del usuario
+		identity, err := provider.Exchange(r.Context(), r.URL.Query().Get("code"), claims.Verifier)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		// Obtener o crear el usuario vinculado a la identidad
+		userId, err := resolveSocialUser(r, identity)
+		if errors.Is(err, errUnverifiedEmail) || errors.Is(err, errDeletedAccount) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		if err != nil {
+			// Retornar un error interno del servidor
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		// Retornar el token de acceso o el desafío 2FA
+		writeLoginResponse(s, w, r, userId, metrics.LOGIN_SOCIAL)
+	}
+}
+
+// resolveSocialUser es una función que obtiene el usuario vinculado a una identidad externa.
+// Si no existe, la vincula al usuario con el mismo correo verificado o crea un usuario nuevo.
+func resolveSocialUser(r *http.Request, identity *social.Identity) (string, error) {
+	ctx := r.Context()
+	// Buscar una identidad ya vinculada
+	linked, err := repository.GetIdentity(ctx, identity.Provider, identity.Subject)
+	if err != nil {
+		return "", err
+	}
+	if linked != nil {
+		// Verificar que el usuario vinculado no haya sido eliminado
+		if _, err := repository.GetUserById(ctx, linked.UserID); err != nil {
+			return "", errDeletedAccount
+		}
+		return linked.UserID, nil
+	}
+	// Solo se vincula o se crea por correo si el proveedor lo verificó, para evitar apropiarse de cuentas ajenas
+	if identity.Email == "" || !identity.EmailVerified {
+		return "", errUnverifiedEmail
+	}
+	record := &models.Identity{
+		Id:       ksuid.New().String(),
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+		Email:    identity.Email,
+	}
+	// Vincular con el usuario existente que tenga el mismo correo
+	user, err := repository.GetUserByEmail(ctx, identity.Email)
+	if err != nil {
+		return "", err
+	}
+	if user != nil && user.Id != "" {
+		record.UserID = user.Id
+		return user.Id, repository.InsertIdentity(ctx, record)
+	}
+	// Crear un usuario nuevo con una contraseña aleatoria que nadie conoce
+	password, err := randomString(32)
+	if err != nil {
+		return "", err
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), HASH_COST)
+	if err != nil {
+		return "", err
+	}
+	fullname := identity.Name
+	if fullname == "" {
+		fullname = strings.Split(identity.Email, "@")[0]
+	}
+	newUser := &models.User{
+		Id:       ksuid.New().String(),
+		Fullname: fullname,
+		Email:    identity.Email,
+		Password: string(hashedPassword),
+	}
+	record.UserID = newUser.Id
+	if err := repository.InsertUserWithIdentity(ctx, newUser, record); err != nil {
+		return "", err
+	}
+	// Registrar el alta en el registro de auditoría
+	audit.Record(r, models.AUDIT_CREATE, models.ENTITY_USER, newUser.Id, nil, newUser)
+	metrics.SIGNUPS.Inc()
+	return newUser.Id, nil
+}
+
+// randomString es una función que genera una cadena aleatoria segura codificada en base64 URL.
+func randomString(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
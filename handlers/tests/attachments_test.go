@@ -0,0 +1,152 @@
+package server_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"talentpitchGo/handlers"
+	"talentpitchGo/middleware"
+	"talentpitchGo/models"
+	"talentpitchGo/repository"
+	"talentpitchGo/storage"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeAttachmentRepository guarda los archivos adjuntos en memoria
+type fakeAttachmentRepository struct {
+	attachments []*models.Attachment
+}
+
+func (f *fakeAttachmentRepository) InsertAttachment(ctx context.Context, attachment *models.Attachment) error {
+	f.attachments = append(f.attachments, attachment)
+	return nil
+}
+
+func (f *fakeAttachmentRepository) GetAttachments(ctx context.Context, challengeID string) ([]*models.Attachment, error) {
+	return f.attachments, nil
+}
+
+func (f *fakeAttachmentRepository) GetAttachmentById(ctx context.Context, challengeID string, id string) (*models.Attachment, error) {
+	for _, attachment := range f.attachments {
+		if attachment.ChallengeID == challengeID && attachment.Id == id {
+			copy := *attachment
+			return &copy, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (f *fakeAttachmentRepository) DeleteAttachment(ctx context.Context, challengeID string, id string) error {
+	for i, attachment := range f.attachments {
+		if attachment.ChallengeID == challengeID && attachment.Id == id {
+			f.attachments = append(f.attachments[:i], f.attachments[i+1:]...)
+			return nil
+		}
+	}
+	return repository.ErrNotFound
+}
+
+// attachmentRouter es el enrutador de los archivos adjuntos con el middleware de autenticación
+func attachmentRouter() *mux.Router {
+	s := &etagServer{}
+	router := mux.NewRouter()
+	router.Use(middleware.CheckAuthMiddleware(s))
+	router.HandleFunc("/challenges/{id}/attachments", handlers.ListAttachmentsHandler(s)).Methods(http.MethodGet)
+	router.HandleFunc("/challenges/{id}/attachments", handlers.UploadAttachmentHandler(s)).Methods(http.MethodPost)
+	router.HandleFunc("/challenges/{id}/attachments/{attachmentId}", handlers.DownloadAttachmentHandler(s)).Methods(http.MethodGet)
+	router.HandleFunc("/challenges/{id}/attachments/{attachmentId}", handlers.DeleteAttachmentHandler(s)).Methods(http.MethodDelete)
+	return router
+}
+
+// userToken firma un token JWT de prueba para el usuario dado
+func userToken(userID string) string {
+	token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, models.AppClaims{UserId: userID}).SignedString([]byte("secret"))
+	return "Bearer " + token
+}
+
+// serveAttachment ejecuta una solicitud autenticada como el usuario dado
+func serveAttachment(req *http.Request, userID string) *httptest.ResponseRecorder {
+	req.Header.Set("Authorization", userToken(userID))
+	rr := httptest.NewRecorder()
+	attachmentRouter().ServeHTTP(rr, req)
+	return rr
+}
+
+// attachmentForm construye el formulario multipart con un archivo
+func attachmentForm(filename string, content string) (*bytes.Buffer, string) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, _ := form.CreateFormFile(handlers.UPLOAD_FIELD, filename)
+	part.Write([]byte(content))
+	form.Close()
+	return &body, form.FormDataContentType()
+}
+
+func TestChallengeAttachments(t *testing.T) {
+	store, _ := storage.NewLocalStore(t.TempDir(), "/uploads")
+	storage.SetBlobStore(store)
+	repository.SetChallengeRepository(&fakeChallengeRepository{challenge: models.Challenge{Id: "c1", Title: "Go", Difficulty: 2, UserID: "owner", Version: 1}})
+	repo := &fakeAttachmentRepository{}
+	repository.SetAttachmentRepository(repo)
+
+	// Solo el dueño del reto puede adjuntar archivos
+	body, contentType := attachmentForm("datos.csv", "a,b\n1,2\n")
+	req := httptest.NewRequest(http.MethodPost, "/challenges/c1/attachments", body)
+	req.Header.Set("Content-Type", contentType)
+	rr := serveAttachment(req, "other")
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	assert.Empty(t, repo.attachments)
+
+	body, contentType = attachmentForm("../../datos.csv", "a,b\n1,2\n")
+	req = httptest.NewRequest(http.MethodPost, "/challenges/c1/attachments", body)
+	req.Header.Set("Content-Type", contentType)
+	rr = serveAttachment(req, "owner")
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	var attachment models.Attachment
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&attachment))
+	// El nombre no conserva la ruta del cliente y el tipo se obtiene de la extensión
+	assert.Equal(t, "datos.csv", attachment.Filename)
+	assert.Equal(t, "text/csv; charset=utf-8", attachment.ContentType)
+	assert.Equal(t, int64(8), attachment.Size)
+	assert.Equal(t, "/api/v1/challenges/c1/attachments/"+attachment.Id, attachment.DownloadURL)
+
+	// Cualquier usuario autenticado puede listar y descargar
+	rr = serveAttachment(httptest.NewRequest(http.MethodGet, "/challenges/c1/attachments", nil), "other")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var list handlers.AttachmentList
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&list))
+	assert.Equal(t, 1, list.Total)
+
+	rr = serveAttachment(httptest.NewRequest(http.MethodGet, "/challenges/c1/attachments/"+attachment.Id, nil), "other")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "a,b\n1,2\n", rr.Body.String())
+	assert.Equal(t, "attachment; filename*=UTF-8''datos.csv", rr.Header().Get("Content-Disposition"))
+	assert.Equal(t, "nosniff", rr.Header().Get("X-Content-Type-Options"))
+
+	// El contenido no se sirve bajo /uploads: solo se descarga con el controlador que verifica el acceso
+	uploads := http.StripPrefix("/uploads/", storage.Handler())
+	for _, target := range []string{
+		"/uploads/challenges/c1/attachments/" + attachment.Id,
+		"/uploads/" + storage.PRIVATE_PREFIX + "challenges/c1/attachments/" + attachment.Id,
+	} {
+		rr = httptest.NewRecorder()
+		uploads.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, target, nil))
+		assert.Equal(t, http.StatusNotFound, rr.Code, target)
+	}
+
+	// Solo el dueño puede eliminar; después la descarga responde 404
+	rr = serveAttachment(httptest.NewRequest(http.MethodDelete, "/challenges/c1/attachments/"+attachment.Id, nil), "other")
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	rr = serveAttachment(httptest.NewRequest(http.MethodDelete, "/challenges/c1/attachments/"+attachment.Id, nil), "owner")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	rr = serveAttachment(httptest.NewRequest(http.MethodGet, "/challenges/c1/attachments/"+attachment.Id, nil), "owner")
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
@@ -0,0 +1,90 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"talentpitchGo/handlers"
+	"talentpitchGo/models"
+	"talentpitchGo/repository"
+	"talentpitchGo/validation"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeUnitOfWork ejecuta la unidad de trabajo sobre una copia de los datos y solo la conserva si no hubo error
+type fakeUnitOfWork struct {
+	repository.Repositories
+	programs     map[string]models.Program
+	companies    []models.Company
+	participants []models.ProgramParticipant
+}
+
+func (f *fakeUnitOfWork) WithTx(ctx context.Context, options repository.TxOptions, fn func(repos repository.Repositories) error) error {
+	tx := &fakeUnitOfWork{programs: f.programs, companies: f.companies, participants: f.participants}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	f.companies, f.participants = tx.companies, tx.participants
+	return nil
+}
+
+func (f *fakeUnitOfWork) GetProgramById(ctx context.Context, id string) (*models.Program, error) {
+	program, ok := f.programs[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &program, nil
+}
+
+func (f *fakeUnitOfWork) InsertCompany(ctx context.Context, company *models.Company) error {
+	company.Version = 1
+	f.companies = append(f.companies, *company)
+	return nil
+}
+
+func (f *fakeUnitOfWork) InsertProgramParticipant(ctx context.Context, participant *models.ProgramParticipant) error {
+	f.participants = append(f.participants, *participant)
+	return nil
+}
+
+func TestCreateCompanyInProgram(t *testing.T) {
+	repository.SetUserRepository(&fakeUserRepository{users: map[string]models.User{"u1": {Id: "u1"}}})
+	uow := &fakeUnitOfWork{programs: map[string]models.Program{"p1": {Id: "p1"}}}
+	repository.SetUnitOfWork(uow)
+	create := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/companies", strings.NewReader(body))
+		rr := httptest.NewRecorder()
+		handlers.CreateCompanyHandler(&etagServer{}).ServeHTTP(rr, req)
+		return rr
+	}
+
+	// Un programa desconocido es un error del campo y no se crea la empresa
+	rr := create(`{"name":"Acme","location":"Bogotá","industry":"Tech","user_id":"u1","program_id":"missing"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	var response handlers.ValidationErrorResponse
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	assert.Equal(t, validation.Errors{{Field: "program_id", Code: validation.CODE_NOT_FOUND, Message: "program not found"}}, response.Errors)
+	assert.Empty(t, uow.companies)
+
+	// La empresa se crea e inscribe en el programa en la misma unidad de trabajo
+	rr = create(`{"name":"Acme","location":"Bogotá","industry":"Tech","user_id":"u1","program_id":"p1"}`)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	if assert.Len(t, uow.companies, 1) && assert.Len(t, uow.participants, 1) {
+		assert.Equal(t, models.ProgramParticipant{
+			Id:         uow.participants[0].Id,
+			ProgramID:  "p1",
+			EntityType: models.ENTITY_COMPANY,
+			EntityID:   uow.companies[0].Id,
+		}, uow.participants[0])
+	}
+
+	// Sin programa solo se crea la empresa
+	assert.Equal(t, http.StatusOK, create(`{"name":"Other","location":"Bogotá","industry":"Tech","user_id":"u1"}`).Code)
+	assert.Len(t, uow.companies, 2)
+	assert.Len(t, uow.participants, 1)
+}
@@ -0,0 +1,32 @@
+package server_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"talentpitchGo/handlers"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStrictJSONDecoding(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"unknown field", `{"title":"t","description":"d","difficulty":1,"user_id":"u1","owner":"x"}`, http.StatusBadRequest},
+		{"trailing data", `{"title":"t","description":"d","difficulty":1,"user_id":"u1"} {}`, http.StatusBadRequest},
+		{"too large", `{"title":"` + strings.Repeat("a", handlers.MAX_JSON_BODY_SIZE) + `"}`, http.StatusRequestEntityTooLarge},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/challenges", strings.NewReader(test.body))
+			rr := httptest.NewRecorder()
+			handlers.CreateChallengeHandler(&etagServer{}).ServeHTTP(rr, req)
+			assert.Equal(t, test.status, rr.Code)
+		})
+	}
+}
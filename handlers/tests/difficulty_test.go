@@ -0,0 +1,35 @@
+package server_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"talentpitchGo/handlers"
+	"talentpitchGo/models"
+	"talentpitchGo/repository"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChallengeStats(t *testing.T) {
+	repository.SetChallengeRepository(&fakeChallengeRepository{challenge: models.Challenge{Id: "c1", Difficulty: models.DIFFICULTY_ADVANCED}})
+
+	// Todos los niveles aparecen en el conteo, aunque no tengan retos
+	rr := httptest.NewRecorder()
+	handlers.ChallengeStatsHandler(&etagServer{}).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/challenges/stats?difficulty=advanced,1", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var response handlers.ChallengeStatsResponse
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	assert.Len(t, response.Stats, len(models.DIFFICULTIES))
+	assert.Equal(t, "advanced", response.Stats[3].Name)
+	assert.Equal(t, 1, response.Stats[3].Count)
+	assert.Equal(t, 0, response.Stats[0].Count)
+	assert.Equal(t, 1, response.Total)
+
+	// Un nivel fuera de la escala se rechaza
+	rr = httptest.NewRecorder()
+	handlers.ChallengeStatsHandler(&etagServer{}).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/challenges/stats?difficulty=7", nil))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
@@ -0,0 +1,139 @@
+package server_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"talentpitchGo/handlers"
+	"talentpitchGo/models"
+	"talentpitchGo/repository"
+	"talentpitchGo/server"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+// etagServer es un servidor de prueba que exige If-Match
+type etagServer struct{}
+
+func (s *etagServer) Config() *server.Config {
+	return &server.Config{JWTSecret: "secret", RequireIfMatch: true}
+}
+
+// fakeChallengeRepository guarda un único reto en memoria
+type fakeChallengeRepository struct {
+	challenge models.Challenge
+	// expected es la versión que el controlador exigió en la última modificación
+	expected int
+	// fields son las columnas de la última actualización parcial
+	fields []string
+}
+
+func (f *fakeChallengeRepository) InsertChallenge(ctx context.Context, challenge *models.Challenge) error {
+	return nil
+}
+
+func (f *fakeChallengeRepository) UpdateChallenge(ctx context.Context, id string, challenge *models.Challenge) error {
+	f.expected = challenge.Version
+	if challenge.Version != 0 && challenge.Version != f.challenge.Version {
+		return repository.ErrVersionConflict
+	}
+	challenge.Version = f.challenge.Version + 1
+	f.challenge = *challenge
+	return nil
+}
+
+func (f *fakeChallengeRepository) DeleteChallenge(ctx context.Context, id string, version int) error {
+	f.expected = version
+	return nil
+}
+
+func (f *fakeChallengeRepository) PatchChallenge(ctx context.Context, id string, challenge *models.Challenge, fields []string) error {
+	f.fields = fields
+	return f.UpdateChallenge(ctx, id, challenge)
+}
+
+func (f *fakeChallengeRepository) RestoreChallenge(ctx context.Context, id string) error {
+	return nil
+}
+
+func (f *fakeChallengeRepository) GetChallenges(ctx context.Context, filter models.ChallengeFilter, page int, pageSize int) ([]*models.Challenge, int, error) {
+	return []*models.Challenge{&f.challenge}, 1, nil
+}
+
+func (f *fakeChallengeRepository) GetChallengeStats(ctx context.Context, filter models.ChallengeFilter) (map[int]int, error) {
+	return map[int]int{f.challenge.Difficulty: 1}, nil
+}
+
+func (f *fakeChallengeRepository) GetChallengeById(ctx context.Context, id string) (*models.Challenge, error) {
+	if id != f.challenge.Id {
+		return nil, repository.ErrNotFound
+	}
+	challenge := f.challenge
+	return &challenge, nil
+}
+
+func (f *fakeChallengeRepository) CloseChallenge() error {
+	return nil
+}
+
+// serveChallenge ejecuta una solicitud contra el enrutador de retos de prueba
+func serveChallenge(method string, body []byte, headers map[string]string) *httptest.ResponseRecorder {
+	s := &etagServer{}
+	router := mux.NewRouter()
+	router.HandleFunc("/challenges/{id}", handlers.GetChallengeHandler(s)).Methods(http.MethodGet)
+	router.HandleFunc("/updateChallenge/{id}", handlers.UpdateChallengeHandler(s)).Methods(http.MethodPut)
+	router.HandleFunc("/deleteChallenge/{id}", handlers.DeleteChallengeHandler(s)).Methods(http.MethodDelete)
+
+	paths := map[string]string{http.MethodGet: "/challenges/c1", http.MethodPut: "/updateChallenge/c1", http.MethodDelete: "/deleteChallenge/c1"}
+	req := httptest.NewRequest(method, paths[method], bytes.NewReader(body))
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestChallengeETag(t *testing.T) {
+	repo := &fakeChallengeRepository{challenge: models.Challenge{Id: "c1", Title: "Go", Description: "d", Difficulty: 2, UserID: "u1", Version: 3}}
+	repository.SetChallengeRepository(repo)
+	body, _ := json.Marshal(handlers.ChallengeRequest{Title: "Go 2", Description: "d", Difficulty: 2, UserID: "u1"})
+
+	// GET devuelve la versión como ETag
+	rr := serveChallenge(http.MethodGet, nil, nil)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `"3"`, rr.Header().Get("ETag"))
+
+	// If-None-Match con la ETag actual devuelve 304 sin cuerpo
+	rr = serveChallenge(http.MethodGet, nil, map[string]string{"If-None-Match": `W/"3"`})
+	assert.Equal(t, http.StatusNotModified, rr.Code)
+	assert.Empty(t, rr.Body.String())
+
+	// PUT sin If-Match se rechaza cuando la configuración lo exige
+	rr = serveChallenge(http.MethodPut, body, nil)
+	assert.Equal(t, http.StatusPreconditionRequired, rr.Code)
+
+	// PUT con una ETag antigua devuelve 412
+	rr = serveChallenge(http.MethodPut, body, map[string]string{"If-Match": `"2"`})
+	assert.Equal(t, http.StatusPreconditionFailed, rr.Code)
+
+	// PUT con la ETag actual exige esa versión al repositorio y devuelve la nueva ETag
+	rr = serveChallenge(http.MethodPut, body, map[string]string{"If-Match": `"3"`})
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, 3, repo.expected)
+	assert.Equal(t, `"4"`, rr.Header().Get("ETag"))
+
+	// DELETE con una ETag antigua devuelve 412
+	rr = serveChallenge(http.MethodDelete, nil, map[string]string{"If-Match": `"3"`})
+	assert.Equal(t, http.StatusPreconditionFailed, rr.Code)
+
+	// DELETE con If-Match: * exige la versión actual
+	rr = serveChallenge(http.MethodDelete, nil, map[string]string{"If-Match": "*"})
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, 4, repo.expected)
+}
@@ -0,0 +1,90 @@
+package server_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"talentpitchGo/handlers"
+	"talentpitchGo/mfa"
+	"talentpitchGo/models"
+	"talentpitchGo/repository"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// fakeMFARepository guarda la configuración 2FA de un usuario y la última ventana TOTP aceptada en memoria
+type fakeMFARepository struct {
+	repository.MFARepository
+	config   models.UserMFA
+	lastStep int64
+	codes    []*models.RecoveryCode
+	useErr   error
+}
+
+func (f *fakeMFARepository) GetUserMFA(ctx context.Context, userId string) (*models.UserMFA, error) {
+	config := f.config
+	return &config, nil
+}
+
+func (f *fakeMFARepository) UseTOTPStep(ctx context.Context, userId string, step int64) error {
+	if step <= f.lastStep {
+		return repository.ErrCodeUsed
+	}
+	f.lastStep = step
+	return nil
+}
+
+func (f *fakeMFARepository) GetRecoveryCodes(ctx context.Context, userId string) ([]*models.RecoveryCode, error) {
+	return f.codes, nil
+}
+
+func (f *fakeMFARepository) UseRecoveryCode(ctx context.Context, id string) error {
+	return f.useErr
+}
+
+func TestLoginMFARejectsReusedCodes(t *testing.T) {
+	secret, _ := mfa.GenerateSecret()
+	hash, _ := bcrypt.GenerateFromPassword([]byte("abcde-fghjk"), bcrypt.MinCost)
+	repo := &fakeMFARepository{
+		config: models.UserMFA{UserID: "u1", Secret: secret, Enabled: true},
+		codes:  []*models.RecoveryCode{{Id: "r1", UserID: "u1", CodeHash: string(hash)}},
+	}
+	repository.SetMFARepository(repo)
+	challenge, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, models.AppClaims{
+		UserId:         "u1",
+		MFAPending:     true,
+		StandardClaims: jwt.StandardClaims{ExpiresAt: time.Now().Add(time.Minute).Unix()},
+	}).SignedString([]byte("secret"))
+
+	login := func(code string) int {
+		body := `{"mfa_token":"` + challenge + `","code":"` + code + `"}`
+		req := httptest.NewRequest(http.MethodPost, "/login/mfa", strings.NewReader(body))
+		rr := httptest.NewRecorder()
+		handlers.LoginMFAHandler(&etagServer{})(rr, req)
+		return rr.Code
+	}
+
+	// Un código TOTP solo se acepta una vez, y tampoco uno de una ventana anterior
+	code, _ := mfa.GenerateCode(secret, time.Now())
+	assert.Equal(t, http.StatusOK, login(code))
+	assert.Equal(t, http.StatusUnauthorized, login(code))
+	previous, _ := mfa.GenerateCode(secret, time.Now().Add(-mfa.PERIOD*time.Second))
+	if previous != code {
+		assert.Equal(t, http.StatusUnauthorized, login(previous))
+	}
+
+	// Un código de recuperación que otra solicitud ya usó no es válido, y un fallo al marcarlo es un error
+	repo.useErr = repository.ErrCodeUsed
+	assert.Equal(t, http.StatusUnauthorized, login("abcde-fghjk"))
+	repo.useErr = errors.New("connection lost")
+	assert.Equal(t, http.StatusInternalServerError, login("abcde-fghjk"))
+	repo.useErr = nil
+	assert.Equal(t, http.StatusOK, login("abcde-fghjk"))
+}
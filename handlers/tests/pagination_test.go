@@ -0,0 +1,36 @@
+package server_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"talentpitchGo/handlers"
+	"talentpitchGo/models"
+	"talentpitchGo/repository"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListPagination(t *testing.T) {
+	repository.SetChallengeRepository(&fakeChallengeRepository{challenge: models.Challenge{Id: "c1", Difficulty: 2}})
+	s := &etagServer{}
+
+	for _, test := range []struct {
+		handler http.HandlerFunc
+		url     string
+		status  int
+	}{
+		// Sin parámetros se usan la primera página y el tamaño por defecto
+		{handlers.ListChallengesHandler(s), "/challenges?difficulty=2", http.StatusOK},
+		// Una página o un tamaño que no son números positivos se rechazan, aunque haya otros filtros válidos
+		{handlers.ListChallengesHandler(s), "/challenges?page=abc&difficulty=2", http.StatusBadRequest},
+		{handlers.ListChallengesHandler(s), "/challenges?page=1&pageSize=0", http.StatusBadRequest},
+		{handlers.ListCompaniesHandler(s), "/companies?page=abc", http.StatusBadRequest},
+		{handlers.ListCompaniesHandler(s), "/companies?pageSize=-1", http.StatusBadRequest},
+	} {
+		rr := httptest.NewRecorder()
+		test.handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, test.url, nil))
+		assert.Equal(t, test.status, rr.Code, test.url)
+	}
+}
@@ -0,0 +1,58 @@
+package server_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"talentpitchGo/handlers"
+	"talentpitchGo/models"
+	"talentpitchGo/repository"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+// patchChallenge ejecuta un PATCH contra el enrutador de retos de prueba
+func patchChallenge(body string, contentType string, ifMatch string) *httptest.ResponseRecorder {
+	router := mux.NewRouter()
+	router.HandleFunc("/challenges/{id}", handlers.PatchChallengeHandler(&etagServer{})).Methods(http.MethodPatch)
+
+	req := httptest.NewRequest(http.MethodPatch, "/challenges/c1", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("If-Match", ifMatch)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestPatchChallenge(t *testing.T) {
+	repo := &fakeChallengeRepository{challenge: models.Challenge{Id: "c1", Title: "Go", Description: "d", Difficulty: 2, UserID: "u1", Version: 3}}
+	repository.SetChallengeRepository(repo)
+
+	// Solo cambia el título; el resto de los campos se conserva
+	rr := patchChallenge(`{"title":"Go 2","description":"d"}`, handlers.MERGE_PATCH_CONTENT_TYPE, `"3"`)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, []string{"title"}, repo.fields)
+	assert.Equal(t, 3, repo.expected)
+	assert.Equal(t, `"4"`, rr.Header().Get("ETag"))
+	var challenge models.Challenge
+	json.NewDecoder(rr.Body).Decode(&challenge)
+	assert.Equal(t, "Go 2", challenge.Title)
+	assert.Equal(t, 2, challenge.Difficulty)
+
+	// La validación se hace sobre el resultado combinado
+	rr = patchChallenge(`{"title":null}`, handlers.MERGE_PATCH_CONTENT_TYPE, `"4"`)
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	assert.Contains(t, rr.Body.String(), `{"field":"title","code":"required","message":"is required"}`)
+
+	// Los campos que no son editables se rechazan
+	rr = patchChallenge(`{"version":9}`, handlers.MERGE_PATCH_CONTENT_TYPE, `"4"`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	// Otro tipo de contenido devuelve 415
+	rr = patchChallenge(`{"title":"Go 3"}`, "text/plain", `"4"`)
+	assert.Equal(t, http.StatusUnsupportedMediaType, rr.Code)
+}
@@ -0,0 +1,40 @@
+package server_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"talentpitchGo/handlers"
+	"talentpitchGo/models"
+	"talentpitchGo/repository"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetChallengeRenderHTML(t *testing.T) {
+	repository.SetChallengeRepository(&fakeChallengeRepository{challenge: models.Challenge{Id: "c1", Title: "Go", Description: "**API** <script>", Difficulty: 2, UserID: "u1", Version: 1}})
+	router := mux.NewRouter()
+	router.HandleFunc("/challenges/{id}", handlers.GetChallengeHandler(&etagServer{})).Methods(http.MethodGet)
+
+	// Con render=html se agrega la descripción en HTML seguro y se conserva el markdown original
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/challenges/c1?render=html", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var response map[string]interface{}
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	assert.Equal(t, "**API** <script>", response["description"])
+	assert.Equal(t, "<p><strong>API</strong> &lt;script&gt;</p>\n", response["description_html"])
+
+	// Sin render la respuesta no cambia
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/challenges/c1", nil))
+	assert.NotContains(t, rr.Body.String(), "description_html")
+
+	// Otros formatos se rechazan
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/challenges/c1?render=pdf", nil))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
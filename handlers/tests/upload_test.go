@@ -0,0 +1,179 @@
+package server_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"talentpitchGo/handlers"
+	"talentpitchGo/middleware"
+	"talentpitchGo/models"
+	"talentpitchGo/repository"
+	"talentpitchGo/storage"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeCompanyRepository guarda una única empresa en memoria; los métodos que no usa la prueba no están implementados
+type fakeCompanyRepository struct {
+	repository.CompanyRepository
+	company models.Company
+	// fields son las columnas de la última actualización parcial
+	fields []string
+}
+
+func (f *fakeCompanyRepository) GetCompanyById(ctx context.Context, id string) (*models.Company, error) {
+	if id != f.company.Id {
+		return nil, repository.ErrNotFound
+	}
+	company := f.company
+	return &company, nil
+}
+
+func (f *fakeCompanyRepository) PatchCompany(ctx context.Context, id string, company *models.Company, fields []string) error {
+	if company.Version != 0 && company.Version != f.company.Version {
+		return repository.ErrVersionConflict
+	}
+	f.fields = fields
+	company.Version = f.company.Version + 1
+	f.company = *company
+	return nil
+}
+
+func (f *fakeCompanyRepository) UpdateCompany(ctx context.Context, id string, company *models.Company) error {
+	if company.Version != 0 && company.Version != f.company.Version {
+		return repository.ErrVersionConflict
+	}
+	company.Version = f.company.Version + 1
+	f.company = *company
+	f.company.Id = id
+	return nil
+}
+
+// fakeUserRepository guarda los usuarios en memoria; los métodos que no usa la prueba no están implementados
+type fakeUserRepository struct {
+	repository.UserRepository
+	users map[string]models.User
+}
+
+func (f *fakeUserRepository) GetUserById(ctx context.Context, id string) (*models.User, error) {
+	user, ok := f.users[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &user, nil
+}
+
+// uploadLogo sube el archivo dado como logo de la empresa c1 con la cabecera If-Match dada, autenticado como el usuario dado
+func uploadLogo(data []byte, ifMatch string, userID string) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, _ := form.CreateFormFile(handlers.UPLOAD_FIELD, "logo.png")
+	part.Write(data)
+	form.Close()
+
+	s := &etagServer{}
+	router := mux.NewRouter()
+	router.Use(middleware.CheckAuthMiddleware(s))
+	router.HandleFunc("/companies/{id}/logo", handlers.UploadCompanyLogoHandler(s)).Methods(http.MethodPost)
+	req := httptest.NewRequest(http.MethodPost, "/companies/c1/logo", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("If-Match", ifMatch)
+	req.Header.Set("Authorization", userToken(userID))
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestUploadCompanyLogo(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := storage.NewLocalStore(dir, "/uploads")
+	storage.SetBlobStore(store)
+	repo := &fakeCompanyRepository{company: models.Company{Id: "c1", Name: "Acme", Location: "Bogotá", Industry: "Tech", UserID: "u1", Version: 2}}
+	repository.SetCompanyRepository(repo)
+	repository.SetUserRepository(&fakeUserRepository{users: map[string]models.User{
+		"u1":    {Id: "u1", Role: "user"},
+		"other": {Id: "other", Role: "user"},
+		"admin": {Id: "admin", Role: models.ROLE_ADMIN},
+	}})
+
+	var logo bytes.Buffer
+	png.Encode(&logo, image.NewNRGBA(image.Rect(0, 0, 600, 300)))
+
+	// La imagen y sus miniaturas se guardan y image_path se asigna sola
+	rr := uploadLogo(logo.Bytes(), `"2"`, "u1")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var response handlers.ImageUploadResponse
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	assert.True(t, strings.HasPrefix(response.ImagePath, "/uploads/companies/c1/logo/"))
+	assert.Equal(t, response.ImagePath, repo.company.ImagePath)
+	assert.Equal(t, []string{"image_path"}, repo.fields)
+	assert.Equal(t, `"3"`, rr.Header().Get("ETag"))
+	assert.Len(t, response.Thumbnails, 2)
+
+	// La miniatura de 64 píxeles conserva la proporción
+	file, err := os.Open(filepath.Join(dir, strings.TrimPrefix(response.Thumbnails["64"], "/uploads/")))
+	assert.NoError(t, err)
+	defer file.Close()
+	thumb, err := png.DecodeConfig(file)
+	assert.NoError(t, err)
+	assert.Equal(t, 64, thumb.Width)
+	assert.Equal(t, 32, thumb.Height)
+
+	// Un archivo que no es una imagen se rechaza por su contenido, aunque se llame logo.png
+	repo.fields = nil
+	rr = uploadLogo([]byte("not an image"), `"3"`, "u1")
+	assert.Equal(t, http.StatusUnsupportedMediaType, rr.Code)
+	assert.Nil(t, repo.fields)
+
+	// Solo el dueño de la empresa y los administradores pueden cambiar el logo
+	rr = uploadLogo(logo.Bytes(), `"3"`, "other")
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	assert.Nil(t, repo.fields)
+
+	// Al reemplazar el logo se eliminan la imagen anterior y sus miniaturas
+	rr = uploadLogo(logo.Bytes(), `"3"`, "admin")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	for _, url := range []string{response.ImagePath, response.Thumbnails["256"], response.Thumbnails["64"]} {
+		_, err := os.Stat(filepath.Join(dir, strings.TrimPrefix(url, "/uploads/")))
+		assert.ErrorIs(t, err, os.ErrNotExist, url)
+	}
+	var replaced handlers.ImageUploadResponse
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&replaced))
+	_, err = os.Stat(filepath.Join(dir, strings.TrimPrefix(replaced.ImagePath, "/uploads/")))
+	assert.NoError(t, err)
+}
+
+func TestCompanyLogoOnlyChangedByUpload(t *testing.T) {
+	repo := &fakeCompanyRepository{company: models.Company{Id: "c1", Name: "Acme", ImagePath: "/uploads/companies/c1/logo/a.png", Location: "Bogotá", Industry: "Tech", UserID: "u1", Version: 1}}
+	repository.SetCompanyRepository(repo)
+	s := &etagServer{}
+
+	// Un PUT sin image_path no borra el logo
+	req := mux.SetURLVars(httptest.NewRequest(http.MethodPut, "/", strings.NewReader(`{"name":"Acme","location":"Cali","industry":"Tech","user_id":"u1"}`)), map[string]string{"id": "c1"})
+	req.Header.Set("If-Match", `"1"`)
+	rr := httptest.NewRecorder()
+	handlers.UpdateCompanyHandler(s).ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "Cali", repo.company.Location)
+	assert.Equal(t, "/uploads/companies/c1/logo/a.png", repo.company.ImagePath)
+
+	// Un PATCH no puede asignar image_path
+	req = mux.SetURLVars(httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(`{"image_path":"https://example.com/logo.png"}`)), map[string]string{"id": "c1"})
+	req.Header.Set("If-Match", `"2"`)
+	rr = httptest.NewRecorder()
+	handlers.PatchCompanyHandler(s).ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Nil(t, repo.fields)
+	assert.Equal(t, "/uploads/companies/c1/logo/a.png", repo.company.ImagePath)
+}
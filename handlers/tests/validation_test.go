@@ -0,0 +1,146 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"talentpitchGo/handlers"
+	"talentpitchGo/middleware"
+	"talentpitchGo/models"
+	"talentpitchGo/repository"
+	"talentpitchGo/validation"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateChallengeValidation(t *testing.T) {
+	body := `{"title":"","description":"d","difficulty":9,"user_id":""}`
+	req := httptest.NewRequest(http.MethodPost, "/challenges", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+	handlers.CreateChallengeHandler(&etagServer{}).ServeHTTP(rr, req)
+
+	// Todos los errores de los campos se devuelven a la vez con 422
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	var response handlers.ValidationErrorResponse
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	assert.Equal(t, validation.Errors{
+		{Field: "title", Code: validation.CODE_REQUIRED, Message: "is required"},
+		{Field: "difficulty", Code: "difficulty", Message: "must be a difficulty level between 1 and 5"},
+		{Field: "user_id", Code: validation.CODE_REQUIRED, Message: "is required"},
+	}, response.Errors)
+}
+
+func TestCreateWithUnknownUser(t *testing.T) {
+	repository.SetUserRepository(&fakeUserRepository{users: map[string]models.User{}})
+
+	// Un user_id desconocido es un error del campo, no un error interno
+	for _, test := range []struct {
+		handler http.HandlerFunc
+		body    string
+	}{
+		{handlers.CreateChallengeHandler(&etagServer{}), `{"title":"Go","description":"d","difficulty":2,"user_id":"missing"}`},
+		{handlers.CreateCompanyHandler(&etagServer{}), `{"name":"Acme","location":"Bogotá","industry":"Tech","user_id":"missing"}`},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(test.body))
+		rr := httptest.NewRecorder()
+		test.handler.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code, test.body)
+		var response handlers.ValidationErrorResponse
+		assert.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+		assert.Equal(t, validation.Errors{
+			{Field: "user_id", Code: validation.CODE_NOT_FOUND, Message: "user not found"},
+		}, response.Errors, test.body)
+	}
+}
+
+// fakeProgramRepository guarda un único programa en memoria
+type fakeProgramRepository struct {
+	repository.ProgramRepository
+	program models.Program
+}
+
+func (f *fakeProgramRepository) GetProgramById(ctx context.Context, id string) (*models.Program, error) {
+	if id != f.program.Id {
+		return nil, repository.ErrNotFound
+	}
+	program := f.program
+	return &program, nil
+}
+
+func TestChangeToUnknownUser(t *testing.T) {
+	repository.SetUserRepository(&fakeUserRepository{users: map[string]models.User{"u1": {Id: "u1"}}})
+	setOwnedResources()
+
+	// Cambiar el dueño a un usuario desconocido con PUT o PATCH es un error del campo, no un error interno
+	for _, test := range ownerChangeRequests("missing") {
+		rr := changeOwner(test.method, test.handler, test.body, "u1")
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code, test.body)
+		var response handlers.ValidationErrorResponse
+		assert.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+		assert.Equal(t, validation.Errors{
+			{Field: "user_id", Code: validation.CODE_NOT_FOUND, Message: "user not found"},
+		}, response.Errors, test.body)
+	}
+}
+
+func TestChangeOwnerRequiresOwnerOrAdmin(t *testing.T) {
+	repository.SetUserRepository(&fakeUserRepository{users: map[string]models.User{
+		"u1": {Id: "u1"},
+		"u2": {Id: "u2"},
+		"a1": {Id: "a1", Role: models.ROLE_ADMIN},
+	}})
+	setOwnedResources()
+
+	for _, test := range ownerChangeRequests("u2") {
+		// Otro usuario no se puede apropiar del recurso
+		rr := changeOwner(test.method, test.handler, test.body, "u2")
+		assert.Equal(t, http.StatusForbidden, rr.Code, test.body)
+	}
+	for _, test := range ownerChangeRequests("missing") {
+		// Un administrador sí puede cambiar el dueño; la solicitud llega a la validación del nuevo dueño
+		rr := changeOwner(test.method, test.handler, test.body, "a1")
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code, test.body)
+	}
+}
+
+// setOwnedResources configura un reto, una empresa y un programa con id c1 del usuario u1
+func setOwnedResources() {
+	repository.SetChallengeRepository(&fakeChallengeRepository{challenge: models.Challenge{Id: "c1", Title: "Go", Description: "d", Difficulty: 2, UserID: "u1", Version: 1}})
+	repository.SetCompanyRepository(&fakeCompanyRepository{company: models.Company{Id: "c1", Name: "Acme", Location: "Bogotá", Industry: "Tech", UserID: "u1", Version: 1}})
+	repository.SetProgramRepository(&fakeProgramRepository{program: models.Program{Id: "c1", Title: "Go", UserID: "u1", Version: 1}})
+}
+
+type ownerChangeRequest struct {
+	method  string
+	handler http.HandlerFunc
+	body    string
+}
+
+// ownerChangeRequests devuelve las solicitudes PUT y PATCH que cambian el dueño de los recursos a userID
+func ownerChangeRequests(userID string) []ownerChangeRequest {
+	s := &etagServer{}
+	return []ownerChangeRequest{
+		{http.MethodPut, handlers.UpdateChallengeHandler(s), `{"title":"Go","description":"d","difficulty":2,"user_id":"` + userID + `"}`},
+		{http.MethodPatch, handlers.PatchChallengeHandler(s), `{"user_id":"` + userID + `"}`},
+		{http.MethodPut, handlers.UpdateCompanyHandler(s), `{"name":"Acme","location":"Bogotá","industry":"Tech","user_id":"` + userID + `"}`},
+		{http.MethodPatch, handlers.PatchCompanyHandler(s), `{"user_id":"` + userID + `"}`},
+		{http.MethodPatch, handlers.PatchProgramHandler(s), `{"user_id":"` + userID + `"}`},
+	}
+}
+
+// changeOwner envía la solicitud autenticada como callerID sobre el recurso c1
+func changeOwner(method string, handler http.HandlerFunc, body string, callerID string) *httptest.ResponseRecorder {
+	req := mux.SetURLVars(httptest.NewRequest(method, "/", strings.NewReader(body)), map[string]string{"id": "c1"})
+	req.Header.Set("If-Match", `"1"`)
+	req.Header.Set("Authorization", userToken(callerID))
+	rr := httptest.NewRecorder()
+	middleware.CheckAuthMiddleware(&etagServer{})(handler).ServeHTTP(rr, req)
+	return rr
+}
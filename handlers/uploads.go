@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"talentpitchGo/audit"      // registro de auditoría
+	"talentpitchGo/imaging"    // validación y reducción de imágenes
+	"talentpitchGo/logging"    // logger de la solicitud
+	"talentpitchGo/models"     // modelos de datos
+	"talentpitchGo/repository" // operaciones de base de datos
+	"talentpitchGo/server"     // configuración del servidor
+	"talentpitchGo/storage"    // almacén de archivos
+
+	"github.com/gorilla/mux"     // enrutador HTTP
+	"github.com/segmentio/ksuid" // para generar nombres de archivo únicos
+)
+
+// Límites de las imágenes subidas
+const (
+	MAX_UPLOAD_SIZE = 5 << 20 // Tamaño máximo del archivo: 5 MB
+	UPLOAD_FIELD    = "file"  // Campo del formulario multipart con el archivo
+	IMAGE_SIZE      = 1024    // Lado máximo de la imagen guardada
+)
+
+// ImageUploadResponse es la respuesta de la subida de una imagen.
+type ImageUploadResponse struct {
+	ImagePath  string            `json:"image_path"` // URL de la imagen, reducida a IMAGE_SIZE
+	Thumbnails map[string]string `json:"thumbnails"` // URL de cada miniatura por su lado en píxeles
+	Version    int               `json:"version"`    // Versión del recurso tras la subida
+}
+
+// readUpload es una función que lee el archivo del formulario multipart, limitando su tamaño a maxSize bytes.
+// Devuelve el contenido y el nombre del archivo que envió el cliente.
+func readUpload(w http.ResponseWriter, r *http.Request, maxSize int64) ([]byte, string, bool) {
+	tooLargeMessage := "File too large: the maximum size is " + strconv.FormatInt(maxSize>>20, 10) + " MB"
+	// Limitar el cuerpo completo, con margen para las cabeceras del formulario
+	r.Body = http.MaxBytesReader(w, r.Body, maxSize+64<<10)
+	if err := r.ParseMultipartForm(maxSize); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, tooLargeMessage, http.StatusRequestEntityTooLarge)
+		} else {
+			http.Error(w, "Invalid multipart form: "+err.Error(), http.StatusBadRequest)
+		}
+		return nil, "", false
+	}
+	// Eliminar los archivos temporales del formulario
+	defer r.MultipartForm.RemoveAll()
+	file, header, err := r.FormFile(UPLOAD_FIELD)
+	if err != nil {
+		http.Error(w, "Missing file field \""+UPLOAD_FIELD+"\"", http.StatusBadRequest)
+		return nil, "", false
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, maxSize+1))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, "", false
+	}
+	if int64(len(data)) > maxSize {
+		http.Error(w, tooLargeMessage, http.StatusRequestEntityTooLarge)
+		return nil, "", false
+	}
+	return data, header.Filename, true
+}
+
+// storeImage es una función que valida la imagen subida y guarda la imagen y sus miniaturas bajo el prefijo dado.
+// Devuelve las URL y las claves guardadas, para eliminarlas si luego falla la actualización del recurso.
+func storeImage(w http.ResponseWriter, r *http.Request, prefix string) (*ImageUploadResponse, []string, bool) {
+	data, _, ok := readUpload(w, r, MAX_UPLOAD_SIZE)
+	if !ok {
+		return nil, nil, false
+	}
+	// Detectar el tipo por el contenido y decodificar la imagen
+	img, contentType, err := imaging.Decode(data)
+	if err != nil {
+		switch {
+		case errors.Is(err, imaging.ErrUnsupportedType):
+			http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+		case errors.Is(err, imaging.ErrTooLarge):
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		default:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+		return nil, nil, false
+	}
+	// Cada subida usa un nombre nuevo para que las URL anteriores no queden en caché con otro contenido
+	name := prefix + "/" + ksuid.New().String()
+	extension := imaging.Extension(contentType)
+	response := &ImageUploadResponse{Thumbnails: map[string]string{}}
+	var keys []string
+	// put es una función que reduce, codifica y guarda una versión de la imagen
+	put := func(key string, size int) (string, error) {
+		encoded, err := imaging.Encode(imaging.Fit(img, size), contentType)
+		if err != nil {
+			return "", err
+		}
+		url, err := storage.Put(r.Context(), key, imaging.OutputType(contentType), encoded)
+		if err != nil {
+			return "", err
+		}
+		keys = append(keys, key)
+		return url, nil
+	}
+	// Guardar la imagen y sus miniaturas
+	response.ImagePath, err = put(name+extension, IMAGE_SIZE)
+	for _, size := range storage.THUMBNAIL_SIZES {
+		if err != nil {
+			break
+		}
+		response.Thumbnails[strconv.Itoa(size)], err = put(storage.ThumbnailKey(name+extension, size), size)
+	}
+	if err != nil {
+		// No dejar versiones sueltas de una subida incompleta
+		deleteBlobs(r.Context(), keys)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, nil, false
+	}
+	return response, keys, true
+}
+
+// deleteBlobs es una función que elimina archivos del almacén, como los de una subida que no se completó.
+func deleteBlobs(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		if err := storage.Delete(ctx, key); err != nil {
+			logging.FromContext(ctx).Error("error deleting uploaded file", "key", key, "error", err)
+		}
+	}
+}
+
+// UploadCompanyLogoHandler es el controlador que sube el logo de una empresa y asigna su image_path.
+// Solo lo pueden usar el dueño de la empresa y los administradores.
+func UploadCompanyLogoHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Obtener el ID de la URL
+		id := mux.Vars(r)["id"]
+		// Obtener la empresa actual
+		before, err := repository.GetCompanyById(r.Context(), id)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				http.Error(w, "Empresa no encontrada", http.StatusNotFound)
+			} else {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		// Verificar que el usuario pueda cambiar la imagen
+		if !isOwnerOrAdmin(w, r, before.UserID, "Only the owner of the company can change its logo") {
+			return
+		}
+		// Validar la versión esperada con la cabecera If-Match
+		version, ok := checkIfMatch(s, w, r, before.Version)
+		if !ok {
+			return
+		}
+		// Guardar la imagen y sus miniaturas
+		response, keys, ok := storeImage(w, r, storage.CompanyLogoPrefix(id))
+		if !ok {
+			return
+		}
+		// Asignar la URL de la imagen a la empresa
+		company := *before
+		company.ImagePath = response.ImagePath
+		company.Version = version
+		if err = repository.PatchCompany(r.Context(), id, &company, []string{"image_path"}); err != nil {
+			deleteBlobs(r.Context(), keys)
+			writeImagePathError(w, err, "Empresa no encontrada")
+			return
+		}
+		// Eliminar la imagen anterior y sus miniaturas, que ya no usa ningún recurso
+		deleteBlobs(r.Context(), storage.ImageKeys(before.ImagePath, storage.CompanyLogoPrefix(id)))
+		// Registrar el cambio en el registro de auditoría
+		audit.Record(r, models.AUDIT_UPDATE, models.ENTITY_COMPANY, id, before, &company)
+		response.Version = company.Version
+		// Retornar la respuesta
+		w.Header().Set("Content-Type", "application/json")
+		setETag(w, company.Version)
+		// Codificar la respuesta
+		json.NewEncoder(w).Encode(response)
+	}
+}
+
+// UploadUserAvatarHandler es el controlador que sube el avatar de un usuario y asigna su avatar_path.
+// Solo lo pueden usar el propio usuario y los administradores.
+func UploadUserAvatarHandler(s server.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Obtener el ID de la URL
+		id := mux.Vars(r)["id"]
+		// Obtener el usuario actual
+		before, err := repository.GetUserById(r.Context(), id)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				http.Error(w, "Usuario no encontrado", http.StatusNotFound)
+			} else {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		// Verificar que el usuario pueda cambiar la imagen
+		if !isOwnerOrAdmin(w, r, before.Id, "Only the user can change their avatar") {
+			return
+		}
+		// Validar la versión esperada con la cabecera If-Match
+		version, ok := checkIfMatch(s, w, r, before.Version)
+		if !ok {
+			return
+		}
+		// Guardar la imagen y sus miniaturas
+		response, keys, ok := storeImage(w, r, storage.UserAvatarPrefix(id))
+		if !ok {
+			return
+		}
+		// Asignar la URL de la imagen al usuario
+		user := *before
+		user.AvatarPath = response.ImagePath
+		user.Version = version
+		if err = repository.PatchUser(r.Context(), id, &user, []string{"avatar_path"}); err != nil {
+			deleteBlobs(r.Context(), keys)
+			writeImagePathError(w, err, "Usuario no encontrado")
+			return
+		}
+		// Eliminar la imagen anterior y sus miniaturas, que ya no usa ningún recurso
+		deleteBlobs(r.Context(), storage.ImageKeys(before.AvatarPath, storage.UserAvatarPrefix(id)))
+		// Registrar el cambio en el registro de auditoría
+		audit.Record(r, models.AUDIT_UPDATE, models.ENTITY_USER, id, before, &user)
+		response.Version = user.Version
+		// Retornar la respuesta
+		w.Header().Set("Content-Type", "application/json")
+		setETag(w, user.Version)
+		// Codificar la respuesta
+		json.NewEncoder(w).Encode(response)
+	}
+}
+
+// writeImagePathError es una función que responde el error de la actualización de la URL de la imagen.
+func writeImagePathError(w http.ResponseWriter, err error, notFound string) {
+	switch {
+	case errors.Is(err, repository.ErrVersionConflict):
+		// El recurso cambió desde la versión indicada en If-Match
+		http.Error(w, "Precondition Failed: the resource was modified", http.StatusPreconditionFailed)
+	case errors.Is(err, repository.ErrNotFound):
+		http.Error(w, notFound, http.StatusNotFound)
+	default:
+		// Responder 422 con los errores de los campos, o un error interno del servidor
+		if !writeValidationError(w, err) {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	}
+}
@@ -3,14 +3,16 @@ package handlers
 
 import (
 	"encoding/json"
-	"log"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
-	"fmt"
 	"errors"
 
+	"talentpitchGo/logging"    // logger de la solicitud
+	"talentpitchGo/middleware" // cabecera de autorización
+	"talentpitchGo/audit"      // registro de auditoría
+	"talentpitchGo/metrics"    // métricas de Prometheus
 	"talentpitchGo/models"     // modelos de datos
 	"talentpitchGo/repository" // operaciones de base de datos
 	"talentpitchGo/server"     // configuración del servidor
@@ -22,21 +24,25 @@ import (
 )
 
 // HASH_COST es la complejidad del algoritmo de hash bcrypt.
+// TOKEN_TTL es la duración del token de acceso.
+// MFA_TOKEN_TTL es la duración del token de desafío 2FA devuelto por el login.
 const (
 		HASH_COST = 8
+		TOKEN_TTL = time.Hour * 24
+		MFA_TOKEN_TTL = time.Minute * 5
 )
 
 // SingUpRequest es la estructura de los datos necesarios para registrar un nuevo usuario.
 type SingUpRequest struct {
-	Email    string `json:"email"`
-	Fullname string `json:"fullname"`
-	Password string `json:"password"`
+	Email    string `json:"email" validate:"required,email,max=255"`
+	Fullname string `json:"fullname" validate:"required,max=255"`
+	Password string `json:"password" validate:"required,min=8,max=72"`
 }
 
 type UpdateUserRequest struct {
 	Id       string `json:"id"`
-	Email    string `json:"email"`
-	Fullname string `json:"fullname"`
+	Email    string `json:"email" validate:"required,email,max=255"`
+	Fullname string `json:"fullname" validate:"required,max=255"`
 }
 
 // LoginResponse es la estructura de la respuesta del login
@@ -44,16 +50,24 @@ type LoginResponse struct {
 	Token string `json:"token"`
 }
 
+// MFAChallengeResponse es la estructura de la respuesta del login cuando el usuario tiene 2FA activo
+type MFAChallengeResponse struct {
+	MFARequired bool   `json:"mfa_required"`
+	MFAToken    string `json:"mfa_token"`
+}
+
 // SingUpLoginRequest es la estructura de los datos necesarios para iniciar sesión.
 type SingUpLoginRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email    string `json:"email" validate:"required"`
+	Password string `json:"password" validate:"required"`
 }
 
 // SingUpResponse es la estructura de la respuesta del registro
 type SingUpResponse struct {
 	Id 	 string  `json:"id"`
 	Email    string `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
 // SignUpHandler es el controlador para la ruta de registro
@@ -64,11 +78,15 @@ func SignUpHandler(s server.Server) http.HandlerFunc {
 		var request SingUpRequest
 		
 		// Decodificar el cuerpo de la solicitud
-		err := json.NewDecoder(r.Body).Decode(&request)
+		err := decodeJSON(w, r, &request)
 		// Verificar si hubo un error decodificando el cuerpo de la solicitud
 		if err != nil {
-			// Retornar un error de solicitud incorrecta
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			// Retornar el error de decodificación
+			writeDecodeError(w, err)
+			return
+		}
+		// Validar los campos de la solicitud
+		if !validateRequest(w, &request) {
 			return
 		}
 
@@ -106,12 +124,18 @@ func SignUpHandler(s server.Server) http.HandlerFunc {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
+		// Registrar el alta en el registro de auditoría
+		audit.Record(r, models.AUDIT_CREATE, models.ENTITY_USER, user.Id, nil, &user)
+		metrics.SIGNUPS.Inc()
 		// Retornar la respuesta
 		w.Header().Set("Content-Type", "application/json")
+		setETag(w, user.Version)
 		// Codificar la respuesta
 		json.NewEncoder(w).Encode(SingUpResponse{
 			Id: user.Id, 
 			Email: user.Email,
+			CreatedAt: user.CreatedAt,
+			UpdatedAt: user.UpdatedAt,
 		})
 }
 }
@@ -123,36 +147,73 @@ func UpdateUserHandler(s server.Server) http.HandlerFunc {
 		// Decodificar el cuerpo de la solicitud
 		var request UpdateUserRequest
 		// Decodificar el cuerpo de la solicitud
-		err := json.NewDecoder(r.Body).Decode(&request)
+		err := decodeJSON(w, r, &request)
 		// Verificar si hubo un error decodificando el cuerpo de la solicitud
 		if err != nil {
-			// Retornar un error de solicitud incorrecta
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			// Retornar el error de decodificación
+			writeDecodeError(w, err)
+			return
+		}
+		// Validar los campos de la solicitud
+		if !validateRequest(w, &request) {
 			return
 		}
 		// Obtener el ID del usuario de la URL
 		id := mux.Vars(r)["id"]
+
+		// Obtener el usuario antes del cambio para el registro de auditoría
+		before, err := repository.GetUserById(r.Context(), id)
+		if err != nil {
+			// Si hay un error, verifica si es porque el usuario no fue encontrado
+			if errors.Is(err, repository.ErrNotFound) {
+				http.Error(w, "Usuario no encontrado", http.StatusNotFound)
+			} else {
+				// Si es otro error, maneja ese error
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		// Validar la versión esperada con la cabecera If-Match
+		version, ok := checkIfMatch(s, w, r, before.Version)
+		if !ok {
+			return
+		}
 		
 		// Crear una nueva estructura de usuario
 		var user = models.User{
 			Id:       request.Id,
 			Fullname: request.Fullname,
 			Email:    request.Email,
+			Version:  version,
 		}
 		// Insertar el usuario en la base de datos
 	    err = repository.UpdateUser(r.Context(), id, &user)
 		// Verificar si hubo un error insertando el usuario
 		if err != nil {
-			// Retornar un error interno del servidor
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			if errors.Is(err, repository.ErrVersionConflict) {
+				// El recurso cambió desde la versión indicada en If-Match
+				http.Error(w, "Precondition Failed: the resource was modified", http.StatusPreconditionFailed)
+			} else if errors.Is(err, repository.ErrNotFound) {
+				http.Error(w, "Usuario no encontrado", http.StatusNotFound)
+			} else {
+				// Retornar un error interno del servidor
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
 			return
 		}
+		// Registrar el cambio en el registro de auditoría
+		after := *before
+		after.Fullname, after.Email, after.UpdatedAt, after.Version = user.Fullname, user.Email, user.UpdatedAt, user.Version
+		audit.Record(r, models.AUDIT_UPDATE, models.ENTITY_USER, id, before, &after)
 		// Retornar la respuesta
 		w.Header().Set("Content-Type", "application/json")
+		setETag(w, user.Version)
 		// Codificar la respuesta
 		json.NewEncoder(w).Encode(SingUpResponse{
 			Id: user.Id,
 			Email: user.Email,
+			CreatedAt: user.CreatedAt,
+			UpdatedAt: user.UpdatedAt,
 		})
 	}
 }
@@ -164,12 +225,34 @@ func DeleteUserHandler(s server.Server) http.HandlerFunc {
 		// Obtener el ID del usuario de la URL
 		id := mux.Vars(r)["id"]
 
-		_, err := repository.GetUserById(r.Context(), id)
-		// Verificar si hubo un error obteniendo el usuario
-		// Verificar si hubo un error obteniendo el usuario
+		// Obtener el usuario antes de eliminarlo para el registro de auditoría
+		before, err := repository.GetUserById(r.Context(), id)
+		if err == nil {
+			// Validar la versión esperada con la cabecera If-Match
+			version, ok := checkIfMatch(s, w, r, before.Version)
+			if !ok {
+				return
+			}
+			// Eliminar lógicamente el usuario y sus retos y empresas en una transacción, y guardar en el registro
+			// de auditoría el estado que se eliminó aunque haya cambiado desde la primera lectura
+			err = repository.WithTx(r.Context(), func(repos repository.Repositories) error {
+				current, err := repos.GetUserById(r.Context(), id)
+				if err != nil {
+					return err
+				}
+				if err := repos.DeleteUser(r.Context(), id, version); err != nil {
+					return err
+				}
+				before = current
+				return nil
+			})
+		}
+		// Verificar si hubo un error eliminando el usuario
 		if err != nil {
-			// Si hay un error, verifica si es porque el usuario no fue encontrado
-			if errors.Is(err, fmt.Errorf("no user found with id %s", id)) {
+			if errors.Is(err, repository.ErrVersionConflict) {
+				// El recurso cambió desde la versión indicada en If-Match
+				http.Error(w, "Precondition Failed: the resource was modified", http.StatusPreconditionFailed)
+			} else if errors.Is(err, repository.ErrNotFound) {
 				http.Error(w, "Usuario no encontrado", http.StatusNotFound)
 			} else {
 				// Si es otro error, maneja ese error
@@ -177,14 +260,8 @@ func DeleteUserHandler(s server.Server) http.HandlerFunc {
 			}
 			return
 		}
-		// Eliminar el usuario de la base de datos
-		err = repository.DeleteUser(r.Context(), id)
-		// Verificar si hubo un error eliminando el usuario
-		if err != nil {
-			// Retornar un error interno del servidor
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
+		// Registrar la eliminación en el registro de auditoría
+		audit.Record(r, models.AUDIT_DELETE, models.ENTITY_USER, id, before, nil)
 		// Retornar la respuesta
 		w.Header().Set("Content-Type", "application/json")
 		// Codificar la respuesta
@@ -201,7 +278,7 @@ func GetUsersHandler(s server.Server) http.HandlerFunc {
 		// Convertir page a int
 		pageNum, err := strconv.Atoi(page)
 		if err != nil {
-			log.Print("Error converting page to int")
+			logging.FromContext(r.Context()).Warn("invalid page parameter", "page", page, "error", err)
 			// Manejar el error
 		}
 		// Obtener el tamaño de la página de la URL
@@ -209,7 +286,7 @@ func GetUsersHandler(s server.Server) http.HandlerFunc {
 		// Convertir pageSize a int
 		pageSizeNum, err := strconv.Atoi(pageSize)
 		if err != nil {
-			log.Print("Error converting pageSize to int")
+			logging.FromContext(r.Context()).Warn("invalid pageSize parameter", "pageSize", pageSize, "error", err)
 			// Manejar el error
 		}
 		// Obtener la lista de usuarios
@@ -240,11 +317,15 @@ func LoginHandler(s server.Server) http.HandlerFunc {
 		// Decodificar el cuerpo de la solicitud
 		var request SingUpLoginRequest
 		// Decodificar el cuerpo de la solicitud
-		err := json.NewDecoder(r.Body).Decode(&request)
+		err := decodeJSON(w, r, &request)
 		// Verificar si hubo un error decodificando el cuerpo de la solicitud
 		if err != nil {
-			// Retornar un error de solicitud incorrecta
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			// Retornar el error de decodificación
+			writeDecodeError(w, err)
+			return
+		}
+		// Validar los campos de la solicitud
+		if !validateRequest(w, &request) {
 			return
 		}
 		// Obtener el usuario por email
@@ -269,18 +350,25 @@ func LoginHandler(s server.Server) http.HandlerFunc {
 			http.Error(w, "Invalid credentials", http.StatusUnauthorized)
 			return
 		}
-		// Crear una nueva estructura de claims
-		claims := models.AppClaims{
-			UserId: user.Id,
-            StandardClaims : jwt.StandardClaims{
-				ExpiresAt: time.Now().Add(time.Hour * 24).Unix(),
-			},
-		}
-		// Crear un nuevo token con los claims
-		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-		// Firmar el token con el secreto JWT
-		tokenString, err := token.SignedString([]byte(s.Config().JWTSecret))
-		// Verificar si hubo un error firmando el token
+		// Retornar el token de acceso o el desafío 2FA
+		writeLoginResponse(s, w, r, user.Id, metrics.LOGIN_PASSWORD)
+	}
+}
+
+// writeLoginResponse es una función que responde a un inicio de sesión correcto con el token de acceso,
+// o con un token de desafío si el usuario tiene 2FA activo. method es el método de inicio de sesión que se cuenta en las métricas.
+func writeLoginResponse(s server.Server, w http.ResponseWriter, r *http.Request, userId string, method string) {
+	// Obtener la configuración 2FA del usuario
+	mfaConfig, err := repository.GetUserMFA(r.Context(), userId)
+	if err != nil {
+		// Retornar un error interno del servidor
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	// Si el usuario tiene 2FA activo, devolver un token de desafío en lugar del token real
+	if mfaConfig != nil && mfaConfig.Enabled {
+		// Firmar el token de desafío
+		challengeToken, err := signToken(s, userId, MFA_TOKEN_TTL, true)
 		if err != nil {
 			// Retornar un error interno del servidor
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -289,37 +377,98 @@ func LoginHandler(s server.Server) http.HandlerFunc {
 		// Retornar la respuesta
 		w.Header().Set("Content-Type", "application/json")
 		// Codificar la respuesta
-		json.NewEncoder(w).Encode(LoginResponse{
-			Token: tokenString,
+		json.NewEncoder(w).Encode(MFAChallengeResponse{
+			MFARequired: true,
+			MFAToken:    challengeToken,
 		})
+		return
+	}
+	// Firmar el token con el secreto JWT
+	tokenString, err := signToken(s, userId, TOKEN_TTL, false)
+	// Verificar si hubo un error firmando el token
+	if err != nil {
+		// Retornar un error interno del servidor
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	// Contar el inicio de sesión
+	metrics.LOGINS.WithLabelValues(method).Inc()
+	// Retornar la respuesta
+	w.Header().Set("Content-Type", "application/json")
+	// Codificar la respuesta
+	json.NewEncoder(w).Encode(LoginResponse{
+		Token: tokenString,
+	})
+}
+
+// signToken es una función que firma un token JWT para el usuario dado.
+// Si mfaPending es verdadero el token solo sirve para completar el inicio de sesión con 2FA.
+func signToken(s server.Server, userId string, ttl time.Duration, mfaPending bool) (string, error) {
+	// Crear una nueva estructura de claims
+	claims := models.AppClaims{
+		UserId:     userId,
+		MFAPending: mfaPending,
+		StandardClaims: jwt.StandardClaims{
+			ExpiresAt: time.Now().Add(ttl).Unix(),
+		},
+	}
+	// Crear un nuevo token con los claims
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	// Firmar el token con el secreto JWT
+	return token.SignedString([]byte(s.Config().JWTSecret))
+}
+
+// parseToken es una función que valida un token JWT y devuelve sus claims.
+func parseToken(s server.Server, tokenString string) (*models.AppClaims, error) {
+	// Parsear el token con los claims
+	token, err := jwt.ParseWithClaims(strings.TrimSpace(tokenString), &models.AppClaims{}, func(token *jwt.Token) (interface{}, error) {
+		// Retornar el secreto JWT de la configuración del servidor
+		return []byte(s.Config().JWTSecret), nil
+	})
+	// Verificar si hubo un error parseando el token
+	if err != nil {
+		return nil, err
+	}
+	// Obtener los claims del token
+	claims, ok := token.Claims.(*models.AppClaims)
+	// Verificar si los claims son válidos
+	if !ok || !token.Valid {
+		return nil, errors.New("invalid token")
 	}
+	return claims, nil
+}
+
+// requestClaims es una función que obtiene los claims del token de acceso de la solicitud,
+// rechazando los tokens de desafío 2FA.
+func requestClaims(s server.Server, r *http.Request) (*models.AppClaims, error) {
+	// Obtener el token de la cabecera "Authorization: Bearer"
+	tokenString, ok := middleware.BearerToken(r)
+	if !ok {
+		return nil, errors.New("missing bearer token")
+	}
+	claims, err := parseToken(s, tokenString)
+	if err != nil {
+		return nil, err
+	}
+	// Verificar que no sea un token de desafío 2FA
+	if claims.MFAPending {
+		return nil, errors.New("mfa verification required")
+	}
+	return claims, nil
 }
 
 // MeHandler es el controlador para la ruta de usuario
 func MeHandler(s server.Server) http.HandlerFunc {
 	// Retornar la función del controlador
 	return func(w http.ResponseWriter, r *http.Request) {
-		// Obtener el token de la cabecera de autorización
-		tokenString := strings.TrimSpace(r.Header.Get("Authorization"))
-		// Parsear el token con los claims
-        token, err := jwt.ParseWithClaims(tokenString, &models.AppClaims{}, func(token *jwt.Token) (interface{}, error) {
-			// Retornar el secreto JWT de la configuración del servidor
-			return []byte(s.Config().JWTSecret), nil
-		})
+		// Obtener los claims del token de la cabecera de autorización
+		claims, err := requestClaims(s, r)
 		// Verificar si hubo un error parseando el token
 		if err != nil {
 			// Retornar un error de no autorizado
 			http.Error(w, err.Error(), http.StatusUnauthorized)
 			return
 		}
-		// Obtener los claims del token
-		claims, ok := token.Claims.(*models.AppClaims)
-		// Verificar si los claims son válidos
-		if !ok && !token.Valid {
-			// Retornar un error de token inválido
-			http.Error(w, "Invalid token", http.StatusUnauthorized)
-			return
-		}
 		// Obtener el usuario por su ID
 		user, err := repository.GetUserById(r.Context(), claims.UserId)
 		if err != nil {
@@ -327,9 +476,83 @@ func MeHandler(s server.Server) http.HandlerFunc {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
+		// Responder 304 si el cliente ya tiene esta versión
+		if notModified(w, r, user.Version) {
+			return
+		}
 		// Retornar la respuesta
 		w.Header().Set("Content-Type", "application/json")
+		setETag(w, user.Version)
 		// Codificar la respuesta
 		json.NewEncoder(w).Encode(user)
 	}
-}
\ No newline at end of file
+}
+
+// PatchUserHandler es el controlador que actualiza parcialmente el usuario con JSON Merge Patch (RFC 7396).
+// Solo se actualizan en la base de datos las columnas que cambian.
+func PatchUserHandler(s server.Server) http.HandlerFunc {
+	// Retornar la función del controlador
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Obtener el ID de la URL
+		id := mux.Vars(r)["id"]
+		// Obtener el usuario actual
+		before, err := repository.GetUserById(r.Context(), id)
+		if err != nil {
+			// Si hay un error, verifica si es porque no fue encontrado
+			if errors.Is(err, repository.ErrNotFound) {
+				http.Error(w, "Usuario no encontrado", http.StatusNotFound)
+			} else {
+				// Si es otro error, maneja ese error
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		// Validar la versión esperada con la cabecera If-Match
+		version, ok := checkIfMatch(s, w, r, before.Version)
+		if !ok {
+			return
+		}
+		// Aplicar el parche sobre el usuario actual
+		var user models.User
+		fields, err := applyMergePatch(r, before, &user, USER_PATCH_FIELDS)
+		if err != nil {
+			writePatchError(w, err)
+			return
+		}
+		if len(fields) > 0 {
+			// Validar el resultado combinado
+			if err := repository.ValidateUser(&user); err != nil {
+				// Responder 422 con los errores de los campos
+				if !writeValidationError(w, err) {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+				}
+				return
+			}
+			// Actualizar solo las columnas modificadas
+			user.Version = version
+			err = repository.PatchUser(r.Context(), id, &user, fields)
+			if err != nil {
+				if errors.Is(err, repository.ErrVersionConflict) {
+					// El recurso cambió desde la versión indicada en If-Match
+					http.Error(w, "Precondition Failed: the resource was modified", http.StatusPreconditionFailed)
+				} else if errors.Is(err, repository.ErrNotFound) {
+					http.Error(w, "Usuario no encontrado", http.StatusNotFound)
+				} else {
+					// Retornar un error interno del servidor
+					http.Error(w, err.Error(), http.StatusInternalServerError)
+				}
+				return
+			}
+			// Registrar el cambio en el registro de auditoría
+			audit.Record(r, models.AUDIT_UPDATE, models.ENTITY_USER, id, before, &user)
+		} else {
+			// Sin cambios no se toca la base de datos
+			user = *before
+		}
+		// Retornar la respuesta
+		w.Header().Set("Content-Type", "application/json")
+		setETag(w, user.Version)
+		// Codificar el usuario actualizado
+		json.NewEncoder(w).Encode(user)
+	}
+}
@@ -0,0 +1,68 @@
+// El paquete handlers contiene los controladores de las rutas HTTP.
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"talentpitchGo/logging"    // logger de la solicitud
+	"talentpitchGo/repository" // operaciones de base de datos
+	"talentpitchGo/validation" // validación de las solicitudes
+)
+
+// ValidationErrorResponse es la estructura de la respuesta cuando los campos de la solicitud no son válidos
+type ValidationErrorResponse struct {
+	Message string            `json:"message"`
+	Errors  validation.Errors `json:"errors"`
+}
+
+// validateRequest es una función que valida los campos de la solicitud con las reglas de su etiqueta validate.
+// Si no son válidos responde 422 con todos los errores y devuelve falso.
+func validateRequest(w http.ResponseWriter, request interface{}) bool {
+	return !writeValidationError(w, validation.Validate(request))
+}
+
+// writeValidationError es una función que responde 422 con los errores de los campos si err es un error de validación.
+// Devuelve falso si err no es un error de validación, para que el controlador lo maneje.
+func writeValidationError(w http.ResponseWriter, err error) bool {
+	var errs validation.Errors
+	if !errors.As(err, &errs) {
+		return false
+	}
+	// Retornar la respuesta
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	// Codificar los errores
+	json.NewEncoder(w).Encode(ValidationErrorResponse{
+		Message: "validation failed",
+		Errors:  errs,
+	})
+	return true
+}
+
+// checkUserExists es una función que verifica que exista el usuario del campo user_id de la solicitud.
+// Si no existe responde 422 con el error del campo; si la consulta falla responde 500. En ambos casos devuelve falso.
+func checkUserExists(w http.ResponseWriter, r *http.Request, userID string) bool {
+	_, err := repository.GetUserById(r.Context(), userID)
+	if err == nil {
+		return true
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		writeValidationError(w, validation.Errors{{Field: "user_id", Code: validation.CODE_NOT_FOUND, Message: "user not found"}})
+		return false
+	}
+	// No exponer el error de la base de datos en la respuesta
+	logging.FromContext(r.Context()).Error("error getting user", "user_id", userID, "error", err)
+	http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
+	return false
+}
+
+// checkOwnerChange es una función que verifica un cambio del dueño de un recurso. Solo el dueño actual o un
+// administrador pueden transferirlo, y el nuevo dueño debe existir. Si el dueño no cambia devuelve verdadero.
+func checkOwnerChange(w http.ResponseWriter, r *http.Request, currentOwner string, newOwner string, message string) bool {
+	if newOwner == currentOwner {
+		return true
+	}
+	return isOwnerOrAdmin(w, r, currentOwner, message) && checkUserExists(w, r, newOwner)
+}
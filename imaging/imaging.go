@@ -0,0 +1,142 @@
+// El paquete imaging valida las imágenes subidas y genera sus versiones reducidas.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	_ "image/gif" // registra el decodificador de GIF
+	"image/jpeg"
+	"image/png"
+	"net/http"
+)
+
+// Tipos de imagen aceptados, detectados por el contenido y no por la extensión ni la cabecera del cliente
+const (
+	TYPE_PNG  = "image/png"
+	TYPE_JPEG = "image/jpeg"
+	TYPE_GIF  = "image/gif"
+)
+
+// MAX_PIXELS es la cantidad máxima de píxeles de una imagen, para no decodificar imágenes enormes muy comprimidas
+const MAX_PIXELS = 40_000_000
+
+// JPEG_QUALITY es la calidad de las imágenes JPEG generadas
+const JPEG_QUALITY = 85
+
+var (
+	// ErrUnsupportedType es el error que se devuelve cuando el contenido no es una imagen aceptada
+	ErrUnsupportedType = errors.New("unsupported image type: only PNG, JPEG and GIF are accepted")
+	// ErrTooLarge es el error que se devuelve cuando la imagen supera MAX_PIXELS
+	ErrTooLarge = errors.New("image dimensions are too large")
+	// ErrInvalidImage es el error que se devuelve cuando el contenido no se puede decodificar
+	ErrInvalidImage = errors.New("invalid image")
+)
+
+// extensions son las extensiones de los archivos generados por tipo
+var extensions = map[string]string{TYPE_PNG: ".png", TYPE_JPEG: ".jpg", TYPE_GIF: ".png"}
+
+// Sniff es una función que detecta el tipo de la imagen a partir de sus primeros bytes.
+func Sniff(data []byte) (string, error) {
+	contentType := http.DetectContentType(data)
+	if _, ok := extensions[contentType]; !ok {
+		return "", ErrUnsupportedType
+	}
+	return contentType, nil
+}
+
+// Decode es una función que detecta el tipo y decodifica la imagen, verificando antes su tamaño.
+func Decode(data []byte) (image.Image, string, error) {
+	contentType, err := Sniff(data)
+	if err != nil {
+		return nil, "", err
+	}
+	// Leer solo la cabecera para rechazar las imágenes enormes sin reservar su memoria
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", ErrInvalidImage
+	}
+	if config.Width <= 0 || config.Height <= 0 {
+		return nil, "", ErrInvalidImage
+	}
+	if config.Width*config.Height > MAX_PIXELS {
+		return nil, "", ErrTooLarge
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", ErrInvalidImage
+	}
+	return img, contentType, nil
+}
+
+// Extension es una función que devuelve la extensión del archivo que genera Encode para el tipo dado.
+func Extension(contentType string) string {
+	return extensions[contentType]
+}
+
+// OutputType es una función que devuelve el tipo de la imagen que genera Encode: los GIF se convierten a PNG.
+func OutputType(contentType string) string {
+	if contentType == TYPE_JPEG {
+		return TYPE_JPEG
+	}
+	return TYPE_PNG
+}
+
+// Encode es una función que codifica la imagen en JPEG si el original es JPEG, y en PNG en otro caso.
+// Al volver a codificar se descartan los metadatos del original, como la ubicación EXIF.
+func Encode(img image.Image, contentType string) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	if OutputType(contentType) == TYPE_JPEG {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: JPEG_QUALITY})
+	} else {
+		err = png.Encode(&buf, img)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Fit es una función que reduce la imagen para que quepa en un cuadrado de size píxeles, conservando la proporción.
+// Las imágenes más pequeñas no se amplían. Cada píxel nuevo es el promedio de los píxeles que cubre.
+func Fit(img image.Image, size int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= size && height <= size {
+		return img
+	}
+	// Calcular las dimensiones nuevas según el lado mayor
+	newWidth, newHeight := size, height*size/width
+	if height > width {
+		newWidth, newHeight = width*size/height, size
+	}
+	if newWidth < 1 {
+		newWidth = 1
+	}
+	if newHeight < 1 {
+		newHeight = 1
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, newWidth, newHeight))
+	for y := 0; y < newHeight; y++ {
+		// Filas del original que cubre la fila nueva
+		y0 := bounds.Min.Y + y*height/newHeight
+		y1 := bounds.Min.Y + (y+1)*height/newHeight
+		for x := 0; x < newWidth; x++ {
+			// Columnas del original que cubre la columna nueva
+			x0 := bounds.Min.X + x*width/newWidth
+			x1 := bounds.Min.X + (x+1)*width/newWidth
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					// Sumar los colores premultiplicados para no oscurecer los bordes transparentes
+					cr, cg, cb, ca := img.At(sx, sy).RGBA()
+					r, g, b, a, n = r+uint64(cr), g+uint64(cg), b+uint64(cb), a+uint64(ca), n+1
+				}
+			}
+			dst.Set(x, y, color.RGBA64{R: uint16(r / n), G: uint16(g / n), B: uint16(b / n), A: uint16(a / n)})
+		}
+	}
+	return dst
+}
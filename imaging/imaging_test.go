@@ -0,0 +1,57 @@
+package imaging_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"talentpitchGo/imaging"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// pngImage genera una imagen PNG del tamaño dado, negra a la izquierda y blanca a la derecha
+func pngImage(width, height int) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if x >= width/2 {
+				img.Set(x, y, color.White)
+			} else {
+				img.Set(x, y, color.Black)
+			}
+		}
+	}
+	var buf bytes.Buffer
+	png.Encode(&buf, img)
+	return buf.Bytes()
+}
+
+func TestDecodeAndFit(t *testing.T) {
+	img, contentType, err := imaging.Decode(pngImage(400, 200))
+	assert.NoError(t, err)
+	assert.Equal(t, imaging.TYPE_PNG, contentType)
+
+	// La reducción conserva la proporción y promedia los píxeles
+	thumb := imaging.Fit(img, 100)
+	assert.Equal(t, image.Rect(0, 0, 100, 50), thumb.Bounds())
+	r, _, _, _ := thumb.At(10, 10).RGBA()
+	assert.Equal(t, uint32(0), r)
+	r, _, _, _ = thumb.At(90, 10).RGBA()
+	assert.Equal(t, uint32(0xffff), r)
+
+	// Las imágenes pequeñas no se amplían
+	assert.Equal(t, img.Bounds(), imaging.Fit(img, 1000).Bounds())
+}
+
+func TestDecodeRejects(t *testing.T) {
+	// El tipo se detecta por el contenido
+	_, _, err := imaging.Decode([]byte("<svg xmlns=\"http://www.w3.org/2000/svg\"></svg>"))
+	assert.ErrorIs(t, err, imaging.ErrUnsupportedType)
+
+	// Una cabecera PNG truncada no es una imagen válida
+	_, _, err = imaging.Decode(pngImage(10, 10)[:40])
+	assert.ErrorIs(t, err, imaging.ErrInvalidImage)
+}
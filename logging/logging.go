@@ -0,0 +1,52 @@
+// El paquete logging configura el logger estructurado (log/slog) de la aplicación y lo transporta en el contexto
+// de cada solicitud, para que los controladores y el repositorio registren los mensajes con el mismo ID de solicitud.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// Formatos de salida del logger
+const (
+	FORMAT_JSON = "json"
+	FORMAT_TEXT = "text"
+)
+
+// loggerKey es la clave del logger en el contexto
+type loggerKey struct{}
+
+// New es una función que crea un logger con el formato (json por defecto, o text) y el nivel
+// (debug, info por defecto, warn o error) dados.
+func New(w io.Writer, format string, level string) (*slog.Logger, error) {
+	var lvl slog.Level
+	if level != "" {
+		if err := lvl.UnmarshalText([]byte(level)); err != nil {
+			return nil, fmt.Errorf("invalid log level %q", level)
+		}
+	}
+	options := &slog.HandlerOptions{Level: lvl}
+	switch strings.ToLower(format) {
+	case "", FORMAT_JSON:
+		return slog.New(slog.NewJSONHandler(w, options)), nil
+	case FORMAT_TEXT:
+		return slog.New(slog.NewTextHandler(w, options)), nil
+	}
+	return nil, fmt.Errorf("invalid log format %q", format)
+}
+
+// WithLogger es una función que devuelve el contexto con el logger dado.
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext es una función que obtiene el logger del contexto, o el logger por defecto si no tiene uno.
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
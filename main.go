@@ -3,13 +3,25 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
+	"talentpitchGo/apikey" // permisos de las API keys
+	"talentpitchGo/docs" // especificación OpenAPI
 	"talentpitchGo/handlers" // controladores de rutas HTTP
+	"talentpitchGo/logging" // logger estructurado
 	"talentpitchGo/middleware" // middleware de autenticación
+	"talentpitchGo/ratelimit" // límite de solicitudes
 	"talentpitchGo/server" // configuración del servidor
+	"talentpitchGo/social" // proveedores de inicio de sesión externos
+	"talentpitchGo/storage" // almacén de los archivos subidos
+	"talentpitchGo/tracing" // trazado distribuido
 
 	"github.com/gorilla/mux" // enrutador HTTP
 	"github.com/joho/godotenv" // para cargar variables de entorno desde un archivo .env
@@ -26,16 +38,115 @@ func main() {
 		log.Fatalf("Error loading .env file: %v", err)
 	}
 
+	// Configurar el logger estructurado con el formato (json o text) y el nivel de LOG_FORMAT y LOG_LEVEL
+	logger, err := logging.New(os.Stdout, os.Getenv("LOG_FORMAT"), os.Getenv("LOG_LEVEL"))
+	if err != nil {
+		log.Fatalf("Error configuring logger: %v", err)
+	}
+	slog.SetDefault(logger)
+
+	// Configurar el exportador de las trazas (otlp, stdout o none) de TRACE_EXPORTER
+	SERVICE_NAME := os.Getenv("OTEL_SERVICE_NAME")
+	if SERVICE_NAME == "" {
+		SERVICE_NAME = "talentpitch"
+	}
+	shutdownTracing, err := tracing.Setup(context.Background(), os.Getenv("TRACE_EXPORTER"), SERVICE_NAME)
+	if err != nil {
+		log.Fatalf("Error configuring tracing: %v", err)
+	}
+	// Enviar las trazas pendientes al terminar
+	defer shutdownTracing(context.Background())
+
 	// Obtener el puerto, el secreto JWT y la URL de la base de datos desde las variables de entorno
 	PORT := os.Getenv("PORT")
 	JWT_SECRET := os.Getenv("JWT_SECRET")
 	DATABASE_URL := os.Getenv("DATABASE_URL")
+	// Obtener el controlador de los repositorios de usuarios, retos y empresas: pq (por defecto) o pgx
+	DATABASE_DRIVER := os.Getenv("DATABASE_DRIVER")
+	// Obtener el tiempo de retención de los registros eliminados (30 días por defecto)
+	SOFT_DELETE_RETENTION := time.Hour * 24 * 30
+	if value := os.Getenv("SOFT_DELETE_RETENTION"); value != "" {
+		SOFT_DELETE_RETENTION, err = time.ParseDuration(value)
+		if err != nil {
+			log.Fatalf("Error parsing SOFT_DELETE_RETENTION: %v", err)
+		}
+	}
+
+	// Exigir If-Match en las actualizaciones y eliminaciones (activado por defecto)
+	REQUIRE_IF_MATCH := true
+	if value := os.Getenv("REQUIRE_IF_MATCH"); value != "" {
+		REQUIRE_IF_MATCH, err = strconv.ParseBool(value)
+		if err != nil {
+			log.Fatalf("Error parsing REQUIRE_IF_MATCH: %v", err)
+		}
+	}
+
+	// Obtener el puerto interno de las métricas de Prometheus (:9090 por defecto); no se publican en el puerto de la API
+	METRICS_PORT := os.Getenv("METRICS_PORT")
+	if METRICS_PORT == "" {
+		METRICS_PORT = ":9090"
+	}
+	// Obtener el almacén de los límites de solicitudes: memory (por defecto) o postgres para compartirlos entre instancias
+	RATE_LIMIT_STORE := os.Getenv("RATE_LIMIT_STORE")
+
+	// Obtener los orígenes permitidos de CORS, separados por comas, y si aceptan credenciales
+	var CORS_ALLOWED_ORIGINS []string
+	for _, origin := range strings.Split(os.Getenv("CORS_ALLOWED_ORIGINS"), ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			CORS_ALLOWED_ORIGINS = append(CORS_ALLOWED_ORIGINS, origin)
+		}
+	}
+	CORS_ALLOW_CREDENTIALS := false
+	if value := os.Getenv("CORS_ALLOW_CREDENTIALS"); value != "" {
+		CORS_ALLOW_CREDENTIALS, err = strconv.ParseBool(value)
+		if err != nil {
+			log.Fatalf("Error parsing CORS_ALLOW_CREDENTIALS: %v", err)
+		}
+	}
+	// Obtener el tiempo que el navegador guarda la verificación previa (10 minutos por defecto)
+	CORS_MAX_AGE := 10 * time.Minute
+	if value := os.Getenv("CORS_MAX_AGE"); value != "" {
+		CORS_MAX_AGE, err = time.ParseDuration(value)
+		if err != nil {
+			log.Fatalf("Error parsing CORS_MAX_AGE: %v", err)
+		}
+	}
+
+	// Obtener las IPs o rangos CIDR de los proxies de confianza, separados por comas. Solo de ellos se acepta
+	// X-Forwarded-For para conocer la IP del cliente en los límites de solicitudes y el registro de auditoría
+	var TRUSTED_PROXIES []string
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			TRUSTED_PROXIES = append(TRUSTED_PROXIES, proxy)
+		}
+	}
+	trustedProxies, err := middleware.ParseTrustedProxies(TRUSTED_PROXIES)
+	if err != nil {
+		log.Fatalf("Error parsing TRUSTED_PROXIES: %v", err)
+	}
+
+	// Registrar los proveedores de inicio de sesión externos configurados
+	registerSocialProviders()
+
+	// Configurar el almacén de los archivos subidos
+	if err := registerBlobStore(); err != nil {
+		log.Fatalf("Error configuring file storage: %v", err)
+	}
 
 	// Crear un nuevo servidor con la configuración dada
 	s, err := server.NewServer(context.Background(), &server.Config{
 		Port: PORT,
 		JWTSecret: JWT_SECRET,
 		DatabaseURL: DATABASE_URL,
+		DatabaseDriver: DATABASE_DRIVER,
+		SoftDeleteRetention: SOFT_DELETE_RETENTION,
+		RequireIfMatch: REQUIRE_IF_MATCH,
+		RateLimitStore: RATE_LIMIT_STORE,
+		CORSAllowedOrigins: CORS_ALLOWED_ORIGINS,
+		CORSAllowCredentials: CORS_ALLOW_CREDENTIALS,
+		CORSMaxAge: CORS_MAX_AGE,
+		TrustedProxies: trustedProxies,
+		MetricsPort: METRICS_PORT,
 	})
     // Manejar el error si existe
 	if err != nil {
@@ -47,34 +158,213 @@ func main() {
 	
 }
 
+// Políticas de límite de solicitudes de las rutas. Las rutas con la misma política comparten la cubeta de cada cliente.
+var (
+	// AUTH_RATE_LIMIT limita los registros e inicios de sesión de cada IP
+	AUTH_RATE_LIMIT = ratelimit.Policy{Name: "auth", Limit: 10, Period: time.Minute}
+	// LIST_RATE_LIMIT limita los listados de cada usuario o API key
+	LIST_RATE_LIMIT = ratelimit.Policy{Name: "list", Limit: 120, Period: time.Minute}
+)
+
 // BindRoutes es una función que enlaza las rutas HTTP con los controladores.
 func BindRoutes(s server.Server, r *mux.Router) {
-	// Usar el middleware de autenticación
+	// Abrir el span de la solicitud, asignarle un ID y registrar el acceso y las métricas antes de autenticarla
+	r.Use(middleware.Tracing, middleware.RequestID, middleware.AccessLog, middleware.Metrics, middleware.Recover)
+	// Resolver la IP del cliente detrás de los proxies de confianza para los límites de solicitudes y la auditoría
+	r.Use(middleware.ClientIP(s.Config().TrustedProxies))
+	// Agregar las cabeceras de seguridad y de CORS. Las verificaciones previas (OPTIONS) no coinciden con ninguna ruta,
+	// así que las atienden también los manejadores de ruta no encontrada y de método no permitido
+	cors := middleware.CORS(middleware.CORSConfig{
+		AllowedOrigins:   s.Config().CORSAllowedOrigins,
+		AllowCredentials: s.Config().CORSAllowCredentials,
+		MaxAge:           s.Config().CORSMaxAge,
+	})
+	r.Use(middleware.SecurityHeaders, cors)
+	r.NotFoundHandler = middleware.SecurityHeaders(cors(http.NotFoundHandler()))
+	r.MethodNotAllowedHandler = middleware.SecurityHeaders(cors(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	})))
+	// Usar el middleware de autenticación. Cada ruta declara al registrarse si es pública (middleware.Public)
+	// o qué permiso necesita una API key para usarla (middleware.RequireScope); el resto solo acepta tokens JWT.
 	r.Use(middleware.CheckAuthMiddleware(s))
-	r.HandleFunc("/", handlers.HomeHandler(s)).Methods(http.MethodGet)
-	r.HandleFunc("/signup", handlers.SignUpHandler(s)).Methods(http.MethodPost)
-	r.HandleFunc("/login", handlers.LoginHandler(s)).Methods(http.MethodPost)
-	r.HandleFunc("/deleteUser/{id}", handlers.DeleteUserHandler(s)).Methods(http.MethodDelete)
-	r.HandleFunc("/updateUser/{id}", handlers.UpdateUserHandler(s)).Methods(http.MethodPut)
-	r.HandleFunc("/users", handlers.GetUsersHandler(s)).Methods(http.MethodGet)
-	r.HandleFunc("/users", handlers.GetUsersHandler(s)).Methods(http.MethodGet)
-	r.HandleFunc("/me", handlers.MeHandler(s)).Methods(http.MethodGet)
+	// Limitar las solicitudes de cada cliente en las rutas que declaran una política (middleware.LimitRate)
+	r.Use(middleware.RateLimit)
+
+	middleware.Public(r.HandleFunc("/", handlers.HomeHandler(s)).Methods(http.MethodGet))
+	// Especificación OpenAPI e interfaz interactiva
+	middleware.Public(r.HandleFunc("/openapi.json", docs.SpecHandler(docs.OPERATIONS)).Methods(http.MethodGet))
+	middleware.Public(r.HandleFunc("/docs", docs.UIHandler("/openapi.json")).Methods(http.MethodGet))
+	// Archivos subidos, cuando el almacén es el sistema de archivos local
+	if files := storage.Handler(); files != nil {
+		middleware.Public(r.PathPrefix(server.UPLOADS_PREFIX + "/").Handler(http.StripPrefix(server.UPLOADS_PREFIX+"/", files)))
+	}
+	// Las rutas de la API se registran con el prefijo de versión
+	api := r.PathPrefix(server.API_V1_PREFIX).Subrouter()
+	middleware.Public(middleware.LimitRate(api.HandleFunc("/signup", handlers.SignUpHandler(s)).Methods(http.MethodPost), AUTH_RATE_LIMIT))
+	middleware.Public(middleware.LimitRate(api.HandleFunc("/login", handlers.LoginHandler(s)).Methods(http.MethodPost), AUTH_RATE_LIMIT))
+	middleware.Public(middleware.LimitRate(api.HandleFunc("/login/mfa", handlers.LoginMFAHandler(s)).Methods(http.MethodPost), AUTH_RATE_LIMIT))
+	middleware.Public(middleware.LimitRate(api.HandleFunc("/auth/{provider}/login", handlers.SocialLoginHandler(s)).Methods(http.MethodGet), AUTH_RATE_LIMIT))
+	middleware.Public(middleware.LimitRate(api.HandleFunc("/auth/{provider}/callback", handlers.SocialCallbackHandler(s)).Methods(http.MethodGet), AUTH_RATE_LIMIT))
+//************************************************************************************************************************
+//************************************************************* USER *****************************************************
+//************************************************************************************************************************
+	middleware.RequireScope(middleware.LimitRate(api.HandleFunc("/users", handlers.GetUsersHandler(s)).Methods(http.MethodGet), LIST_RATE_LIMIT), apikey.SCOPE_USERS_READ)
+	api.HandleFunc("/users/{id}", handlers.UpdateUserHandler(s)).Methods(http.MethodPut)
+	api.HandleFunc("/users/{id}", handlers.PatchUserHandler(s)).Methods(http.MethodPatch)
+	api.HandleFunc("/users/{id}", handlers.DeleteUserHandler(s)).Methods(http.MethodDelete)
+	api.HandleFunc("/users/{id}/avatar", handlers.UploadUserAvatarHandler(s)).Methods(http.MethodPost)
+	middleware.RequireScope(middleware.LimitRate(api.HandleFunc("/users/{id}/challenges", handlers.ListUserChallengesHandler(s)).Methods(http.MethodGet), LIST_RATE_LIMIT), apikey.SCOPE_CHALLENGES_READ)
+	middleware.RequireScope(middleware.LimitRate(api.HandleFunc("/users/{id}/companies", handlers.ListUserCompaniesHandler(s)).Methods(http.MethodGet), LIST_RATE_LIMIT), apikey.SCOPE_COMPANIES_READ)
+	api.HandleFunc("/me", handlers.MeHandler(s)).Methods(http.MethodGet)
+	api.HandleFunc("/mfa/totp/enroll", handlers.EnrollTOTPHandler(s)).Methods(http.MethodPost)
+	api.HandleFunc("/mfa/totp/verify", handlers.VerifyTOTPHandler(s)).Methods(http.MethodPost)
+	api.HandleFunc("/mfa/totp/disable", handlers.DisableTOTPHandler(s)).Methods(http.MethodPost)
+	api.HandleFunc("/api-keys", handlers.CreateAPIKeyHandler(s)).Methods(http.MethodPost)
+	api.HandleFunc("/api-keys", handlers.ListAPIKeysHandler(s)).Methods(http.MethodGet)
+	api.HandleFunc("/api-keys/{id}", handlers.RevokeAPIKeyHandler(s)).Methods(http.MethodDelete)
+	api.HandleFunc("/admin/users/{id}/restore", handlers.RestoreUserHandler(s)).Methods(http.MethodPost)
+	api.HandleFunc("/admin/challenges/{id}/restore", handlers.RestoreChallengeHandler(s)).Methods(http.MethodPost)
+	api.HandleFunc("/admin/companies/{id}/restore", handlers.RestoreCompanyHandler(s)).Methods(http.MethodPost)
+	api.HandleFunc("/admin/audit", handlers.GetAuditLogHandler(s)).Methods(http.MethodGet)
 //************************************************************************************************************************
 //************************************************************* CHALLENGE ************************************************
 //************************************************************************************************************************
-	r.HandleFunc("/challenges", handlers.CreateChallengeHandler(s)).Methods(http.MethodPost)
-	r.HandleFunc("/updateChallenge/{id}", handlers.UpdateChallengeHandler(s)).Methods(http.MethodPut)
-	r.HandleFunc("/deleteChallenge/{id}", handlers.DeleteChallengeHandler(s)).Methods(http.MethodDelete)
-	r.HandleFunc("/challenges", handlers.ListChallengesHandler(s)).Methods(http.MethodGet)
-	r.HandleFunc("/challenges/{id}", handlers.GetChallengeHandler(s)).Methods(http.MethodGet)
+	middleware.RequireScope(api.HandleFunc("/challenges", handlers.CreateChallengeHandler(s)).Methods(http.MethodPost), apikey.SCOPE_CHALLENGES_WRITE)
+	middleware.RequireScope(middleware.LimitRate(api.HandleFunc("/challenges", handlers.ListChallengesHandler(s)).Methods(http.MethodGet), LIST_RATE_LIMIT), apikey.SCOPE_CHALLENGES_READ)
+	// Las rutas fijas van antes de /challenges/{id} para que no se tomen como un ID
+	middleware.RequireScope(api.HandleFunc("/challenges/difficulties", handlers.ListDifficultiesHandler(s)).Methods(http.MethodGet), apikey.SCOPE_CHALLENGES_READ)
+	middleware.RequireScope(api.HandleFunc("/challenges/stats", handlers.ChallengeStatsHandler(s)).Methods(http.MethodGet), apikey.SCOPE_CHALLENGES_READ)
+	middleware.RequireScope(api.HandleFunc("/challenges/{id}", handlers.GetChallengeHandler(s)).Methods(http.MethodGet), apikey.SCOPE_CHALLENGES_READ)
+	middleware.RequireScope(api.HandleFunc("/challenges/{id}", handlers.UpdateChallengeHandler(s)).Methods(http.MethodPut), apikey.SCOPE_CHALLENGES_WRITE)
+	middleware.RequireScope(api.HandleFunc("/challenges/{id}", handlers.PatchChallengeHandler(s)).Methods(http.MethodPatch), apikey.SCOPE_CHALLENGES_WRITE)
+	middleware.RequireScope(api.HandleFunc("/challenges/{id}", handlers.DeleteChallengeHandler(s)).Methods(http.MethodDelete), apikey.SCOPE_CHALLENGES_WRITE)
+	middleware.RequireScope(api.HandleFunc("/challenges/{id}/attachments", handlers.ListAttachmentsHandler(s)).Methods(http.MethodGet), apikey.SCOPE_CHALLENGES_READ)
+	middleware.RequireScope(api.HandleFunc("/challenges/{id}/attachments", handlers.UploadAttachmentHandler(s)).Methods(http.MethodPost), apikey.SCOPE_CHALLENGES_WRITE)
+	middleware.RequireScope(api.HandleFunc("/challenges/{id}/attachments/{attachmentId}", handlers.DownloadAttachmentHandler(s)).Methods(http.MethodGet), apikey.SCOPE_CHALLENGES_READ)
+	middleware.RequireScope(api.HandleFunc("/challenges/{id}/attachments/{attachmentId}", handlers.DeleteAttachmentHandler(s)).Methods(http.MethodDelete), apikey.SCOPE_CHALLENGES_WRITE)
 //************************************************************************************************************************
 //************************************************************* COMPANY **************************************************
 //************************************************************************************************************************
-	r.HandleFunc("/companies", handlers.CreateCompanyHandler(s)).Methods(http.MethodPost)
-	r.HandleFunc("/updateCompany/{id}", handlers.UpdateCompanyHandler(s)).Methods(http.MethodPut)
-	r.HandleFunc("/deleteCompany/{id}", handlers.DeleteCompanyHandler(s)).Methods(http.MethodDelete)
-	r.HandleFunc("/companies", handlers.ListCompaniesHandler(s)).Methods(http.MethodGet)
-	r.HandleFunc("/companies/{id}", handlers.GetCompanyHandler(s)).Methods(http.MethodGet)
+	middleware.RequireScope(api.HandleFunc("/companies", handlers.CreateCompanyHandler(s)).Methods(http.MethodPost), apikey.SCOPE_COMPANIES_WRITE)
+	middleware.RequireScope(middleware.LimitRate(api.HandleFunc("/companies", handlers.ListCompaniesHandler(s)).Methods(http.MethodGet), LIST_RATE_LIMIT), apikey.SCOPE_COMPANIES_READ)
+	middleware.RequireScope(api.HandleFunc("/companies/{id}", handlers.GetCompanyHandler(s)).Methods(http.MethodGet), apikey.SCOPE_COMPANIES_READ)
+	middleware.RequireScope(api.HandleFunc("/companies/{id}", handlers.UpdateCompanyHandler(s)).Methods(http.MethodPut), apikey.SCOPE_COMPANIES_WRITE)
+	middleware.RequireScope(api.HandleFunc("/companies/{id}", handlers.PatchCompanyHandler(s)).Methods(http.MethodPatch), apikey.SCOPE_COMPANIES_WRITE)
+	middleware.RequireScope(api.HandleFunc("/companies/{id}", handlers.DeleteCompanyHandler(s)).Methods(http.MethodDelete), apikey.SCOPE_COMPANIES_WRITE)
+	middleware.RequireScope(api.HandleFunc("/companies/{id}/logo", handlers.UploadCompanyLogoHandler(s)).Methods(http.MethodPost), apikey.SCOPE_COMPANIES_WRITE)
+	middleware.RequireScope(middleware.LimitRate(api.HandleFunc("/companies/{id}/challenges", handlers.ListCompanyChallengesHandler(s)).Methods(http.MethodGet), LIST_RATE_LIMIT), apikey.SCOPE_CHALLENGES_READ)
+//************************************************************************************************************************
+//************************************************************* PROGRAM **************************************************
+//************************************************************************************************************************
+	api.HandleFunc("/programs/{id}", handlers.GetProgramHandler(s)).Methods(http.MethodGet)
+	api.HandleFunc("/programs/{id}", handlers.PatchProgramHandler(s)).Methods(http.MethodPatch)
+//************************************************************************************************************************
+//************************************************************* LEGACY ***************************************************
+//************************************************************************************************************************
+	// legacy es una función que registra una ruta anterior sin versión como alias obsoleto de la ruta de la API
+	legacy := func(path string, successor string, handler http.HandlerFunc, method string) *mux.Route {
+		return r.HandleFunc(path, middleware.Deprecated(server.API_V1_PREFIX+successor, handler)).Methods(method)
+	}
+	// Solo las rutas que existían antes de la versión tienen alias; las nuevas solo se registran con el prefijo
+	middleware.Public(middleware.LimitRate(legacy("/signup", "/signup", handlers.SignUpHandler(s), http.MethodPost), AUTH_RATE_LIMIT))
+	middleware.Public(middleware.LimitRate(legacy("/login", "/login", handlers.LoginHandler(s), http.MethodPost), AUTH_RATE_LIMIT))
+	legacy("/deleteUser/{id}", "/users/{id}", handlers.DeleteUserHandler(s), http.MethodDelete)
+	legacy("/updateUser/{id}", "/users/{id}", handlers.UpdateUserHandler(s), http.MethodPut)
+	middleware.RequireScope(middleware.LimitRate(legacy("/users", "/users", handlers.GetUsersHandler(s), http.MethodGet), LIST_RATE_LIMIT), apikey.SCOPE_USERS_READ)
+	legacy("/me", "/me", handlers.MeHandler(s), http.MethodGet)
+	middleware.RequireScope(legacy("/challenges", "/challenges", handlers.CreateChallengeHandler(s), http.MethodPost), apikey.SCOPE_CHALLENGES_WRITE)
+	middleware.RequireScope(legacy("/updateChallenge/{id}", "/challenges/{id}", handlers.UpdateChallengeHandler(s), http.MethodPut), apikey.SCOPE_CHALLENGES_WRITE)
+	middleware.RequireScope(legacy("/deleteChallenge/{id}", "/challenges/{id}", handlers.DeleteChallengeHandler(s), http.MethodDelete), apikey.SCOPE_CHALLENGES_WRITE)
+	middleware.RequireScope(middleware.LimitRate(legacy("/challenges", "/challenges", handlers.ListChallengesHandler(s), http.MethodGet), LIST_RATE_LIMIT), apikey.SCOPE_CHALLENGES_READ)
+	// Las rutas fijas de los retos no tienen alias, pero van antes de /challenges/{id} para que no se tomen como un ID
+	middleware.Public(r.Handle("/challenges/difficulties", http.NotFoundHandler()))
+	middleware.Public(r.Handle("/challenges/stats", http.NotFoundHandler()))
+	middleware.RequireScope(legacy("/challenges/{id}", "/challenges/{id}", handlers.GetChallengeHandler(s), http.MethodGet), apikey.SCOPE_CHALLENGES_READ)
+	middleware.RequireScope(legacy("/companies", "/companies", handlers.CreateCompanyHandler(s), http.MethodPost), apikey.SCOPE_COMPANIES_WRITE)
+	middleware.RequireScope(legacy("/updateCompany/{id}", "/companies/{id}", handlers.UpdateCompanyHandler(s), http.MethodPut), apikey.SCOPE_COMPANIES_WRITE)
+	middleware.RequireScope(legacy("/deleteCompany/{id}", "/companies/{id}", handlers.DeleteCompanyHandler(s), http.MethodDelete), apikey.SCOPE_COMPANIES_WRITE)
+	middleware.RequireScope(middleware.LimitRate(legacy("/companies", "/companies", handlers.ListCompaniesHandler(s), http.MethodGet), LIST_RATE_LIMIT), apikey.SCOPE_COMPANIES_READ)
+	middleware.RequireScope(legacy("/companies/{id}", "/companies/{id}", handlers.GetCompanyHandler(s), http.MethodGet), apikey.SCOPE_COMPANIES_READ)
+
+}
+
+// registerSocialProviders es una función que registra los proveedores de inicio de sesión externos
+// cuyo client id está definido en las variables de entorno.
+func registerSocialProviders() {
+	// URL pública de la API, usada para construir las URL de retorno
+	baseURL := os.Getenv("OAUTH_REDIRECT_BASE_URL")
+	// redirect es una función que construye la URL de retorno de un proveedor
+	redirect := func(name string) string {
+		return baseURL + server.API_V1_PREFIX + "/auth/" + name + "/callback"
+	}
+	// withCredentials es una función que completa la configuración con las credenciales de las variables de entorno
+	withCredentials := func(config social.ProviderConfig, prefix string) social.ProviderConfig {
+		config.ClientID = os.Getenv(prefix + "_CLIENT_ID")
+		config.ClientSecret = os.Getenv(prefix + "_CLIENT_SECRET")
+		config.RedirectURL = redirect(config.Name)
+		return config
+	}
+
+	if os.Getenv("GOOGLE_CLIENT_ID") != "" {
+		social.Register(social.NewOIDCProvider(withCredentials(social.GOOGLE, "GOOGLE")))
+	}
+	if os.Getenv("LINKEDIN_CLIENT_ID") != "" {
+		social.Register(social.NewOIDCProvider(withCredentials(social.LINKEDIN, "LINKEDIN")))
+	}
+	if os.Getenv("GITHUB_CLIENT_ID") != "" {
+		social.Register(social.NewGitHubProvider(withCredentials(social.ProviderConfig{Name: "github"}, "GITHUB")))
+	}
+	// Proveedor OIDC genérico descubierto desde su emisor (por ejemplo un servidor OIDC local de pruebas)
+	if issuer := os.Getenv("OIDC_ISSUER"); issuer != "" && os.Getenv("OIDC_CLIENT_ID") != "" {
+		name := os.Getenv("OIDC_NAME")
+		if name == "" {
+			name = "oidc"
+		}
+		config, err := social.Discover(context.Background(), issuer, withCredentials(social.ProviderConfig{Name: name}, "OIDC"))
+		if err != nil {
+			log.Fatalf("Error discovering OIDC provider %s: %v", issuer, err)
+		}
+		social.Register(social.NewOIDCProvider(config))
+	}
+}
 
 
-}
\ No newline at end of file
+// registerBlobStore es una función que configura el almacén de los archivos subidos según STORAGE_DRIVER:
+// "local" (por defecto) guarda los archivos en UPLOAD_DIR y los sirve bajo /uploads; "s3" los sube a un bucket compatible con S3.
+func registerBlobStore() error {
+	switch driver := os.Getenv("STORAGE_DRIVER"); driver {
+	case "", "local":
+		dir := os.Getenv("UPLOAD_DIR")
+		if dir == "" {
+			dir = "uploads"
+		}
+		// URL pública de los archivos; por defecto la ruta relativa de este servidor
+		baseURL := os.Getenv("UPLOAD_BASE_URL")
+		if baseURL == "" {
+			baseURL = server.UPLOADS_PREFIX
+		}
+		store, err := storage.NewLocalStore(dir, baseURL)
+		if err != nil {
+			return err
+		}
+		storage.SetBlobStore(store)
+	case "s3":
+		pathStyle, _ := strconv.ParseBool(os.Getenv("S3_PATH_STYLE"))
+		store, err := storage.NewS3Store(storage.S3Config{
+			Endpoint:        os.Getenv("S3_ENDPOINT"),
+			Region:          os.Getenv("S3_REGION"),
+			Bucket:          os.Getenv("S3_BUCKET"),
+			AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+			PublicURL:       os.Getenv("S3_PUBLIC_URL"),
+			PathStyle:       pathStyle,
+		})
+		if err != nil {
+			return err
+		}
+		storage.SetBlobStore(store)
+	default:
+		return fmt.Errorf("unknown STORAGE_DRIVER %q", driver)
+	}
+	return nil
+}
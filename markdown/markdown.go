@@ -0,0 +1,320 @@
+// El paquete markdown convierte el markdown de las descripciones en HTML seguro para mostrar.
+//
+// Soporta encabezados, párrafos, listas, citas, bloques de código, separadores, énfasis, código en línea,
+// tachado, enlaces e imágenes. El HTML del texto nunca se copia: todo el contenido se escapa y las únicas
+// etiquetas de la salida son las que genera el propio conversor, por lo que no hace falta sanearla después.
+package markdown
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// SAFE_SCHEMES son los esquemas permitidos en los enlaces; las URL relativas también se permiten
+var SAFE_SCHEMES = map[string]bool{"http": true, "https": true, "mailto": true}
+
+// IMAGE_SCHEMES son los esquemas permitidos en las imágenes
+var IMAGE_SCHEMES = map[string]bool{"http": true, "https": true}
+
+// Expresiones de los bloques
+var (
+	headingPattern   = regexp.MustCompile(`^(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	rulePattern      = regexp.MustCompile(`^ {0,3}((\*[ \t]*){3,}|(-[ \t]*){3,}|(_[ \t]*){3,})$`)
+	fencePattern     = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})[ \t]*([^`\\s]*)")
+	bulletPattern    = regexp.MustCompile(`^ {0,3}[-*+][ \t]+(.*)$`)
+	orderedPattern   = regexp.MustCompile(`^ {0,3}(\d{1,9})[.)][ \t]+(.*)$`)
+	quotePattern     = regexp.MustCompile(`^ {0,3}>[ ]?(.*)$`)
+	continuationLine = regexp.MustCompile(`^( {2,}|\t)\S`)
+)
+
+// ToHTML es una función que convierte el markdown dado en HTML seguro.
+func ToHTML(source string) string {
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+	var out strings.Builder
+	renderBlocks(&out, strings.Split(source, "\n"))
+	return out.String()
+}
+
+// renderBlocks es una función que escribe el HTML de una lista de líneas de bloques.
+func renderBlocks(out *strings.Builder, lines []string) {
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		switch {
+		case strings.TrimSpace(line) == "":
+			i++
+		case fencePattern.MatchString(line):
+			i = renderFence(out, lines, i)
+		case headingPattern.MatchString(strings.TrimLeft(line, " ")) && len(line)-len(strings.TrimLeft(line, " ")) < 4:
+			match := headingPattern.FindStringSubmatch(strings.TrimLeft(line, " "))
+			level := string(rune('0' + len(match[1])))
+			out.WriteString("<h" + level + ">" + renderInline(match[2]) + "</h" + level + ">\n")
+			i++
+		case rulePattern.MatchString(line):
+			out.WriteString("<hr>\n")
+			i++
+		case quotePattern.MatchString(line):
+			// Las líneas seguidas de la cita se convierten como bloques propios
+			var inner []string
+			for ; i < len(lines) && quotePattern.MatchString(lines[i]); i++ {
+				inner = append(inner, quotePattern.FindStringSubmatch(lines[i])[1])
+			}
+			out.WriteString("<blockquote>\n")
+			renderBlocks(out, inner)
+			out.WriteString("</blockquote>\n")
+		case bulletPattern.MatchString(line):
+			i = renderList(out, lines, i, bulletPattern, "ul")
+		case orderedPattern.MatchString(line):
+			i = renderList(out, lines, i, orderedPattern, "ol")
+		default:
+			i = renderParagraph(out, lines, i)
+		}
+	}
+}
+
+// renderFence es una función que escribe un bloque de código delimitado y devuelve la línea siguiente.
+func renderFence(out *strings.Builder, lines []string, start int) int {
+	match := fencePattern.FindStringSubmatch(lines[start])
+	fence, language := match[1], match[2]
+	i := start + 1
+	var code []string
+	for ; i < len(lines); i++ {
+		// El bloque termina con una valla del mismo carácter y al menos igual de larga
+		closing := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(closing, fence) && strings.Trim(closing, fence[:1]) == "" {
+			i++
+			break
+		}
+		code = append(code, lines[i])
+	}
+	out.WriteString("<pre><code")
+	if language != "" {
+		out.WriteString(` class="language-` + html.EscapeString(language) + `"`)
+	}
+	out.WriteString(">")
+	for _, line := range code {
+		out.WriteString(html.EscapeString(line) + "\n")
+	}
+	out.WriteString("</code></pre>\n")
+	return i
+}
+
+// renderList es una función que escribe una lista y devuelve la línea siguiente.
+// Las líneas con sangría que siguen a un elemento se unen a su texto.
+func renderList(out *strings.Builder, lines []string, start int, pattern *regexp.Regexp, tag string) int {
+	open := "<" + tag + ">\n"
+	if tag == "ol" {
+		// Conservar el número inicial de las listas ordenadas
+		if number := strings.TrimLeft(pattern.FindStringSubmatch(lines[start])[1], "0"); number != "1" && number != "" {
+			open = `<ol start="` + number + `">` + "\n"
+		}
+	}
+	out.WriteString(open)
+	i := start
+	for i < len(lines) && pattern.MatchString(lines[i]) {
+		match := pattern.FindStringSubmatch(lines[i])
+		text := match[len(match)-1]
+		i++
+		for ; i < len(lines) && continuationLine.MatchString(lines[i]); i++ {
+			text += "\n" + strings.TrimSpace(lines[i])
+		}
+		out.WriteString("<li>" + renderInline(text) + "</li>\n")
+	}
+	out.WriteString("</" + tag + ">\n")
+	return i
+}
+
+// renderParagraph es una función que escribe un párrafo y devuelve la línea siguiente.
+func renderParagraph(out *strings.Builder, lines []string, start int) int {
+	var text []string
+	i := start
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		// El párrafo termina en una línea vacía o en el inicio de otro bloque
+		if i > start && (strings.TrimSpace(line) == "" || fencePattern.MatchString(line) || headingPattern.MatchString(line) ||
+			rulePattern.MatchString(line) || quotePattern.MatchString(line) || bulletPattern.MatchString(line)) {
+			break
+		}
+		text = append(text, strings.TrimSpace(line))
+	}
+	out.WriteString("<p>" + renderInline(strings.Join(text, "\n")) + "</p>\n")
+	return i
+}
+
+// renderInline es una función que convierte el formato en línea de un texto, escapando todo lo demás.
+func renderInline(text string) string {
+	var out strings.Builder
+	for i := 0; i < len(text); {
+		c := text[i]
+		switch {
+		case c == '\\' && i+1 < len(text) && strings.IndexByte("\\`*_{}[]()#+-.!~>|", text[i+1]) >= 0:
+			// Carácter escapado
+			out.WriteString(html.EscapeString(text[i+1 : i+2]))
+			i += 2
+			continue
+		case c == '`':
+			// Código en línea: termina con la misma cantidad de comillas
+			run := countRun(text, i, '`')
+			if end := strings.Index(text[i+run:], text[i:i+run]); end >= 0 {
+				code := strings.TrimSpace(text[i+run : i+run+end])
+				out.WriteString("<code>" + html.EscapeString(code) + "</code>")
+				i += run + end + run
+				continue
+			}
+			out.WriteString(text[i : i+run])
+			i += run
+			continue
+		case c == '!' && i+1 < len(text) && text[i+1] == '[':
+			if label, target, next, ok := parseLink(text, i+1); ok {
+				if src, safe := safeURL(target, IMAGE_SCHEMES); safe {
+					out.WriteString(`<img src="` + html.EscapeString(src) + `" alt="` + html.EscapeString(label) + `">`)
+				} else {
+					out.WriteString(html.EscapeString(label))
+				}
+				i = next
+				continue
+			}
+		case c == '[':
+			if label, target, next, ok := parseLink(text, i); ok {
+				if href, safe := safeURL(target, SAFE_SCHEMES); safe {
+					out.WriteString(`<a href="` + html.EscapeString(href) + `" rel="nofollow noopener noreferrer">` + renderInline(label) + "</a>")
+				} else {
+					// Los enlaces con esquemas peligrosos, como javascript:, quedan como texto
+					out.WriteString(renderInline(label))
+				}
+				i = next
+				continue
+			}
+		case c == '*' || c == '_' || c == '~':
+			if rendered, next, ok := renderDelimited(text, i); ok {
+				out.WriteString(rendered)
+				i = next
+				continue
+			}
+		case c == '\n':
+			out.WriteString("<br>\n")
+			i++
+			continue
+		}
+		out.WriteString(html.EscapeString(text[i : i+1]))
+		i++
+	}
+	return out.String()
+}
+
+// renderDelimited es una función que convierte el énfasis (*, _), el énfasis fuerte (**, __) y el tachado (~~).
+func renderDelimited(text string, i int) (string, int, bool) {
+	c := text[i]
+	run := countRun(text, i, c)
+	if run > 2 {
+		run = 2
+	}
+	if c == '~' && run != 2 {
+		return "", 0, false
+	}
+	delimiter := text[i : i+run]
+	// El texto enfatizado no puede empezar con un espacio
+	start := i + run
+	if start >= len(text) || text[start] == ' ' || text[start] == '\n' {
+		return "", 0, false
+	}
+	// "_" dentro de una palabra, como en snake_case, no es énfasis
+	if c == '_' && i > 0 && isWordByte(text[i-1]) {
+		return "", 0, false
+	}
+	end := strings.Index(text[start:], delimiter)
+	for end >= 0 {
+		closeAt := start + end
+		// El cierre no puede seguir a un espacio ni ser parte de un delimitador más largo
+		if text[closeAt-1] != ' ' && (run == 2 || closeAt+1 >= len(text) || text[closeAt+1] != c) &&
+			!(c == '_' && closeAt+run < len(text) && isWordByte(text[closeAt+run])) {
+			tag := map[string]string{"*": "em", "_": "em", "**": "strong", "__": "strong", "~~": "del"}[delimiter]
+			return "<" + tag + ">" + renderInline(text[start:closeAt]) + "</" + tag + ">", closeAt + run, true
+		}
+		next := strings.Index(text[closeAt+run:], delimiter)
+		if next < 0 {
+			break
+		}
+		end = closeAt + run + next - start
+	}
+	return "", 0, false
+}
+
+// parseLink es una función que lee un enlace [texto](url) desde el corchete de apertura.
+func parseLink(text string, i int) (string, string, int, bool) {
+	// Buscar el corchete de cierre, respetando los corchetes anidados
+	depth := 0
+	for j := i; j < len(text); j++ {
+		switch text[j] {
+		case '\\':
+			j++
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				if j+1 >= len(text) || text[j+1] != '(' {
+					return "", "", 0, false
+				}
+				end := closingParen(text[j+2:])
+				if end < 0 {
+					return "", "", 0, false
+				}
+				// Ignorar el título opcional: [texto](url "título")
+				target := strings.Fields(text[j+2 : j+2+end])
+				if len(target) == 0 {
+					return "", "", 0, false
+				}
+				return text[i+1 : j], strings.Trim(target[0], "<>"), j + 3 + end, true
+			}
+		}
+	}
+	return "", "", 0, false
+}
+
+// closingParen es una función que devuelve la posición del paréntesis que cierra la URL, respetando los paréntesis anidados.
+func closingParen(text string) int {
+	depth := 0
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '(':
+			depth++
+		case ')':
+			if depth == 0 {
+				return i
+			}
+			depth--
+		case '\n':
+			return -1
+		}
+	}
+	return -1
+}
+
+// safeURL es una función que indica si la URL es relativa o usa uno de los esquemas permitidos.
+func safeURL(raw string, schemes map[string]bool) (string, bool) {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return "", false
+	}
+	if parsed.Scheme == "" {
+		// Las URL relativas a otro host (//host) no se permiten
+		return raw, parsed.Host == ""
+	}
+	return raw, schemes[strings.ToLower(parsed.Scheme)]
+}
+
+// countRun es una función que cuenta las repeticiones del carácter desde la posición dada.
+func countRun(text string, i int, c byte) int {
+	n := 0
+	for i+n < len(text) && text[i+n] == c {
+		n++
+	}
+	return n
+}
+
+// isWordByte es una función que indica si el byte es una letra, un dígito o parte de un carácter UTF-8.
+func isWordByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
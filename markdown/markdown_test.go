@@ -0,0 +1,43 @@
+package markdown_test
+
+import (
+	"testing"
+
+	"talentpitchGo/markdown"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToHTML(t *testing.T) {
+	cases := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{"heading", "## Entrega", "<h2>Entrega</h2>\n"},
+		{"inline", "Usa *una* **API** con `a<b` y ~~XML~~", "<p>Usa <em>una</em> <strong>API</strong> con <code>a&lt;b</code> y <del>XML</del></p>\n"},
+		{"snake case", "user_id y page_size", "<p>user_id y page_size</p>\n"},
+		{"list", "- uno\n- dos", "<ul>\n<li>uno</li>\n<li>dos</li>\n</ul>\n"},
+		{"ordered", "2. dos\n3. tres", "<ol start=\"2\">\n<li>dos</li>\n<li>tres</li>\n</ol>\n"},
+		{"quote", "> nota", "<blockquote>\n<p>nota</p>\n</blockquote>\n"},
+		{"code block", "```go\nfmt.Println(\"<hola>\")\n```", "<pre><code class=\"language-go\">fmt.Println(&#34;&lt;hola&gt;&#34;)\n</code></pre>\n"},
+		{"link", "[datos](https://example.com/a?b=1&c=2)", "<p><a href=\"https://example.com/a?b=1&amp;c=2\" rel=\"nofollow noopener noreferrer\">datos</a></p>\n"},
+		{"image", "![diagrama](https://example.com/d.png)", "<p><img src=\"https://example.com/d.png\" alt=\"diagrama\"></p>\n"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.expected, markdown.ToHTML(c.source))
+		})
+	}
+}
+
+// TestToHTMLSanitizes verifica que el HTML y las URL peligrosas del texto nunca lleguen a la salida
+func TestToHTMLSanitizes(t *testing.T) {
+	assert.Equal(t, "<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>\n", markdown.ToHTML("<script>alert(1)</script>"))
+	assert.Equal(t, "<p>&lt;img src=x onerror=alert(1)&gt;</p>\n", markdown.ToHTML("<img src=x onerror=alert(1)>"))
+	assert.Equal(t, "<p>clic</p>\n", markdown.ToHTML("[clic](javascript:alert(1))"))
+	assert.Equal(t, "<p>clic</p>\n", markdown.ToHTML("[clic](JaVaScRiPt:alert(1))"))
+	assert.Equal(t, "<p>x</p>\n", markdown.ToHTML("![x](data:image/svg+xml;base64,PHN2Zz4=)"))
+	assert.Equal(t, "<p>otro</p>\n", markdown.ToHTML("[otro](//evil.example.com)"))
+	assert.Equal(t, "<p><a href=\"https://e.com/&#34;onmouseover=&#34;x\" rel=\"nofollow noopener noreferrer\">q</a></p>\n", markdown.ToHTML("[q](https://e.com/\"onmouseover=\"x)"))
+}
@@ -0,0 +1,56 @@
+// El paquete mergepatch implementa JSON Merge Patch (RFC 7396).
+package mergepatch
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Apply es una función que aplica un JSON Merge Patch a un documento JSON y devuelve el documento resultante.
+// Los miembros del parche con valor null se eliminan del documento; el resto lo reemplaza o se combina recursivamente.
+func Apply(document []byte, patch []byte) ([]byte, error) {
+	// Decodificar el documento, que puede estar vacío
+	var target interface{}
+	if len(bytes.TrimSpace(document)) > 0 {
+		if err := decode(document, &target); err != nil {
+			return nil, err
+		}
+	}
+	// Decodificar el parche
+	var changes interface{}
+	if err := decode(patch, &changes); err != nil {
+		return nil, err
+	}
+	// Combinar y codificar el resultado
+	return json.Marshal(merge(target, changes))
+}
+
+// merge es una función que combina un valor con su parche siguiendo el algoritmo de la RFC 7396
+func merge(target interface{}, patch interface{}) interface{} {
+	// Un parche que no es un objeto reemplaza el valor completo
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	// Si el valor no es un objeto se parte de un objeto vacío
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+	for name, value := range patchObject {
+		if value == nil {
+			// null elimina el miembro
+			delete(targetObject, name)
+		} else {
+			targetObject[name] = merge(targetObject[name], value)
+		}
+	}
+	return targetObject
+}
+
+// decode es una función que decodifica JSON conservando los números tal como se escribieron
+func decode(data []byte, value interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(value)
+}
@@ -0,0 +1,44 @@
+package mergepatch_test
+
+import (
+	"testing"
+
+	"talentpitchGo/mergepatch"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Ejemplos del apéndice A de la RFC 7396
+func TestApplyRFC7396Examples(t *testing.T) {
+	cases := []struct {
+		document string
+		patch    string
+		expected string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, c := range cases {
+		result, err := mergepatch.Apply([]byte(c.document), []byte(c.patch))
+		assert.NoError(t, err)
+		assert.JSONEq(t, c.expected, string(result), "patch %s on %s", c.patch, c.document)
+	}
+}
+
+func TestApplyInvalidPatch(t *testing.T) {
+	_, err := mergepatch.Apply([]byte(`{"a":1}`), []byte(`{"a":`))
+	assert.Error(t, err)
+}
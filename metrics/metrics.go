@@ -0,0 +1,113 @@
+// El paquete metrics define las métricas de Prometheus de la aplicación (HTTP, base de datos y negocio)
+// y el controlador que las expone en el formato de texto de Prometheus.
+package metrics
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// NAMESPACE es el prefijo de las métricas propias de la aplicación
+const NAMESPACE = "talentpitch"
+
+// Métodos de inicio de sesión de LOGINS
+const (
+	LOGIN_PASSWORD = "password"
+	LOGIN_MFA      = "mfa"
+	LOGIN_SOCIAL   = "social"
+)
+
+// Registry es el registro con todas las métricas que se exponen en /metrics
+var Registry = prometheus.NewRegistry()
+
+// Métricas HTTP, etiquetadas por método, plantilla de la ruta y código de la respuesta
+var (
+	HTTP_REQUESTS = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: NAMESPACE,
+		Name:      "http_requests_total",
+		Help:      "Number of HTTP requests by method, route template and status.",
+	}, []string{"method", "route", "status"})
+	HTTP_DURATION = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: NAMESPACE,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of HTTP requests by method, route template and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+)
+
+// DB_QUERY_DURATION es la duración de las consultas de cada método del repositorio
+var DB_QUERY_DURATION = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: NAMESPACE,
+	Name:      "db_query_duration_seconds",
+	Help:      "Duration of database calls by repository method.",
+	Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+}, []string{"method"})
+
+// Métricas de negocio
+var (
+	SIGNUPS = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: NAMESPACE,
+		Name:      "signups_total",
+		Help:      "Number of users signed up.",
+	})
+	LOGINS = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: NAMESPACE,
+		Name:      "logins_total",
+		Help:      "Number of successful logins by method (password, mfa, social).",
+	}, []string{"method"})
+	CHALLENGES_CREATED = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: NAMESPACE,
+		Name:      "challenges_created_total",
+		Help:      "Number of challenges created.",
+	})
+	COMPANIES_CREATED = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: NAMESPACE,
+		Name:      "companies_created_total",
+		Help:      "Number of companies created.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTP_REQUESTS, HTTP_DURATION, DB_QUERY_DURATION,
+		SIGNUPS, LOGINS, CHALLENGES_CREATED, COMPANIES_CREATED,
+	)
+}
+
+// Handler es una función que devuelve el controlador que expone las métricas del registro.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// ObserveRequest es una función que registra una solicitud HTTP atendida.
+func ObserveRequest(method string, route string, status int, duration time.Duration) {
+	code := strconv.Itoa(status)
+	HTTP_REQUESTS.WithLabelValues(method, route, code).Inc()
+	HTTP_DURATION.WithLabelValues(method, route, code).Observe(duration.Seconds())
+}
+
+// ObserveQuery es una función que registra la duración de un método del repositorio iniciado en start.
+// Se usa al comienzo del método con defer metrics.ObserveQuery("GetChallenges", time.Now()).
+func ObserveQuery(method string, start time.Time) {
+	DB_QUERY_DURATION.WithLabelValues(method).Observe(time.Since(start).Seconds())
+}
+
+// RegisterDBStats es una función que agrega las métricas del pool de conexiones (sql.DBStats) de la base de datos.
+// Registrar de nuevo la misma base de datos no tiene efecto.
+func RegisterDBStats(db *sql.DB, name string) error {
+	err := Registry.Register(collectors.NewDBStatsCollector(db, name))
+	var registered prometheus.AlreadyRegisteredError
+	if errors.As(err, &registered) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,50 @@
+package mfa
+
+import (
+	"crypto/rand"
+	"math/big"
+	"strings"
+)
+
+const (
+	// RECOVERY_CODES es el número de códigos de recuperación que se generan al activar 2FA.
+	RECOVERY_CODES = 10
+	// RECOVERY_CODE_SIZE es el número de caracteres de cada código de recuperación (sin el guion).
+	RECOVERY_CODE_SIZE = 10
+)
+
+// recoveryAlphabet es el alfabeto de los códigos de recuperación, sin caracteres ambiguos (0/o, 1/l/i).
+const recoveryAlphabet = "23456789abcdefghjkmnpqrstuvwxyz"
+
+// GenerateRecoveryCodes es una función que genera n códigos de recuperación de un solo uso con el formato xxxxx-xxxxx.
+func GenerateRecoveryCodes(n int) ([]string, error) {
+	codes := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		// Elegir cada carácter del alfabeto de forma aleatoria y uniforme
+		var sb strings.Builder
+		for j := 0; j < RECOVERY_CODE_SIZE; j++ {
+			if j == RECOVERY_CODE_SIZE/2 {
+				sb.WriteByte('-')
+			}
+			idx, err := rand.Int(rand.Reader, big.NewInt(int64(len(recoveryAlphabet))))
+			if err != nil {
+				return nil, err
+			}
+			sb.WriteByte(recoveryAlphabet[idx.Int64()])
+		}
+		codes = append(codes, sb.String())
+	}
+	return codes, nil
+}
+
+// NormalizeRecoveryCode es una función que normaliza un código de recuperación ingresado por el usuario
+// (minúsculas, sin espacios) para compararlo con su hash.
+func NormalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.ReplaceAll(code, " ", "")
+}
+
+// IsRecoveryCode es una función que indica si el código tiene el formato de un código de recuperación y no de un código TOTP.
+func IsRecoveryCode(code string) bool {
+	return strings.Contains(NormalizeRecoveryCode(code), "-")
+}
@@ -0,0 +1,117 @@
+// El paquete mfa contiene las utilidades de autenticación de dos factores (TOTP y códigos de recuperación).
+package mfa
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// PERIOD es la duración en segundos de cada ventana TOTP.
+	PERIOD = 30
+	// DIGITS es el número de dígitos de cada código TOTP.
+	DIGITS = 6
+	// SKEW es el número de ventanas anteriores y posteriores que se aceptan para tolerar desfases de reloj.
+	SKEW = 1
+	// SECRET_SIZE es el tamaño en bytes del secreto TOTP (160 bits, recomendado por RFC 4226).
+	SECRET_SIZE = 20
+)
+
+// encoding es la codificación base32 sin relleno usada por las aplicaciones autenticadoras.
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateSecret es una función que genera un nuevo secreto TOTP aleatorio codificado en base32.
+func GenerateSecret() (string, error) {
+	// Generar bytes aleatorios para el secreto
+	secret := make([]byte, SECRET_SIZE)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	// Codificar el secreto en base32
+	return encoding.EncodeToString(secret), nil
+}
+
+// URI es una función que construye la URI otpauth:// que las aplicaciones autenticadoras leen desde un código QR.
+func URI(issuer string, account string, secret string) string {
+	// Construir la etiqueta con el emisor y la cuenta
+	label := url.PathEscape(issuer) + ":" + url.PathEscape(account)
+	// Construir los parámetros de la URI
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", issuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprint(DIGITS))
+	params.Set("period", fmt.Sprint(PERIOD))
+	// Retornar la URI
+	return "otpauth://totp/" + label + "?" + params.Encode()
+}
+
+// GenerateCode es una función que calcula el código TOTP de un secreto para un instante dado.
+func GenerateCode(secret string, t time.Time) (string, error) {
+	// Decodificar el secreto
+	key, err := encoding.DecodeString(strings.ToUpper(strings.TrimSpace(secret)))
+	if err != nil {
+		return "", err
+	}
+	// Calcular el código para la ventana del instante dado
+	return hotp(key, uint64(t.Unix()/PERIOD)), nil
+}
+
+// Validate es una función que verifica un código TOTP contra un secreto, tolerando SKEW ventanas de desfase.
+func Validate(secret string, code string, t time.Time) bool {
+	_, ok := ValidateStep(secret, code, t)
+	return ok
+}
+
+// ValidateStep es una función que verifica un código TOTP como Validate y devuelve además la ventana (el contador)
+// en la que coincidió, para rechazar después los códigos de esa ventana o de una anterior.
+func ValidateStep(secret string, code string, t time.Time) (int64, bool) {
+	// Normalizar el código recibido
+	code = strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+	// Verificar que el código tenga la longitud esperada
+	if len(code) != DIGITS {
+		return 0, false
+	}
+	// Decodificar el secreto
+	key, err := encoding.DecodeString(strings.ToUpper(strings.TrimSpace(secret)))
+	if err != nil {
+		return 0, false
+	}
+	// Comparar el código con cada ventana permitida
+	counter := t.Unix() / PERIOD
+	for i := -SKEW; i <= SKEW; i++ {
+		expected := hotp(key, uint64(counter+int64(i)))
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return counter + int64(i), true
+		}
+	}
+	return 0, false
+}
+
+// hotp es una función que calcula un código HOTP (RFC 4226) para una clave y un contador.
+func hotp(key []byte, counter uint64) string {
+	// Codificar el contador en big endian
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+	// Calcular el HMAC-SHA1
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+	// Aplicar el truncamiento dinámico
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	// Reducir al número de dígitos configurado
+	mod := uint32(1)
+	for i := 0; i < DIGITS; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", DIGITS, value%mod)
+}
@@ -0,0 +1,59 @@
+package mfa_test
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+
+	"talentpitchGo/mfa"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// secret es el secreto de los vectores de prueba del RFC 6238 ("12345678901234567890") codificado en base32.
+var secret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestGenerateCodeRFC6238(t *testing.T) {
+	// Vectores SHA1 del apéndice B del RFC 6238, truncados a 6 dígitos
+	vectors := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+	for ts, expected := range vectors {
+		code, err := mfa.GenerateCode(secret, time.Unix(ts, 0))
+		assert.NoError(t, err)
+		assert.Equal(t, expected, code, "timestamp %d", ts)
+	}
+}
+
+func TestValidateSkew(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	code, err := mfa.GenerateCode(secret, now)
+	assert.NoError(t, err)
+
+	// El código es válido en su ventana y en las ventanas vecinas
+	assert.True(t, mfa.Validate(secret, code, now))
+	assert.True(t, mfa.Validate(secret, code, now.Add(mfa.PERIOD*time.Second)))
+	// Pero no fuera del desfase permitido
+	assert.False(t, mfa.Validate(secret, code, now.Add(3*mfa.PERIOD*time.Second)))
+	assert.False(t, mfa.Validate(secret, "12345", now))
+
+	// La ventana devuelta es la del código, no la del instante de la verificación
+	step, ok := mfa.ValidateStep(secret, code, now.Add(mfa.PERIOD*time.Second))
+	assert.True(t, ok)
+	assert.Equal(t, now.Unix()/mfa.PERIOD, step)
+}
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	codes, err := mfa.GenerateRecoveryCodes(mfa.RECOVERY_CODES)
+	assert.NoError(t, err)
+	assert.Len(t, codes, mfa.RECOVERY_CODES)
+	for _, code := range codes {
+		assert.Len(t, code, mfa.RECOVERY_CODE_SIZE+1)
+		assert.True(t, mfa.IsRecoveryCode(code))
+	}
+}
@@ -2,60 +2,217 @@
 package middleware
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 	"strings"
+	"sync"
+	"talentpitchGo/apikey"
+	"talentpitchGo/logging"
 	"talentpitchGo/server"
 	"talentpitchGo/models"
+	"talentpitchGo/repository"
 	"github.com/golang-jwt/jwt"
+	"github.com/gorilla/mux"
 )
-	
-// NO_AUTH_NEEDED esta variable contiene las rutas que no necesitan autenticación
-var (
-	NO_AUTH_NEEDED = []string{
-		"/signup", 
-		"/login",
+
+// REALM es el realm que se anuncia en la cabecera WWW-Authenticate
+const (
+	REALM = "talentpitch"
+)
+
+// RouteAuth es la estructura de los metadatos de autenticación de una ruta, declarados al registrarla en BindRoutes
+type RouteAuth struct {
+	Public bool   // La ruta no necesita autenticación
+	Scope  string // Permiso que necesita una API key; vacío si la ruta solo acepta tokens JWT
+}
+
+// Principal es la estructura del usuario autenticado de la solicitud
+type Principal struct {
+	UserID   string // ID del usuario autenticado o dueño de la API key
+	APIKeyID string // ID de la API key usada; vacío si se autenticó con un token JWT
+}
+
+// principalKey es la clave del Principal en el contexto de la solicitud
+type principalKey struct{}
+
+// PrincipalFromContext es una función que obtiene el usuario autenticado guardado por CheckAuthMiddleware
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(Principal)
+	return principal, ok
+}
+
+// withPrincipal es una función que devuelve la solicitud con el usuario autenticado en su contexto
+// y lo agrega a los mensajes del logger y al registro de acceso
+func withPrincipal(r *http.Request, principal Principal) *http.Request {
+	if info, ok := r.Context().Value(requestInfoKey{}).(*requestInfo); ok {
+		info.userID = principal.UserID
 	}
+	ctx := context.WithValue(r.Context(), principalKey{}, principal)
+	ctx = logging.WithLogger(ctx, logging.FromContext(ctx).With("user_id", principal.UserID))
+	return r.WithContext(ctx)
+}
+
+// routes esta variable contiene los metadatos de autenticación de cada ruta registrada
+var (
+	routesMu sync.RWMutex
+	routes   = map[*mux.Route]RouteAuth{}
 )
 
-// shouldCheckToken es una función para verificar si el token debe ser verificado para la ruta dada
-func shouldCheckToken(route string) bool {
-	// Iterar sobre las rutas que no necesitan autenticación
-	for _, p := range NO_AUTH_NEEDED {
-		// Verificar si la ruta contiene la ruta actual
-		if strings.Contains(route, p) {
-			return false
-		}
+// Public es una función que declara una ruta como pública y devuelve la misma ruta
+func Public(route *mux.Route) *mux.Route {
+	routesMu.Lock()
+	defer routesMu.Unlock()
+	routes[route] = RouteAuth{Public: true}
+	return route
+}
+
+// RequireScope es una función que declara el permiso que necesita una API key para usar la ruta y devuelve la misma ruta
+func RequireScope(route *mux.Route, scope string) *mux.Route {
+	routesMu.Lock()
+	defer routesMu.Unlock()
+	routes[route] = RouteAuth{Scope: scope}
+	return route
+}
+
+// routeAuth es una función que obtiene los metadatos de autenticación de la ruta que coincidió con la solicitud.
+// Las rutas sin metadatos, o las solicitudes sin ruta, necesitan un token JWT.
+func routeAuth(r *http.Request) RouteAuth {
+	// Obtener la ruta de mux que coincidió con la solicitud
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return RouteAuth{}
 	}
-	return true
+	routesMu.RLock()
+	defer routesMu.RUnlock()
+	return routes[route]
 }
 
-// CheckAuthMiddleware es un middleware para verificar si la solicitud tiene un token JWT válido
+// BearerToken es una función que obtiene la credencial de la cabecera "Authorization: Bearer <token>" (RFC 6750).
+// Devuelve falso si la cabecera no existe o usa otro esquema.
+func BearerToken(r *http.Request) (string, bool) {
+	// Separar el esquema de la credencial
+	scheme, credential, found := strings.Cut(strings.TrimSpace(r.Header.Get("Authorization")), " ")
+	// El esquema no distingue mayúsculas y minúsculas
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	credential = strings.TrimSpace(credential)
+	return credential, credential != ""
+}
+
+// apiKeyFromRequest es una función que obtiene la API key de la cabecera X-API-Key o de la cabecera de autorización
+func apiKeyFromRequest(r *http.Request) string {
+	// Obtener la key de la cabecera dedicada
+	if key := strings.TrimSpace(r.Header.Get("X-API-Key")); key != "" {
+		return key
+	}
+	// Obtener la key de la cabecera de autorización
+	if credential, ok := BearerToken(r); ok && apikey.IsAPIKey(credential) {
+		return credential
+	}
+	return ""
+}
+
+// unauthorized es una función que responde 401 con una cabecera WWW-Authenticate según RFC 6750.
+// Sin código de error se indica solo el esquema, como corresponde a una solicitud sin credenciales.
+func unauthorized(w http.ResponseWriter, errorCode string, description string) {
+	challenge := fmt.Sprintf("Bearer realm=%q", REALM)
+	if errorCode != "" {
+		challenge += fmt.Sprintf(", error=%q, error_description=%q", errorCode, description)
+	}
+	w.Header().Set("WWW-Authenticate", challenge)
+	http.Error(w, description, http.StatusUnauthorized)
+}
+
+// forbidden es una función que responde 403 por falta de permisos con una cabecera WWW-Authenticate según RFC 6750
+func forbidden(w http.ResponseWriter, scope string, description string) {
+	challenge := fmt.Sprintf("Bearer realm=%q, error=\"insufficient_scope\", error_description=%q", REALM, description)
+	if scope != "" {
+		challenge += fmt.Sprintf(", scope=%q", scope)
+	}
+	w.Header().Set("WWW-Authenticate", challenge)
+	http.Error(w, description, http.StatusForbidden)
+}
+
+// CheckAuthMiddleware es un middleware para verificar si la solicitud tiene un token JWT o una API key válidos
 func CheckAuthMiddleware(s server.Server) func(h http.Handler) http.Handler{
 	// Retornar un http.HandlerFunc
 	return func(next http.Handler) http.Handler {
 		// Retornar un http.HandlerFunc
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// Verificar si el token no debe ser verificado
-			if !shouldCheckToken(r.URL.Path) {
+			// Obtener los metadatos de autenticación de la ruta
+			auth := routeAuth(r)
+			// Verificar si la ruta es pública
+			if auth.Public {
 				// Llamar al siguiente manejador
 				next.ServeHTTP(w, r)
 				return
 			}
+			// Verificar si la solicitud usa una API key
+			if key := apiKeyFromRequest(r); key != "" {
+				checkAPIKey(w, r, next, auth, key)
+				return
+			}
 			// Obtener el token de la cabecera de autorización
-			tokenString := strings.TrimSpace(r.Header.Get("Authorization"))
+			tokenString, ok := BearerToken(r)
 			// Verificar si el token está vacío
-			_, err := jwt.ParseWithClaims(tokenString, &models.AppClaims{}, func(token *jwt.Token) (interface{}, error) {
+			if !ok {
+				// Retornar un error de no autorizado
+				unauthorized(w, "", "missing bearer token")
+				return
+			}
+			token, err := jwt.ParseWithClaims(tokenString, &models.AppClaims{}, func(token *jwt.Token) (interface{}, error) {
 				// Retornar el secreto JWT de la configuración del servidor
 				return []byte(s.Config().JWTSecret), nil
 			})
 			// Verificar si hubo un error al verificar el token
 			if err != nil {
 				// Retornar un error de no autorizado
-				http.Error(w, err.Error(), http.StatusUnauthorized)
+				unauthorized(w, "invalid_token", err.Error())
 				return
 			}
-			// Llamar al siguiente manejador
-			next.ServeHTTP(w, r)
+			// Rechazar los tokens de desafío 2FA, que solo sirven para completar el inicio de sesión
+			claims, ok := token.Claims.(*models.AppClaims)
+			if !ok || claims.MFAPending {
+				// Retornar un error de no autorizado
+				unauthorized(w, "invalid_token", "mfa verification required")
+				return
+			}
+			// Llamar al siguiente manejador con el usuario autenticado en el contexto
+			next.ServeHTTP(w, withPrincipal(r, Principal{UserID: claims.UserId}))
 		})
 	}
-}
\ No newline at end of file
+}
+
+// checkAPIKey es una función que valida una API key y su permiso para la ruta antes de llamar al siguiente manejador
+func checkAPIKey(w http.ResponseWriter, r *http.Request, next http.Handler, auth RouteAuth, key string) {
+	// Buscar la key por su hash
+	apiKey, err := repository.GetAPIKeyByHash(r.Context(), apikey.Hash(key))
+	if err != nil {
+		// Retornar un error interno del servidor
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	// Verificar que la key exista y no esté revocada
+	if apiKey == nil {
+		unauthorized(w, "invalid_token", "invalid api key")
+		return
+	}
+	// Verificar que la ruta acepte API keys
+	if auth.Scope == "" {
+		forbidden(w, "", "api keys are not allowed on this route")
+		return
+	}
+	// Verificar que la key tenga el permiso de la ruta
+	if !apikey.HasScope(apiKey.Scopes, auth.Scope) {
+		forbidden(w, auth.Scope, "api key is missing scope "+auth.Scope)
+		return
+	}
+	// Registrar el último uso de la key
+	if err := repository.TouchAPIKey(r.Context(), apiKey.Id); err != nil {
+		logging.FromContext(r.Context()).Warn("error updating api key last use", "api_key_id", apiKey.Id, "error", err)
+	}
+	// Llamar al siguiente manejador con el dueño de la key en el contexto
+	next.ServeHTTP(w, withPrincipal(r, Principal{UserID: apiKey.UserID, APIKeyID: apiKey.Id}))
+}
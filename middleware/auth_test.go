@@ -0,0 +1,143 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"talentpitchGo/apikey"
+	"talentpitchGo/middleware"
+	"talentpitchGo/models"
+	"talentpitchGo/repository"
+	"talentpitchGo/server"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+// MockServer es una implementación de server.Server para las pruebas.
+type MockServer struct{}
+
+func (s *MockServer) Config() *server.Config {
+	return &server.Config{Port: ":0", JWTSecret: "secret", DatabaseURL: "database_url"}
+}
+
+// fakeAPIKeyRepository es un repositorio de API keys en memoria.
+type fakeAPIKeyRepository struct {
+	keys    map[string]*models.APIKey
+	touched []string
+}
+
+func (f *fakeAPIKeyRepository) InsertAPIKey(ctx context.Context, key *models.APIKey) error {
+	f.keys[key.KeyHash] = key
+	return nil
+}
+
+func (f *fakeAPIKeyRepository) GetAPIKeysByUser(ctx context.Context, userId string) ([]*models.APIKey, error) {
+	return nil, nil
+}
+
+func (f *fakeAPIKeyRepository) GetAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	return f.keys[keyHash], nil
+}
+
+func (f *fakeAPIKeyRepository) RevokeAPIKey(ctx context.Context, userId string, id string) error {
+	return nil
+}
+
+func (f *fakeAPIKeyRepository) TouchAPIKey(ctx context.Context, id string) error {
+	f.touched = append(f.touched, id)
+	return nil
+}
+
+func TestCheckAuthMiddlewareAPIKeyScopes(t *testing.T) {
+	// Registrar una key con permiso de lectura de retos
+	key, prefix, err := apikey.Generate()
+	assert.NoError(t, err)
+	repo := &fakeAPIKeyRepository{keys: map[string]*models.APIKey{}}
+	repo.InsertAPIKey(context.Background(), &models.APIKey{
+		Id: "key-1", UserID: "user-1", Prefix: prefix, KeyHash: apikey.Hash(key),
+		Scopes: []string{apikey.SCOPE_CHALLENGES_READ},
+	})
+	repository.SetAPIKeyRepository(repo)
+
+	// Crear un enrutador con el middleware y rutas de prueba
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+	r := mux.NewRouter()
+	r.Use(middleware.CheckAuthMiddleware(&MockServer{}))
+	middleware.RequireScope(r.HandleFunc("/challenges", ok).Methods(http.MethodGet), apikey.SCOPE_CHALLENGES_READ)
+	middleware.RequireScope(r.HandleFunc("/challenges", ok).Methods(http.MethodPost), apikey.SCOPE_CHALLENGES_WRITE)
+	r.HandleFunc("/me", ok).Methods(http.MethodGet)
+
+	cases := []struct {
+		method string
+		path   string
+		header string
+		value  string
+		status int
+	}{
+		{http.MethodGet, "/challenges", "X-API-Key", key, http.StatusOK},
+		{http.MethodGet, "/challenges", "Authorization", "Bearer " + key, http.StatusOK},
+		{http.MethodPost, "/challenges", "X-API-Key", key, http.StatusForbidden},
+		{http.MethodGet, "/me", "X-API-Key", key, http.StatusForbidden},
+		{http.MethodGet, "/challenges", "X-API-Key", apikey.PREFIX + "unknown", http.StatusUnauthorized},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(c.method, c.path, nil)
+		req.Header.Set(c.header, c.value)
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		assert.Equal(t, c.status, rr.Code, "%s %s", c.method, c.path)
+	}
+	// Solo las solicitudes aceptadas actualizan el último uso
+	assert.Equal(t, []string{"key-1", "key-1"}, repo.touched)
+}
+
+func TestCheckAuthMiddlewarePublicRoutesAndBearer(t *testing.T) {
+	// Crear un enrutador con rutas públicas y privadas
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+	r := mux.NewRouter()
+	r.Use(middleware.CheckAuthMiddleware(&MockServer{}))
+	middleware.Public(r.HandleFunc("/", ok).Methods(http.MethodGet))
+	middleware.Public(r.HandleFunc("/login", ok).Methods(http.MethodPost))
+	r.HandleFunc("/anything/login/{rest}", ok).Methods(http.MethodGet)
+	r.HandleFunc("/me", ok).Methods(http.MethodGet)
+
+	// Firmar un token válido
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, models.AppClaims{
+		UserId:         "user-1",
+		StandardClaims: jwt.StandardClaims{ExpiresAt: time.Now().Add(time.Hour).Unix()},
+	}).SignedString([]byte("secret"))
+	assert.NoError(t, err)
+
+	cases := []struct {
+		name          string
+		method        string
+		path          string
+		authorization string
+		status        int
+		challenge     string
+	}{
+		{"home is public", http.MethodGet, "/", "", http.StatusOK, ""},
+		{"login is public", http.MethodPost, "/login", "", http.StatusOK, ""},
+		{"paths containing /login are not public", http.MethodGet, "/anything/login/x", "", http.StatusUnauthorized, `Bearer realm="talentpitch"`},
+		{"missing token", http.MethodGet, "/me", "", http.StatusUnauthorized, `Bearer realm="talentpitch"`},
+		{"token without scheme", http.MethodGet, "/me", token, http.StatusUnauthorized, `Bearer realm="talentpitch"`},
+		{"bearer token", http.MethodGet, "/me", "Bearer " + token, http.StatusOK, ""},
+		{"scheme is case insensitive", http.MethodGet, "/me", "bearer " + token, http.StatusOK, ""},
+		{"invalid token", http.MethodGet, "/me", "Bearer nope", http.StatusUnauthorized, `Bearer realm="talentpitch", error="invalid_token"`},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(c.method, c.path, nil)
+		if c.authorization != "" {
+			req.Header.Set("Authorization", c.authorization)
+		}
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		assert.Equal(t, c.status, rr.Code, c.name)
+		assert.Contains(t, rr.Header().Get("WWW-Authenticate"), c.challenge, c.name)
+	}
+}
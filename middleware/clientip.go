@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// FORWARDED_FOR_HEADER es la cabecera con la que los proxies informan la IP del cliente
+const FORWARDED_FOR_HEADER = "X-Forwarded-For"
+
+// clientIPKey es la clave de la IP del cliente en el contexto de la solicitud
+type clientIPKey struct{}
+
+// ParseTrustedProxies es una función que convierte una lista de IPs o rangos CIDR en los rangos de los proxies
+// de confianza. Una IP sin máscara es un rango con esa única dirección.
+func ParseTrustedProxies(values []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(values))
+	for _, value := range values {
+		if strings.Contains(value, "/") {
+			prefix, err := netip.ParsePrefix(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", value, err)
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", value, err)
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
+
+// ClientIP es una función que devuelve un middleware que guarda en el contexto la IP del cliente. Si la conexión
+// viene de un proxy de confianza, la IP se toma de X-Forwarded-For: la primera dirección, de derecha a izquierda,
+// que no sea de un proxy de confianza. Sin proxies de confianza la cabecera se ignora, porque cualquiera la puede enviar.
+func ClientIP(trustedProxies []netip.Prefix) func(http.Handler) http.Handler {
+	trusted := func(ip string) bool {
+		addr, err := netip.ParseAddr(ip)
+		if err != nil {
+			return false
+		}
+		addr = addr.Unmap()
+		for _, prefix := range trustedProxies {
+			if prefix.Contains(addr) {
+				return true
+			}
+		}
+		return false
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := remoteIP(r)
+			if trusted(ip) {
+				// Recorrer la cadena de proxies desde el más cercano; todas las cabeceras se tratan como una sola lista
+				var hops []string
+				for _, header := range r.Header.Values(FORWARDED_FOR_HEADER) {
+					hops = append(hops, strings.Split(header, ",")...)
+				}
+				for i := len(hops) - 1; i >= 0; i-- {
+					hop := strings.TrimSpace(hops[i])
+					if _, err := netip.ParseAddr(hop); err != nil {
+						// Una dirección inválida no es de fiar; se usa la del último proxy que la envió
+						break
+					}
+					ip = hop
+					if !trusted(hop) {
+						break
+					}
+				}
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), clientIPKey{}, ip)))
+		})
+	}
+}
+
+// RequestIP es una función que obtiene la IP del cliente de la solicitud. Si el middleware ClientIP no la guardó,
+// se usa la dirección remota de la conexión.
+func RequestIP(r *http.Request) string {
+	if ip, ok := r.Context().Value(clientIPKey{}).(string); ok {
+		return ip
+	}
+	return remoteIP(r)
+}
+
+// remoteIP es una función que obtiene la IP de la dirección remota de la conexión, sin el puerto
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
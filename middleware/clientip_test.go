@@ -0,0 +1,59 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"talentpitchGo/middleware"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientIP(t *testing.T) {
+	proxies, err := middleware.ParseTrustedProxies([]string{"10.0.0.0/8", "192.168.1.1"})
+	require.NoError(t, err)
+	_, err = middleware.ParseTrustedProxies([]string{"proxy"})
+	assert.Error(t, err)
+
+	tests := []struct {
+		name         string
+		trusted      bool
+		remoteAddr   string
+		forwardedFor []string
+		expectedIP   string
+	}{
+		{name: "no proxies ignore the header", remoteAddr: "203.0.113.1:1234", forwardedFor: []string{"198.51.100.1"}, expectedIP: "203.0.113.1"},
+		{name: "untrusted peer ignores the header", trusted: true, remoteAddr: "203.0.113.1:1234", forwardedFor: []string{"198.51.100.1"}, expectedIP: "203.0.113.1"},
+		{name: "trusted peer", trusted: true, remoteAddr: "10.0.0.1:1234", forwardedFor: []string{"198.51.100.1"}, expectedIP: "198.51.100.1"},
+		{name: "spoofed entries before the client", trusted: true, remoteAddr: "10.0.0.1:1234", forwardedFor: []string{"1.1.1.1, 198.51.100.1", "192.168.1.1"}, expectedIP: "198.51.100.1"},
+		{name: "only proxies", trusted: true, remoteAddr: "10.0.0.1:1234", forwardedFor: []string{"10.0.0.2"}, expectedIP: "10.0.0.2"},
+		{name: "invalid entry", trusted: true, remoteAddr: "10.0.0.1:1234", forwardedFor: []string{"198.51.100.1, unknown"}, expectedIP: "10.0.0.1"},
+		{name: "no header", trusted: true, remoteAddr: "10.0.0.1:1234", expectedIP: "10.0.0.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var trusted = proxies
+			if !tt.trusted {
+				trusted = nil
+			}
+			var ip string
+			handler := middleware.ClientIP(trusted)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ip = middleware.RequestIP(r)
+			}))
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for _, value := range tt.forwardedFor {
+				req.Header.Add(middleware.FORWARDED_FOR_HEADER, value)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+			assert.Equal(t, tt.expectedIP, ip)
+		})
+	}
+
+	// Sin el middleware se usa la dirección de la conexión
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "203.0.113.1:1234"
+	assert.Equal(t, "203.0.113.1", middleware.RequestIP(req))
+}
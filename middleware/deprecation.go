@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// DEPRECATION_DATE es la fecha desde la que las rutas sin versión están obsoletas
+var DEPRECATION_DATE = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+
+// Deprecated es una función que envuelve el controlador de una ruta obsoleta.
+// Responde igual que el controlador, pero agrega la cabecera Deprecation (RFC 9745) y un Link a la ruta que la reemplaza.
+// successor es la plantilla de la ruta nueva; sus variables ({id}, ...) se completan con las de la solicitud.
+func Deprecated(successor string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Completar las variables de la ruta nueva con las de la solicitud
+		link := successor
+		for name, value := range mux.Vars(r) {
+			link = strings.ReplaceAll(link, "{"+name+"}", value)
+		}
+		w.Header().Set("Deprecation", fmt.Sprintf("@%d", DEPRECATION_DATE.Unix()))
+		w.Header().Add("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", link))
+		next(w, r)
+	}
+}
@@ -0,0 +1,28 @@
+package middleware_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"talentpitchGo/middleware"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeprecated(t *testing.T) {
+	router := mux.NewRouter()
+	router.HandleFunc("/updateChallenge/{id}", middleware.Deprecated("/api/v1/challenges/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodPut, "/updateChallenge/c1", nil))
+
+	// La ruta obsoleta responde igual, con la cabecera Deprecation y el enlace a la ruta nueva
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+	assert.Equal(t, fmt.Sprintf("@%d", middleware.DEPRECATION_DATE.Unix()), rr.Header().Get("Deprecation"))
+	assert.Equal(t, `</api/v1/challenges/c1>; rel="successor-version"`, rr.Header().Get("Link"))
+}
@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"time"
+
+	"talentpitchGo/logging"
+
+	"github.com/gorilla/mux"
+	"github.com/segmentio/ksuid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// REQUEST_ID_HEADER es la cabecera con el ID de la solicitud
+const REQUEST_ID_HEADER = "X-Request-ID"
+
+// requestIDPattern son los ID de solicitud que se aceptan del cliente; los demás se reemplazan por uno nuevo
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// requestInfo es la estructura con los datos de la solicitud que se completan mientras se atiende,
+// como el usuario autenticado, para incluirlos en el registro de acceso
+type requestInfo struct {
+	id     string
+	userID string
+}
+
+// requestInfoKey es la clave de requestInfo en el contexto de la solicitud
+type requestInfoKey struct{}
+
+// RequestIDFromContext es una función que obtiene el ID de la solicitud guardado por RequestID
+func RequestIDFromContext(ctx context.Context) string {
+	if info, ok := ctx.Value(requestInfoKey{}).(*requestInfo); ok {
+		return info.id
+	}
+	return ""
+}
+
+// RequestID es un middleware que usa el ID de la cabecera X-Request-ID o genera uno nuevo, lo devuelve en la respuesta
+// y agrega al contexto un logger que incluye el ID en todos sus mensajes
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(REQUEST_ID_HEADER)
+		if !requestIDPattern.MatchString(id) {
+			id = ksuid.New().String()
+		}
+		// Dejar el ID en la solicitud para los controladores que leen la cabecera
+		r.Header.Set(REQUEST_ID_HEADER, id)
+		w.Header().Set(REQUEST_ID_HEADER, id)
+		ctx := context.WithValue(r.Context(), requestInfoKey{}, &requestInfo{id: id})
+		logger := logging.FromContext(ctx).With("request_id", id)
+		// Relacionar los mensajes con la traza de la solicitud, si el middleware Tracing abrió una
+		if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+			logger = logger.With("trace_id", spanContext.TraceID().String())
+		}
+		ctx = logging.WithLogger(ctx, logger)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// statusRecorder es un http.ResponseWriter que guarda el código y el tamaño de la respuesta
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	if s.status == 0 {
+		s.status = status
+	}
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *statusRecorder) Write(data []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	n, err := s.ResponseWriter.Write(data)
+	s.bytes += int64(n)
+	return n, err
+}
+
+// Unwrap permite que http.ResponseController acceda al http.ResponseWriter original
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
+
+// AccessLog es un middleware que registra cada solicitud con su método, la plantilla de la ruta, el código,
+// la latencia y el usuario autenticado. Debe ir después de RequestID para incluir el ID de la solicitud.
+func AccessLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(recorder, r)
+		if recorder.status == 0 {
+			recorder.status = http.StatusOK
+		}
+		attrs := []slog.Attr{
+			slog.String("method", r.Method),
+			slog.String("route", routeTemplate(r)),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", recorder.status),
+			slog.Int64("bytes", recorder.bytes),
+			slog.Duration("latency", time.Since(start)),
+		}
+		if info, ok := r.Context().Value(requestInfoKey{}).(*requestInfo); ok && info.userID != "" {
+			attrs = append(attrs, slog.String("user_id", info.userID))
+		}
+		// Los errores del servidor se registran como errores
+		level := slog.LevelInfo
+		if recorder.status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		logging.FromContext(r.Context()).LogAttrs(r.Context(), level, "request", attrs...)
+	})
+}
+
+// routeTemplate es una función que devuelve la plantilla de la ruta de la solicitud (por ejemplo /challenges/{id}),
+// para agrupar las solicitudes sin los IDs de la URL; vacía si ninguna ruta coincidió
+func routeTemplate(r *http.Request) string {
+	if current := mux.CurrentRoute(r); current != nil {
+		if route, err := current.GetPathTemplate(); err == nil {
+			return route
+		}
+	}
+	return ""
+}
@@ -0,0 +1,65 @@
+package middleware_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"talentpitchGo/logging"
+	"talentpitchGo/middleware"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+// captureLogs es una función que cambia el logger por defecto por uno que escribe en JSON en el buffer devuelto
+func captureLogs(t *testing.T) *bytes.Buffer {
+	var buf bytes.Buffer
+	logger, err := logging.New(&buf, logging.FORMAT_JSON, "debug")
+	assert.NoError(t, err)
+	previous := slog.Default()
+	slog.SetDefault(logger)
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return &buf
+}
+
+func TestRequestIDAndAccessLog(t *testing.T) {
+	buf := captureLogs(t)
+	router := mux.NewRouter()
+	router.Use(middleware.RequestID, middleware.AccessLog)
+	router.HandleFunc("/challenges/{id}", func(w http.ResponseWriter, r *http.Request) {
+		logging.FromContext(r.Context()).Info("handler")
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	// Se respeta el ID que envía el cliente
+	req := httptest.NewRequest(http.MethodGet, "/challenges/c1", nil)
+	req.Header.Set(middleware.REQUEST_ID_HEADER, "abc-123")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, "abc-123", rr.Header().Get(middleware.REQUEST_ID_HEADER))
+
+	// El mensaje del controlador y el registro de acceso llevan el mismo ID
+	decoder := json.NewDecoder(buf)
+	var handlerLine, accessLine map[string]interface{}
+	assert.NoError(t, decoder.Decode(&handlerLine))
+	assert.NoError(t, decoder.Decode(&accessLine))
+	assert.Equal(t, "abc-123", handlerLine["request_id"])
+	assert.Equal(t, "abc-123", accessLine["request_id"])
+	assert.Equal(t, "request", accessLine["msg"])
+	assert.Equal(t, "/challenges/{id}", accessLine["route"])
+	assert.Equal(t, float64(http.StatusTeapot), accessLine["status"])
+	assert.Contains(t, accessLine, "latency")
+
+	// Un ID inválido se reemplaza por uno nuevo
+	req = httptest.NewRequest(http.MethodGet, "/challenges/c1", nil)
+	req.Header.Set(middleware.REQUEST_ID_HEADER, "bad id\n")
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	id := rr.Header().Get(middleware.REQUEST_ID_HEADER)
+	assert.NotEmpty(t, id)
+	assert.NotEqual(t, "bad id\n", id)
+}
@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"talentpitchGo/metrics"
+)
+
+// Metrics es un middleware que cuenta las solicitudes y mide su latencia por método, plantilla de la ruta y código
+func Metrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(recorder, r)
+		if recorder.status == 0 {
+			recorder.status = http.StatusOK
+		}
+		metrics.ObserveRequest(r.Method, routeTemplate(r), recorder.status, time.Since(start))
+	})
+}
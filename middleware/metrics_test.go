@@ -0,0 +1,37 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"talentpitchGo/metrics"
+	"talentpitchGo/middleware"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	router := mux.NewRouter()
+	router.Use(middleware.Metrics)
+	router.HandleFunc("/companies/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	router.Handle("/metrics", metrics.Handler())
+
+	// Las solicitudes se agrupan por la plantilla de la ruta, no por la URL
+	counter := metrics.HTTP_REQUESTS.WithLabelValues(http.MethodGet, "/companies/{id}", "404")
+	before := testutil.ToFloat64(counter)
+	for _, id := range []string{"c1", "c2"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/companies/"+id, nil))
+	}
+	assert.Equal(t, before+2, testutil.ToFloat64(counter))
+
+	// El controlador expone las métricas en el formato de texto de Prometheus
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `talentpitch_http_request_duration_seconds_count{method="GET",route="/companies/{id}",status="404"}`)
+}
@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"talentpitchGo/logging"
+	"talentpitchGo/ratelimit"
+
+	"github.com/gorilla/mux"
+)
+
+// routePolicies esta variable contiene la política de límite de solicitudes de cada ruta registrada
+var (
+	routePoliciesMu sync.RWMutex
+	routePolicies   = map[*mux.Route]ratelimit.Policy{}
+)
+
+// LimitRate es una función que declara la política de límite de solicitudes de una ruta y devuelve la misma ruta
+func LimitRate(route *mux.Route, policy ratelimit.Policy) *mux.Route {
+	routePoliciesMu.Lock()
+	defer routePoliciesMu.Unlock()
+	routePolicies[route] = policy
+	return route
+}
+
+// routePolicy es una función que obtiene la política de límite de la ruta que coincidió con la solicitud
+func routePolicy(r *http.Request) (ratelimit.Policy, bool) {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return ratelimit.Policy{}, false
+	}
+	routePoliciesMu.RLock()
+	defer routePoliciesMu.RUnlock()
+	policy, ok := routePolicies[route]
+	return policy, ok
+}
+
+// RateLimit es un middleware que aplica la política de límite de la ruta a cada cliente: el usuario autenticado,
+// la API key o, en las rutas públicas, la IP que resolvió ClientIP. Devuelve las cabeceras X-RateLimit-* y responde 429 al superar el límite.
+// Debe ir después de CheckAuthMiddleware para conocer el usuario. Si el almacén falla, la solicitud se permite.
+func RateLimit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		policy, ok := routePolicy(r)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		result, err := ratelimit.TakeToken(r.Context(), policy, rateLimitClient(r))
+		if err != nil {
+			logging.FromContext(r.Context()).Error("error taking rate limit token", "policy", policy.Name, "error", err)
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		w.Header().Set("X-RateLimit-Reset", ceilSeconds(result.Reset))
+		if !result.Allowed {
+			w.Header().Set("Retry-After", ceilSeconds(result.RetryAfter))
+			http.Error(w, "Too many requests", http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// rateLimitClient es una función que devuelve la clave del cliente de la solicitud para el límite
+func rateLimitClient(r *http.Request) string {
+	if principal, ok := PrincipalFromContext(r.Context()); ok {
+		if principal.APIKeyID != "" {
+			return "key:" + principal.APIKeyID
+		}
+		return "user:" + principal.UserID
+	}
+	return "ip:" + RequestIP(r)
+}
+
+// ceilSeconds es una función que devuelve una duración en segundos enteros, redondeando hacia arriba
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
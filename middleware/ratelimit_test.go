@@ -0,0 +1,50 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"talentpitchGo/middleware"
+	"talentpitchGo/ratelimit"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimit(t *testing.T) {
+	ratelimit.SetStore(ratelimit.NewMemoryStore())
+	router := mux.NewRouter()
+	router.Use(middleware.RateLimit)
+	handler := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+	middleware.LimitRate(router.HandleFunc("/login", handler), ratelimit.Policy{Name: "login-test", Limit: 2, Period: time.Minute})
+	router.HandleFunc("/free", handler)
+
+	request := func(path string, remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		req.RemoteAddr = remoteAddr
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// Las dos primeras solicitudes de la IP se permiten con las cabeceras del límite
+	rr := request("/login", "10.0.0.1:1234")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "2", rr.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, "1", rr.Header().Get("X-RateLimit-Remaining"))
+	assert.Equal(t, http.StatusOK, request("/login", "10.0.0.1:5678").Code)
+
+	// La tercera supera el límite
+	rr = request("/login", "10.0.0.1:1234")
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "0", rr.Header().Get("X-RateLimit-Remaining"))
+	assert.Equal(t, "30", rr.Header().Get("Retry-After"))
+
+	// Otra IP y las rutas sin política no se limitan
+	assert.Equal(t, http.StatusOK, request("/login", "10.0.0.2:1234").Code)
+	rr = request("/free", "10.0.0.1:1234")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Empty(t, rr.Header().Get("X-RateLimit-Limit"))
+}
@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"talentpitchGo/logging"
+	"talentpitchGo/reporting"
+)
+
+// InternalErrorResponse es la estructura de la respuesta de un pánico recuperado
+type InternalErrorResponse struct {
+	Message   string `json:"message"`
+	RequestID string `json:"request_id"`
+}
+
+// Recover es un middleware que convierte los pánicos de los controladores en una respuesta 500 en JSON con el ID
+// de la solicitud y registra la traza de la pila. Los pánicos y las respuestas 5xx se envían al ErrorReporter.
+// Debe ir después de RequestID para incluir el ID de la solicitud.
+func Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorder := &statusRecorder{ResponseWriter: w}
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				// Enviar los errores del servidor que respondió el controlador
+				if recorder.status >= http.StatusInternalServerError {
+					reporting.Send(r.Context(), newReport(r, fmt.Errorf("%d %s", recorder.status, http.StatusText(recorder.status)), recorder.status))
+				}
+				return
+			}
+			// http.ErrAbortHandler interrumpe la respuesta a propósito y no es un error
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+			err, ok := recovered.(error)
+			if !ok {
+				err = fmt.Errorf("%v", recovered)
+			}
+			stack := debug.Stack()
+			logging.FromContext(r.Context()).Error("panic recovered", "error", err, "stack", string(stack))
+			report := newReport(r, err, http.StatusInternalServerError)
+			report.Panic, report.Stack = true, stack
+			reporting.Send(r.Context(), report)
+			// Si el controlador ya empezó a responder, no se puede cambiar la respuesta
+			if recorder.status != 0 {
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(InternalErrorResponse{
+				Message:   "internal server error",
+				RequestID: RequestIDFromContext(r.Context()),
+			})
+		}()
+		next.ServeHTTP(recorder, r)
+	})
+}
+
+// newReport es una función que crea el informe de un error del servidor con los datos de la solicitud
+func newReport(r *http.Request, err error, status int) reporting.Report {
+	report := reporting.Report{
+		Err:       err,
+		RequestID: RequestIDFromContext(r.Context()),
+		Method:    r.Method,
+		Route:     routeTemplate(r),
+		Path:      r.URL.Path,
+		Status:    status,
+	}
+	if info, ok := r.Context().Value(requestInfoKey{}).(*requestInfo); ok {
+		report.UserID = info.userID
+	}
+	return report
+}
@@ -0,0 +1,87 @@
+package middleware_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"talentpitchGo/middleware"
+	"talentpitchGo/reporting"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeReporter es un ErrorReporter que guarda los informes recibidos
+type fakeReporter struct {
+	reports []reporting.Report
+}
+
+func (f *fakeReporter) Report(ctx context.Context, report reporting.Report) {
+	f.reports = append(f.reports, report)
+}
+
+func TestRecover(t *testing.T) {
+	buf := captureLogs(t)
+	reporter := &fakeReporter{}
+	reporting.SetErrorReporter(reporter)
+	t.Cleanup(func() { reporting.SetErrorReporter(nil) })
+
+	router := mux.NewRouter()
+	router.Use(middleware.RequestID, middleware.Recover)
+	router.HandleFunc("/panic/{id}", func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+	router.HandleFunc("/fail", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Failed", http.StatusServiceUnavailable)
+	})
+	router.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	// Un pánico se convierte en un 500 en JSON con el ID de la solicitud
+	req := httptest.NewRequest(http.MethodGet, "/panic/p1", nil)
+	req.Header.Set(middleware.REQUEST_ID_HEADER, "abc-123")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	var response middleware.InternalErrorResponse
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	assert.Equal(t, "internal server error", response.Message)
+	assert.Equal(t, "abc-123", response.RequestID)
+	assert.Contains(t, buf.String(), "panic recovered")
+	assert.Contains(t, buf.String(), "stack")
+
+	// El pánico se envía al ErrorReporter con la traza de la pila
+	if assert.Len(t, reporter.reports, 1) {
+		report := reporter.reports[0]
+		assert.True(t, report.Panic)
+		assert.EqualError(t, report.Err, "boom")
+		assert.NotEmpty(t, report.Stack)
+		assert.Equal(t, "abc-123", report.RequestID)
+		assert.Equal(t, "/panic/{id}", report.Route)
+		assert.Equal(t, "/panic/p1", report.Path)
+		assert.Equal(t, http.StatusInternalServerError, report.Status)
+	}
+
+	// Las respuestas 5xx también se envían, sin pánico
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/fail", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	if assert.Len(t, reporter.reports, 2) {
+		report := reporter.reports[1]
+		assert.False(t, report.Panic)
+		assert.True(t, strings.HasPrefix(report.Err.Error(), "503"))
+		assert.Equal(t, http.StatusServiceUnavailable, report.Status)
+	}
+
+	// Los errores del cliente no se envían
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/ok", nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Len(t, reporter.reports, 2)
+}
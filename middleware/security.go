@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Valores por defecto de CORS
+var (
+	CORS_ALLOWED_METHODS = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+	CORS_ALLOWED_HEADERS = []string{"Authorization", "Content-Type", "If-Match", "If-None-Match", "X-API-Key", REQUEST_ID_HEADER, "traceparent", "tracestate"}
+	// CORS_EXPOSED_HEADERS son las cabeceras de las respuestas que puede leer el código del navegador
+	CORS_EXPOSED_HEADERS = []string{"ETag", "Location", "Link", "Deprecation", REQUEST_ID_HEADER, "Retry-After", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset"}
+)
+
+// HSTS es el valor de la cabecera Strict-Transport-Security: un año, incluidos los subdominios
+const HSTS = "max-age=31536000; includeSubDomains"
+
+// CORSConfig es la estructura de la configuración de CORS
+type CORSConfig struct {
+	AllowedOrigins   []string      // Orígenes permitidos; "*" permite cualquiera, sin credenciales. Vacío desactiva CORS
+	AllowedMethods   []string      // Métodos permitidos; CORS_ALLOWED_METHODS si está vacío
+	AllowedHeaders   []string      // Cabeceras permitidas en las solicitudes; CORS_ALLOWED_HEADERS si está vacío
+	AllowCredentials bool          // Permitir cookies y cabeceras de autorización del navegador
+	MaxAge           time.Duration // Tiempo que el navegador guarda la respuesta de la verificación previa
+}
+
+// CORS es una función que devuelve un middleware que agrega las cabeceras CORS a las solicitudes de los orígenes
+// permitidos y responde las verificaciones previas (OPTIONS). Como las rutas no registran OPTIONS, el middleware
+// también se usa como MethodNotAllowedHandler del enrutador para atender las verificaciones previas.
+func CORS(config CORSConfig) func(http.Handler) http.Handler {
+	if len(config.AllowedMethods) == 0 {
+		config.AllowedMethods = CORS_ALLOWED_METHODS
+	}
+	if len(config.AllowedHeaders) == 0 {
+		config.AllowedHeaders = CORS_ALLOWED_HEADERS
+	}
+	allowed := map[string]bool{}
+	for _, origin := range config.AllowedOrigins {
+		allowed[strings.TrimRight(origin, "/")] = true
+	}
+	methods := strings.Join(config.AllowedMethods, ", ")
+	headers := strings.Join(config.AllowedHeaders, ", ")
+	exposed := strings.Join(CORS_EXPOSED_HEADERS, ", ")
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			// La respuesta depende del origen, aunque no se permita
+			w.Header().Add("Vary", "Origin")
+			if origin == "" || (!allowed["*"] && !allowed[origin]) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			// Las credenciales solo se permiten a los orígenes listados: con "*" cualquier sitio podría hacer
+			// solicitudes autenticadas en nombre del usuario (server.NewServer rechaza esa configuración)
+			if allowed["*"] {
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+			} else {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+				if config.AllowCredentials {
+					w.Header().Set("Access-Control-Allow-Credentials", "true")
+				}
+			}
+			// Verificación previa
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				w.Header().Add("Vary", "Access-Control-Request-Method")
+				w.Header().Add("Vary", "Access-Control-Request-Headers")
+				w.Header().Set("Access-Control-Allow-Methods", methods)
+				w.Header().Set("Access-Control-Allow-Headers", headers)
+				if config.MaxAge > 0 {
+					w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(config.MaxAge.Seconds())))
+				}
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			w.Header().Set("Access-Control-Expose-Headers", exposed)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// SecurityHeaders es un middleware que agrega las cabeceras de seguridad a todas las respuestas:
+// HSTS, X-Content-Type-Options: nosniff, X-Frame-Options: DENY y Referrer-Policy
+func SecurityHeaders(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Strict-Transport-Security", HSTS)
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("X-Frame-Options", "DENY")
+		w.Header().Set("Referrer-Policy", "no-referrer")
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,41 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"talentpitchGo/middleware"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCORSWithCredentialsEchoesOrigin(t *testing.T) {
+	handler := middleware.CORS(middleware.CORSConfig{AllowedOrigins: []string{"https://app.example.com"}, AllowCredentials: true})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	// Con credenciales se devuelve el origen permitido de la solicitud
+	req := httptest.NewRequest(http.MethodGet, "/challenges", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "https://app.example.com", rr.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", rr.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Contains(t, rr.Header().Get("Access-Control-Expose-Headers"), "ETag")
+	assert.Contains(t, rr.Header().Values("Vary"), "Origin")
+
+	// Con "*" nunca se permiten credenciales, aunque la configuración las pida
+	handler = middleware.CORS(middleware.CORSConfig{AllowedOrigins: []string{"*"}, AllowCredentials: true})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, "*", rr.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, rr.Header().Get("Access-Control-Allow-Credentials"))
+
+	// Sin orígenes configurados no se agregan cabeceras CORS
+	handler = middleware.CORS(middleware.CORSConfig{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Empty(t, rr.Header().Get("Access-Control-Allow-Origin"))
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+
+	"talentpitchGo/tracing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing es un middleware que abre un span por solicitud con el nombre del método y la plantilla de la ruta,
+// como hijo de la traza de la cabecera traceparent si el cliente la envía. Debe ser el primer middleware del enrutador.
+func Tracing(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Continuar la traza del cliente
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		route := routeTemplate(r)
+		name := r.Method + " " + route
+		if route == "" {
+			name = r.Method
+		}
+		ctx, span := tracing.Tracer().Start(ctx, name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(r.Method),
+				semconv.HTTPRoute(route),
+				semconv.URLPath(r.URL.Path),
+			),
+		)
+		defer span.End()
+
+		recorder := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+		if recorder.status == 0 {
+			recorder.status = http.StatusOK
+		}
+		span.SetAttributes(semconv.HTTPResponseStatusCode(recorder.status))
+		// Solo los errores del servidor marcan el span como fallido
+		if recorder.status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(recorder.status))
+		}
+	})
+}
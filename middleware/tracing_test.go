@@ -0,0 +1,46 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"talentpitchGo/middleware"
+	"talentpitchGo/tracing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tracing.Install(sdktrace.NewSimpleSpanProcessor(exporter))
+
+	router := mux.NewRouter()
+	router.Use(middleware.Tracing)
+	router.HandleFunc("/challenges/{id}", func(w http.ResponseWriter, r *http.Request) {
+		// El controlador recibe el span de la solicitud en el contexto
+		assert.True(t, trace.SpanContextFromContext(r.Context()).IsValid())
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	// La solicitud continúa la traza de la cabecera traceparent
+	req := httptest.NewRequest(http.MethodGet, "/challenges/c1", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 1)
+	span := spans[0]
+	assert.Equal(t, "GET /challenges/{id}", span.Name)
+	assert.Equal(t, trace.SpanKindServer, span.SpanKind)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", span.Parent.SpanID().String())
+	assert.Contains(t, span.Attributes, attribute.Int("http.response.status_code", http.StatusInternalServerError))
+	assert.Equal(t, codes.Error, span.Status.Code)
+}
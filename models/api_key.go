@@ -0,0 +1,16 @@
+// Descripcion: En este archivo se define la estructura de las API keys
+package models
+
+import "time"
+
+// APIKey es la estructura de una API key para integraciones entre servidores (solo se guarda su hash)
+type APIKey struct {
+	Id         string     `json:"id"`
+	UserID     string     `json:"user_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	KeyHash    string     `json:"-"`
+	Scopes     []string   `json:"scopes"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
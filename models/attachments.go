@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// Attachment es una estructura que representa un archivo adjunto de un reto, como un enunciado, un conjunto de datos o código inicial.
+type Attachment struct {
+	Id          string    `json:"id"`
+	ChallengeID string    `json:"challenge_id"`
+	UserID      string    `json:"user_id"` // Usuario que subió el archivo
+	Filename    string    `json:"filename" validate:"required,max=255"`
+	ContentType string    `json:"content_type" validate:"required,max=255"`
+	Size        int64     `json:"size"`
+	BlobKey     string    `json:"-"`            // Clave del archivo en el almacén; no se expone
+	DownloadURL string    `json:"download_url"` // Ruta de descarga; no se guarda en la base de datos
+	CreatedAt   time.Time `json:"created_at"`
+}
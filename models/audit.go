@@ -0,0 +1,45 @@
+// Descripcion: En este archivo se define la estructura del registro de auditoría
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditEntry es la estructura de una entrada del registro de auditoría
+type AuditEntry struct {
+	Id         string          `json:"id"`
+	ActorID    string          `json:"actor_id,omitempty"`   // Usuario que hizo el cambio; vacío en los registros públicos
+	APIKeyID   string          `json:"api_key_id,omitempty"` // API key usada, si la hubo
+	Action     string          `json:"action"`
+	EntityType string          `json:"entity_type"`
+	EntityID   string          `json:"entity_id"`
+	Before     json.RawMessage `json:"before,omitempty"` // Campos modificados antes del cambio
+	After      json.RawMessage `json:"after,omitempty"`  // Campos modificados después del cambio
+	RequestID  string          `json:"request_id,omitempty"`
+	IP         string          `json:"ip,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// AuditFilter es la estructura de los filtros de consulta del registro de auditoría
+type AuditFilter struct {
+	EntityType string
+	EntityID   string
+	ActorID    string
+	From       *time.Time
+	To         *time.Time
+}
+
+// Acciones y entidades del registro de auditoría
+const (
+	AUDIT_CREATE  = "create"
+	AUDIT_UPDATE  = "update"
+	AUDIT_DELETE  = "delete"
+	AUDIT_RESTORE = "restore"
+
+	ENTITY_USER       = "user"
+	ENTITY_CHALLENGE  = "challenge"
+	ENTITY_COMPANY    = "company"
+	ENTITY_PROGRAM    = "program"
+	ENTITY_ATTACHMENT = "attachment"
+)
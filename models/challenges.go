@@ -1,11 +1,20 @@
 package models
 
-
+import "time"
 
 type Challenge struct {
     Id          string    `json:"id"`
-    Title       string    `json:"title"`
-    Description string    `json:"description"`
-    Difficulty  int       `json:"difficulty"`
-    UserID      string    `json:"user_id"`
-}
\ No newline at end of file
+    Title       string    `json:"title" validate:"required,max=255"`
+    Description string    `json:"description" validate:"required"`
+    Difficulty  int       `json:"difficulty" validate:"required,difficulty"`
+    UserID      string    `json:"user_id" validate:"required"`
+    Version     int       `json:"version"` // Versión de la fila, se incrementa en cada modificación
+    CreatedAt   time.Time `json:"created_at"`
+    UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ChallengeFilter es una estructura con los filtros de la consulta de retos; los campos vacíos no filtran.
+type ChallengeFilter struct {
+	UserID       string // Retos del usuario dado
+	Difficulties []int  // Retos con alguno de los niveles de dificultad dados
+}
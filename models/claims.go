@@ -6,5 +6,16 @@ import "github.com/golang-jwt/jwt"
 // AppClaims es la estructura de los claims del token JWT
 type AppClaims struct {
 	UserId string `json:"user_id"`
+	// MFAPending indica que el token es un desafío 2FA y solo sirve para completar el inicio de sesión
+	MFAPending bool `json:"mfa_pending,omitempty"`
 	jwt.StandardClaims 
-}
\ No newline at end of file
+}
+
+// OAuthStateClaims es la estructura de los claims de la cookie que guarda el state y el code_verifier PKCE
+// durante el inicio de sesión con un proveedor externo
+type OAuthStateClaims struct {
+	Provider string `json:"provider"`
+	State    string `json:"state"`
+	Verifier string `json:"verifier"`
+	jwt.StandardClaims
+}
@@ -1,11 +1,16 @@
 package models
 
+import "time"
+
 // Company es una estructura que representa una empresa.
 type Company struct {
 	Id        string `json:"id"`
-	Name      string `json:"name"`
-	ImagePath string `json:"image_path"`
-	Location  string `json:"location"`
-	Industry  string `json:"industry"`
-	UserID    string `json:"user_id"`
+	Name      string `json:"name" validate:"required,max=255"`
+	ImagePath string `json:"image_path" validate:"max=255"`
+	Location  string `json:"location" validate:"required,max=255"`
+	Industry  string `json:"industry" validate:"required,max=255"`
+	UserID    string `json:"user_id" validate:"required"`
+	Version   int    `json:"version"` // Versión de la fila, se incrementa en cada modificación
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
\ No newline at end of file
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"talentpitchGo/validation"
+)
+
+// Niveles de dificultad de los retos, de 1 a 5. El 0 significa que falta la dificultad.
+const (
+	DIFFICULTY_BEGINNER     = 1
+	DIFFICULTY_EASY         = 2
+	DIFFICULTY_INTERMEDIATE = 3
+	DIFFICULTY_ADVANCED     = 4
+	DIFFICULTY_EXPERT       = 5
+)
+
+// Difficulty es una estructura que describe un nivel de dificultad de los retos.
+type Difficulty struct {
+	Level       int    `json:"level"`       // Valor que se guarda en el reto
+	Name        string `json:"name"`        // Nombre estable del nivel, para los clientes
+	Label       string `json:"label"`       // Nombre para mostrar
+	Description string `json:"description"` // Descripción del nivel
+}
+
+// DifficultyStats es una estructura con la cantidad de retos de un nivel de dificultad.
+type DifficultyStats struct {
+	Difficulty
+	Count int `json:"count"`
+}
+
+// DIFFICULTIES es la escala de dificultad de los retos, ordenada por nivel
+var DIFFICULTIES = []Difficulty{
+	{Level: DIFFICULTY_BEGINNER, Name: "beginner", Label: "Principiante", Description: "Sin experiencia previa; conceptos básicos."},
+	{Level: DIFFICULTY_EASY, Name: "easy", Label: "Fácil", Description: "Conocimientos básicos y problemas acotados."},
+	{Level: DIFFICULTY_INTERMEDIATE, Name: "intermediate", Label: "Intermedio", Description: "Experiencia práctica y varios conceptos combinados."},
+	{Level: DIFFICULTY_ADVANCED, Name: "advanced", Label: "Avanzado", Description: "Experiencia sólida; problemas abiertos o de diseño."},
+	{Level: DIFFICULTY_EXPERT, Name: "expert", Label: "Experto", Description: "Dominio del tema; problemas complejos y poco definidos."},
+}
+
+// ValidDifficulty es una función que indica si el nivel pertenece a la escala de dificultad.
+func ValidDifficulty(level int) bool {
+	return level >= DIFFICULTY_BEGINNER && level <= DIFFICULTY_EXPERT
+}
+
+// ParseDifficulty es una función que obtiene el nivel de dificultad a partir de su número o de su nombre.
+func ParseDifficulty(value string) (int, bool) {
+	value = strings.TrimSpace(value)
+	if level, err := strconv.Atoi(value); err == nil {
+		return level, ValidDifficulty(level)
+	}
+	for _, difficulty := range DIFFICULTIES {
+		if strings.EqualFold(difficulty.Name, value) {
+			return difficulty.Level, true
+		}
+	}
+	return 0, false
+}
+
+// init registra la regla "difficulty" de la etiqueta validate
+func init() {
+	validation.RegisterRule("difficulty", func(value reflect.Value, param string) string {
+		if value.Kind() == reflect.Int && ValidDifficulty(int(value.Int())) {
+			return ""
+		}
+		return fmt.Sprintf("must be a difficulty level between %d and %d", DIFFICULTY_BEGINNER, DIFFICULTY_EXPERT)
+	})
+}
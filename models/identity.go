@@ -0,0 +1,11 @@
+// Descripcion: En este archivo se define la estructura de las identidades externas de los usuarios
+package models
+
+// Identity es la estructura que vincula una cuenta de un proveedor externo (google, github, ...) con un usuario
+type Identity struct {
+	Id       string `json:"id"`
+	UserID   string `json:"user_id"`
+	Provider string `json:"provider"`
+	Subject  string `json:"subject"`
+	Email    string `json:"email"`
+}
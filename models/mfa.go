@@ -0,0 +1,19 @@
+// Descripcion: En este archivo se definen las estructuras de la autenticación de dos factores
+package models
+
+import "time"
+
+// UserMFA es la estructura de la configuración TOTP de un usuario
+type UserMFA struct {
+	UserID  string `json:"user_id"`
+	Secret  string `json:"-"`
+	Enabled bool   `json:"enabled"`
+}
+
+// RecoveryCode es la estructura de un código de recuperación de un solo uso (solo se guarda su hash)
+type RecoveryCode struct {
+	Id       string     `json:"id"`
+	UserID   string     `json:"user_id"`
+	CodeHash string     `json:"-"`
+	UsedAt   *time.Time `json:"used_at"`
+}
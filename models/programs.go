@@ -5,9 +5,20 @@ import "time"
 // Program es una estructura que representa un programa de formación.
 type Program struct {
 	Id          string    `json:"id"`
-	Title       string    `json:"title"`
+	Title       string    `json:"title" validate:"required,max=255"`
 	Description string    `json:"description"`
 	StartDate   time.Time `json:"start_date"`
 	EndDate     time.Time `json:"end_date"`
-	UserID      string    `json:"user_id"`
-}
\ No newline at end of file
+	UserID      string    `json:"user_id" validate:"required"`
+	Version     int       `json:"version"` // Versión de la fila, se incrementa en cada modificación
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ProgramParticipant es una estructura que representa la inscripción de un usuario, un reto o una empresa en un programa.
+type ProgramParticipant struct {
+	Id         string `json:"id"`
+	ProgramID  string `json:"program_id"`
+	EntityType string `json:"entity_type"` // ENTITY_USER, ENTITY_CHALLENGE o ENTITY_COMPANY
+	EntityID   string `json:"entity_id"`
+}
@@ -1,10 +1,22 @@
 // Descripcion: En este archivo se define la estructura de los usuarios
 package models
 
+import "time"
+
 // User es la estructura de los datos de un usuario
 type User struct {
 	Id       string  `json:"id"`
-	Fullname string `json:"fullname"`
-	Email    string `json:"email"`
-	Password string `json:"password"`
-}
\ No newline at end of file
+	Fullname string `json:"fullname" validate:"required,max=255"`
+	Email    string `json:"email" validate:"required,email,max=255"`
+	Password string `json:"-"` // Hash de la contraseña; nunca se serializa
+	Role     string `json:"role"`
+	AvatarPath string `json:"avatar_path" validate:"max=255"` // URL del avatar; se asigna al subir la imagen
+	Version  int    `json:"version"` // Versión de la fila, se incrementa en cada modificación
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ROLE_ADMIN es el rol de los usuarios que pueden usar las rutas de administración
+const (
+	ROLE_ADMIN = "admin"
+)
\ No newline at end of file
@@ -0,0 +1,53 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore es un almacén que guarda las cubetas en la memoria del proceso. Los límites no se comparten
+// entre instancias; para eso se usa el almacén de la base de datos.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*memoryBucket
+	swept   time.Time // Última limpieza de las cubetas llenas
+}
+
+// memoryBucket es la estructura de una cubeta en memoria con el período de su política, para saber cuándo está llena
+type memoryBucket struct {
+	Bucket
+	period time.Duration
+}
+
+// NewMemoryStore es una función que crea un almacén de cubetas en memoria.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: map[string]*memoryBucket{}}
+}
+
+// TakeToken es una función que toma una ficha de la cubeta de la clave.
+func (m *MemoryStore) TakeToken(ctx context.Context, key string, policy Policy, now time.Time) (Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sweep(now)
+	bucket, ok := m.buckets[key]
+	if !ok {
+		bucket = &memoryBucket{period: policy.Period}
+		m.buckets[key] = bucket
+	}
+	return bucket.Take(policy, now), nil
+}
+
+// sweep es una función que elimina, como mucho una vez por minuto, las cubetas que ya se llenaron de nuevo:
+// son iguales a una cubeta nueva y solo ocupan memoria
+func (m *MemoryStore) sweep(now time.Time) {
+	if now.Sub(m.swept) < time.Minute {
+		return
+	}
+	m.swept = now
+	for key, bucket := range m.buckets {
+		if now.Sub(bucket.UpdatedAt) >= bucket.period {
+			delete(m.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+// El paquete ratelimit limita la cantidad de solicitudes de cada cliente con cubetas de fichas (token bucket)
+// guardadas detrás de la interfaz Store, en memoria o en la base de datos para compartirlas entre instancias.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+)
+
+// Policy es la estructura de una política de límite: Limit solicitudes por Period, que se recuperan de forma continua.
+// Las rutas con la misma política comparten la cubeta de cada cliente.
+type Policy struct {
+	Name   string        // Nombre de la política; forma parte de la clave de la cubeta
+	Limit  int           // Capacidad de la cubeta: solicitudes seguidas que se permiten
+	Period time.Duration // Tiempo en que la cubeta vacía se vuelve a llenar
+}
+
+// Result es la estructura del resultado de tomar una ficha de la cubeta
+type Result struct {
+	Allowed    bool          // La solicitud se permite
+	Limit      int           // Capacidad de la cubeta
+	Remaining  int           // Fichas que quedan después de esta solicitud
+	Reset      time.Duration // Tiempo hasta que la cubeta vuelva a estar llena
+	RetryAfter time.Duration // Tiempo hasta la siguiente ficha; 0 si la solicitud se permite
+}
+
+// Bucket es la estructura del estado de una cubeta
+type Bucket struct {
+	Tokens    float64   // Fichas disponibles en UpdatedAt
+	UpdatedAt time.Time // Momento del último cálculo de las fichas
+}
+
+// Store es una interfaz que define el almacén de las cubetas.
+type Store interface {
+	// TakeToken es una función que recupera las fichas de la cubeta de la clave hasta now y toma una si hay.
+	// Una clave sin cubeta empieza con la cubeta llena. Debe ser atómica entre solicitudes concurrentes.
+	TakeToken(ctx context.Context, key string, policy Policy, now time.Time) (Result, error)
+}
+
+// Sweeper es una interfaz de los almacenes que necesitan eliminar sus cubetas llenas fuera de TakeToken, como el
+// de la base de datos. El almacén en memoria las elimina por su cuenta.
+type Sweeper interface {
+	// DeleteFullBuckets es una función que elimina las cubetas que ya estaban llenas en now y devuelve cuántas eliminó.
+	DeleteFullBuckets(ctx context.Context, now time.Time) (int64, error)
+}
+
+// implementationStore es una variable que contiene la implementación de la interfaz Store.
+var implementationStore Store
+
+// SetStore es una función que establece la implementación de la interfaz Store.
+func SetStore(store Store) {
+	// Establecer la implementación de la interfaz Store
+	implementationStore = store
+}
+
+// TakeToken es una función que toma una ficha de la cubeta del cliente para la política dada.
+func TakeToken(ctx context.Context, policy Policy, client string) (Result, error) {
+	// Verificar que implementationStore no sea nil
+	if implementationStore == nil {
+		return Result{}, errors.New("implementationStore cannot be nil")
+	}
+	// Verificar que la política sea válida
+	if policy.Limit <= 0 || policy.Period <= 0 {
+		return Result{}, errors.New("rate limit policy must have a positive limit and period")
+	}
+	// Llamar a la función TakeToken de la implementación
+	return implementationStore.TakeToken(ctx, policy.Name+":"+client, policy, time.Now())
+}
+
+// DeleteFullBuckets es una función que elimina las cubetas llenas del almacén configurado, si las guarda fuera
+// de la memoria. Una cubeta eliminada es igual a una nueva, así que no cambia los límites.
+func DeleteFullBuckets(ctx context.Context) (int64, error) {
+	sweeper, ok := implementationStore.(Sweeper)
+	if !ok {
+		return 0, nil
+	}
+	return sweeper.DeleteFullBuckets(ctx, time.Now())
+}
+
+// Take es una función que recupera las fichas de la cubeta hasta now según la política y toma una si hay.
+// Los almacenes la usan para que la cuenta sea la misma en todas las implementaciones.
+func (b *Bucket) Take(policy Policy, now time.Time) Result {
+	capacity := float64(policy.Limit)
+	// Fichas que se recuperan por segundo
+	rate := capacity / policy.Period.Seconds()
+	// Una cubeta nueva empieza llena
+	if b.UpdatedAt.IsZero() {
+		b.Tokens = capacity
+	} else if elapsed := now.Sub(b.UpdatedAt).Seconds(); elapsed > 0 {
+		b.Tokens = math.Min(capacity, b.Tokens+elapsed*rate)
+	}
+	if now.After(b.UpdatedAt) {
+		b.UpdatedAt = now
+	}
+	result := Result{Limit: policy.Limit}
+	if b.Tokens >= 1 {
+		b.Tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((1 - b.Tokens) / rate)
+	}
+	result.Remaining = int(b.Tokens)
+	result.Reset = seconds((capacity - b.Tokens) / rate)
+	return result
+}
+
+// seconds es una función que convierte una cantidad de segundos en una duración
+func seconds(value float64) time.Duration {
+	return time.Duration(value * float64(time.Second))
+}
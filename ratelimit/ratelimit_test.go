@@ -0,0 +1,50 @@
+package ratelimit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"talentpitchGo/ratelimit"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStoreRefillsOverTime(t *testing.T) {
+	store := ratelimit.NewMemoryStore()
+	policy := ratelimit.Policy{Name: "test", Limit: 2, Period: 2 * time.Second}
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Una cubeta nueva permite Limit solicitudes seguidas
+	result, err := store.TakeToken(context.Background(), "ip:1", policy, now)
+	assert.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 1, result.Remaining)
+	result, _ = store.TakeToken(context.Background(), "ip:1", policy, now)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+	assert.Equal(t, 2*time.Second, result.Reset)
+
+	// Sin fichas se rechaza e indica cuándo habrá una
+	result, _ = store.TakeToken(context.Background(), "ip:1", policy, now.Add(500*time.Millisecond))
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 500*time.Millisecond, result.RetryAfter)
+
+	// Otro cliente tiene su propia cubeta
+	result, _ = store.TakeToken(context.Background(), "ip:2", policy, now)
+	assert.True(t, result.Allowed)
+
+	// Después de un segundo se recupera una ficha
+	result, _ = store.TakeToken(context.Background(), "ip:1", policy, now.Add(time.Second))
+	assert.True(t, result.Allowed)
+}
+
+func TestTakeTokenRequiresStoreAndValidPolicy(t *testing.T) {
+	ratelimit.SetStore(ratelimit.NewMemoryStore())
+	_, err := ratelimit.TakeToken(context.Background(), ratelimit.Policy{Name: "empty"}, "ip:1")
+	assert.Error(t, err)
+
+	result, err := ratelimit.TakeToken(context.Background(), ratelimit.Policy{Name: "ok", Limit: 1, Period: time.Minute}, "ip:1")
+	assert.NoError(t, err)
+	assert.True(t, result.Allowed)
+}
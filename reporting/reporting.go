@@ -0,0 +1,50 @@
+// El paquete reporting envía los pánicos y los errores del servidor (5xx) a un servicio externo de seguimiento
+// de errores detrás de la interfaz ErrorReporter. Por defecto no se envían a ninguna parte.
+package reporting
+
+import (
+	"context"
+)
+
+// Report es la estructura de un error del servidor
+type Report struct {
+	Err       error  // Error o pánico recuperado
+	Stack     []byte // Traza de la pila del pánico; vacía si no es un pánico
+	Panic     bool   // El error es un pánico recuperado
+	RequestID string // ID de la solicitud
+	Method    string // Método HTTP
+	Route     string // Plantilla de la ruta
+	Path      string // Ruta de la URL
+	Status    int    // Código de la respuesta
+	UserID    string // Usuario autenticado; vacío si no lo hay
+}
+
+// ErrorReporter es una interfaz que define el envío de los errores a un servicio de seguimiento.
+type ErrorReporter interface {
+	// Report es una función que envía el error. No debe bloquear la solicitud por mucho tiempo.
+	Report(ctx context.Context, report Report)
+}
+
+// NoopReporter es un ErrorReporter que descarta los errores
+type NoopReporter struct{}
+
+// Report es una función que descarta el error.
+func (NoopReporter) Report(ctx context.Context, report Report) {}
+
+// implementationErrorReporter es una variable que contiene la implementación de la interfaz ErrorReporter.
+var implementationErrorReporter ErrorReporter = NoopReporter{}
+
+// SetErrorReporter es una función que establece la implementación de la interfaz ErrorReporter.
+// Con nil se vuelve a descartar los errores.
+func SetErrorReporter(reporter ErrorReporter) {
+	if reporter == nil {
+		reporter = NoopReporter{}
+	}
+	// Establecer la implementación de la interfaz ErrorReporter
+	implementationErrorReporter = reporter
+}
+
+// Send es una función que envía el error al ErrorReporter configurado.
+func Send(ctx context.Context, report Report) {
+	implementationErrorReporter.Report(ctx, report)
+}
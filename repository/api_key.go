@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"talentpitchGo/models"
+)
+
+// APIKeyRepository es una interfaz que define las operaciones de base de datos para las API keys.
+type APIKeyRepository interface {
+	// InsertAPIKey es una función que inserta una nueva API key en la base de datos.
+	InsertAPIKey(ctx context.Context, key *models.APIKey) error
+	// GetAPIKeysByUser es una función que obtiene las API keys activas de un usuario.
+	GetAPIKeysByUser(ctx context.Context, userId string) ([]*models.APIKey, error)
+	// GetAPIKeyByHash es una función que obtiene una API key activa por su hash, o nil si no existe.
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error)
+	// RevokeAPIKey es una función que revoca una API key de un usuario.
+	RevokeAPIKey(ctx context.Context, userId string, id string) error
+	// TouchAPIKey es una función que actualiza la fecha de último uso de una API key.
+	TouchAPIKey(ctx context.Context, id string) error
+}
+
+// ErrAPIKeyNotFound es el error que se devuelve cuando la API key no existe o no pertenece al usuario.
+var ErrAPIKeyNotFound = errors.New("api key not found")
+
+// implementationAPIKey es una variable que contiene la implementación de la interfaz APIKeyRepository.
+var implementationAPIKey APIKeyRepository
+
+// SetAPIKeyRepository es una función que establece la implementación de la interfaz APIKeyRepository.
+func SetAPIKeyRepository(repo APIKeyRepository) {
+	// Establecer la implementación de la interfaz APIKeyRepository
+	implementationAPIKey = repo
+}
+
+// InsertAPIKey es una función que inserta una nueva API key en la base de datos.
+func InsertAPIKey(ctx context.Context, key *models.APIKey) error {
+	// Verificar que implementationAPIKey no sea nil
+	if implementationAPIKey == nil {
+		return errors.New("implementationAPIKey cannot be nil")
+	}
+	// Verificar que la key no sea nil
+	if key == nil {
+		return errors.New("api key cannot be nil")
+	}
+	// Verificar que los campos necesarios de la key no estén vacíos
+	if key.UserID == "" {
+		return errors.New("api key user_id cannot be empty")
+	}
+	if key.Name == "" {
+		return errors.New("api key name cannot be empty")
+	}
+	if key.KeyHash == "" {
+		return errors.New("api key hash cannot be empty")
+	}
+	if len(key.Scopes) == 0 {
+		return errors.New("api key scopes cannot be empty")
+	}
+	// Insertar la key en la base de datos
+	return implementationAPIKey.InsertAPIKey(ctx, key)
+}
+
+// GetAPIKeysByUser es una función que obtiene las API keys activas de un usuario.
+func GetAPIKeysByUser(ctx context.Context, userId string) ([]*models.APIKey, error) {
+	// Verificar que implementationAPIKey no sea nil
+	if implementationAPIKey == nil {
+		return nil, errors.New("implementationAPIKey cannot be nil")
+	}
+	// Obtener las keys del usuario
+	return implementationAPIKey.GetAPIKeysByUser(ctx, userId)
+}
+
+// GetAPIKeyByHash es una función que obtiene una API key activa por su hash, o nil si no existe.
+func GetAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	// Verificar que implementationAPIKey no sea nil
+	if implementationAPIKey == nil {
+		return nil, errors.New("implementationAPIKey cannot be nil")
+	}
+	// Obtener la key por su hash
+	return implementationAPIKey.GetAPIKeyByHash(ctx, keyHash)
+}
+
+// RevokeAPIKey es una función que revoca una API key de un usuario.
+func RevokeAPIKey(ctx context.Context, userId string, id string) error {
+	// Verificar que implementationAPIKey no sea nil
+	if implementationAPIKey == nil {
+		return errors.New("implementationAPIKey cannot be nil")
+	}
+	// Revocar la key
+	return implementationAPIKey.RevokeAPIKey(ctx, userId, id)
+}
+
+// TouchAPIKey es una función que actualiza la fecha de último uso de una API key.
+func TouchAPIKey(ctx context.Context, id string) error {
+	// Verificar que implementationAPIKey no sea nil
+	if implementationAPIKey == nil {
+		return errors.New("implementationAPIKey cannot be nil")
+	}
+	// Actualizar la fecha de último uso
+	return implementationAPIKey.TouchAPIKey(ctx, id)
+}
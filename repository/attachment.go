@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"talentpitchGo/models"
+	"talentpitchGo/validation"
+)
+
+// AttachmentRepository es una interfaz que define las operaciones de base de datos para los archivos adjuntos de los retos.
+type AttachmentRepository interface {
+	// InsertAttachment es una función que inserta un archivo adjunto.
+	InsertAttachment(ctx context.Context, attachment *models.Attachment) error
+	// GetAttachments es una función que obtiene los archivos adjuntos de un reto, del más antiguo al más nuevo.
+	GetAttachments(ctx context.Context, challengeID string) ([]*models.Attachment, error)
+	// GetAttachmentById es una función que obtiene un archivo adjunto de un reto; devuelve ErrNotFound si no existe.
+	GetAttachmentById(ctx context.Context, challengeID string, id string) (*models.Attachment, error)
+	// DeleteAttachment es una función que elimina un archivo adjunto de un reto; devuelve ErrNotFound si no existe.
+	DeleteAttachment(ctx context.Context, challengeID string, id string) error
+}
+
+// implementationAttachment es una variable que contiene la implementación de la interfaz AttachmentRepository.
+var implementationAttachment AttachmentRepository
+
+// SetAttachmentRepository es una función que establece la implementación de la interfaz AttachmentRepository.
+func SetAttachmentRepository(repo AttachmentRepository) {
+	// Establecer la implementación de la interfaz AttachmentRepository
+	implementationAttachment = repo
+}
+
+// InsertAttachment es una función que inserta un archivo adjunto.
+func InsertAttachment(ctx context.Context, attachment *models.Attachment) error {
+	// Verificar que implementationAttachment no sea nil
+	if implementationAttachment == nil {
+		return errors.New("implementationAttachment cannot be nil")
+	}
+	// Verificar que el archivo adjunto no sea nil
+	if attachment == nil {
+		return errors.New("attachment cannot be nil")
+	}
+	// Verificar que el archivo pertenezca a un reto y esté guardado
+	if attachment.Id == "" || attachment.ChallengeID == "" || attachment.BlobKey == "" {
+		return errors.New("attachment id, challenge_id and blob key cannot be empty")
+	}
+	// Verificar los campos con las reglas de su etiqueta validate
+	if err := validation.Validate(attachment); err != nil {
+		return err
+	}
+	// Insertar el archivo adjunto
+	return implementationAttachment.InsertAttachment(ctx, attachment)
+}
+
+// GetAttachments es una función que obtiene los archivos adjuntos de un reto.
+func GetAttachments(ctx context.Context, challengeID string) ([]*models.Attachment, error) {
+	// Verificar que implementationAttachment no sea nil
+	if implementationAttachment == nil {
+		return nil, errors.New("implementationAttachment cannot be nil")
+	}
+	// Obtener los archivos adjuntos
+	return implementationAttachment.GetAttachments(ctx, challengeID)
+}
+
+// GetAttachmentById es una función que obtiene un archivo adjunto de un reto.
+func GetAttachmentById(ctx context.Context, challengeID string, id string) (*models.Attachment, error) {
+	// Verificar que implementationAttachment no sea nil
+	if implementationAttachment == nil {
+		return nil, errors.New("implementationAttachment cannot be nil")
+	}
+	// Obtener el archivo adjunto
+	return implementationAttachment.GetAttachmentById(ctx, challengeID, id)
+}
+
+// DeleteAttachment es una función que elimina un archivo adjunto de un reto.
+func DeleteAttachment(ctx context.Context, challengeID string, id string) error {
+	// Verificar que implementationAttachment no sea nil
+	if implementationAttachment == nil {
+		return errors.New("implementationAttachment cannot be nil")
+	}
+	// Eliminar el archivo adjunto
+	return implementationAttachment.DeleteAttachment(ctx, challengeID, id)
+}
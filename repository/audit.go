@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"talentpitchGo/models"
+)
+
+// AuditRepository es una interfaz que define las operaciones de base de datos para el registro de auditoría.
+// El registro es de solo inserción: no hay operaciones para modificar o eliminar entradas.
+type AuditRepository interface {
+	// InsertAuditEntry es una función que agrega una entrada al registro de auditoría.
+	InsertAuditEntry(ctx context.Context, entry *models.AuditEntry) error
+	// GetAuditEntries es una función que obtiene las entradas del registro que cumplen el filtro, con paginación.
+	GetAuditEntries(ctx context.Context, filter models.AuditFilter, page int, pageSize int) ([]*models.AuditEntry, int, error)
+}
+
+// implementationAudit es una variable que contiene la implementación de la interfaz AuditRepository.
+var implementationAudit AuditRepository
+
+// SetAuditRepository es una función que establece la implementación de la interfaz AuditRepository.
+func SetAuditRepository(repo AuditRepository) {
+	// Establecer la implementación de la interfaz AuditRepository
+	implementationAudit = repo
+}
+
+// InsertAuditEntry es una función que agrega una entrada al registro de auditoría.
+func InsertAuditEntry(ctx context.Context, entry *models.AuditEntry) error {
+	// Verificar que implementationAudit no sea nil
+	if implementationAudit == nil {
+		return errors.New("implementationAudit cannot be nil")
+	}
+	// Verificar que la entrada no sea nil
+	if entry == nil {
+		return errors.New("audit entry cannot be nil")
+	}
+	// Verificar que los campos necesarios de la entrada no estén vacíos
+	if entry.Action == "" {
+		return errors.New("audit entry action cannot be empty")
+	}
+	if entry.EntityType == "" || entry.EntityID == "" {
+		return errors.New("audit entry entity cannot be empty")
+	}
+	// Insertar la entrada en la base de datos
+	return implementationAudit.InsertAuditEntry(ctx, entry)
+}
+
+// GetAuditEntries es una función que obtiene las entradas del registro que cumplen el filtro, con paginación.
+func GetAuditEntries(ctx context.Context, filter models.AuditFilter, page int, pageSize int) ([]*models.AuditEntry, int, error) {
+	// Verificar que implementationAudit no sea nil
+	if implementationAudit == nil {
+		return nil, 0, errors.New("implementationAudit cannot be nil")
+	}
+	// Verificar que el rango de fechas sea válido
+	if filter.From != nil && filter.To != nil && filter.To.Before(*filter.From) {
+		return nil, 0, errors.New("audit filter 'to' cannot be before 'from'")
+	}
+	// Consultar las entradas
+	return implementationAudit.GetAuditEntries(ctx, filter, page, pageSize)
+}
@@ -4,7 +4,9 @@ package repository
 import (
 	"errors"
 	"context"
+	"fmt"
 	"talentpitchGo/models"
+	"talentpitchGo/validation"
 )
 
 
@@ -13,12 +15,20 @@ type ChallengeRepository interface {
 	// InsertChallenge es una función que inserta un nuevo reto en la base de datos.
 	InsertChallenge(ctx context.Context, challenge *models.Challenge) error
 	// UpdateChallenge es una función que actualiza un reto en la base de datos.
+	// Si la versión del modelo no es 0, solo actualiza si coincide con la versión actual; si no, devuelve ErrVersionConflict.
 	UpdateChallenge(ctx context.Context, id string, challenge *models.Challenge) error
 	// DeleteChallenge es una función que elimina un reto de la base de datos por su ID.
-	DeleteChallenge(ctx context.Context, id string) error
-	// GetChallenges es una función que obtiene una lista de retos de la base de datos.
-	GetChallenges(ctx context.Context, page int, pageSize int) ([]*models.Challenge, int, error)
-	// GetChallengeById es una función que obtiene un reto de la base de datos por su ID.
+	// Si version no es 0, solo elimina si coincide con la versión actual; si no, devuelve ErrVersionConflict.
+	DeleteChallenge(ctx context.Context, id string, version int) error
+	// PatchChallenge es una función que actualiza solo los campos dados de un reto, con la misma regla de versión que UpdateChallenge.
+	PatchChallenge(ctx context.Context, id string, challenge *models.Challenge, fields []string) error
+	// RestoreChallenge es una función que restaura un reto eliminado.
+	RestoreChallenge(ctx context.Context, id string) error
+	// GetChallenges es una función que obtiene una lista de los retos que cumplen el filtro.
+	GetChallenges(ctx context.Context, filter models.ChallengeFilter, page int, pageSize int) ([]*models.Challenge, int, error)
+	// GetChallengeStats es una función que cuenta los retos que cumplen el filtro por nivel de dificultad.
+	GetChallengeStats(ctx context.Context, filter models.ChallengeFilter) (map[int]int, error)
+	// GetChallengeById es una función que obtiene un reto de la base de datos por su ID; devuelve ErrNotFound si no existe.
 	GetChallengeById(ctx context.Context, id string) (*models.Challenge, error)
 	// Close es una función que cierra la conexión a la base de datos.
 	CloseChallenge() error
@@ -47,18 +57,9 @@ func InsertChallenge(ctx context.Context, challenge *models.Challenge) error {
 	}
 
 
-	// Verificar que los campos necesarios del reto no estén vacíos
-	if challenge.Title == "" {
-		return errors.New("challenge title cannot be empty")
-	}
-	if challenge.Description == "" {
-		return errors.New("challenge description cannot be empty")
-	}
-	if challenge.Difficulty == 0 {
-		return errors.New("challenge difficulty cannot be empty")
-	}
-	if challenge.UserID == "" {
-		return errors.New("challenge user_id cannot be empty")
+	// Verificar que los campos del reto sean válidos
+	if err := ValidateChallenge(challenge); err != nil {
+		return err
 	}
 
 	// Llamar a la función InsertChallenge de la implementación
@@ -78,31 +79,49 @@ func UpdateChallenge(ctx context.Context ,id string, challenge *models.Challenge
 		return errors.New("context cannot be nil")
 	}
 
-	// Verificar que el reto no sea nil
-	if challenge == nil {
-		return errors.New("challenge cannot be nil")
+	// Verificar que los campos del reto sean válidos
+	if err := ValidateChallenge(challenge); err != nil {
+		return err
 	}
 
-	// Verificar que los campos necesarios del reto no estén vacíos
-	if challenge.Title == "" {
-		return errors.New("challenge title cannot be empty")
-	}
-	if challenge.Description == "" {
-		return errors.New("challenge description cannot be empty")
+	// Llamar a la función UpdateChallenge de la implementación
+	return implementationChallenge.UpdateChallenge(ctx, id, challenge)
+}
+
+// PatchChallenge es una función que actualiza solo los campos dados de un reto.
+// La validación se hace sobre el reto completo ya combinado con los cambios.
+func PatchChallenge(ctx context.Context, id string, challenge *models.Challenge, fields []string) error {
+	// Verificar que implementationChallenge no sea nil
+	if implementationChallenge == nil {
+		return errors.New("implementationChallenge cannot be nil")
 	}
-	if challenge.Difficulty == 0 {
-		return errors.New("challenge difficulty cannot be empty")
+
+	// Verificar que haya campos que actualizar
+	if len(fields) == 0 {
+		return errors.New("challenge patch fields cannot be empty")
 	}
-	if challenge.UserID == "" {
-		return errors.New("challenge user_id cannot be empty")
+
+	// Verificar que los campos del reto sean válidos
+	if err := ValidateChallenge(challenge); err != nil {
+		return err
 	}
 
-	// Llamar a la función UpdateChallenge de la implementación
-	return implementationChallenge.UpdateChallenge(ctx, id, challenge)
+	// Llamar a la función PatchChallenge de la implementación
+	return implementationChallenge.PatchChallenge(ctx, id, challenge, fields)
+}
+
+// ValidateChallenge es una función que verifica los campos de un reto con las reglas de su etiqueta validate.
+// Devuelve todos los errores de los campos como validation.Errors.
+func ValidateChallenge(challenge *models.Challenge) error {
+	// Verificar que el reto no sea nil
+	if challenge == nil {
+		return errors.New("challenge cannot be nil")
+	}
+	return validation.Validate(challenge)
 }
 
 // DeleteChallenge es una función que elimina un reto de la base de datos por su ID.
-func DeleteChallenge(ctx context.Context ,id string) error {
+func DeleteChallenge(ctx context.Context ,id string, version int) error {
 	// Verificar que implementationChallenge no sea nil
 	if implementationChallenge == nil {
 		return errors.New("implementationChallenge cannot be nil")
@@ -114,12 +133,26 @@ func DeleteChallenge(ctx context.Context ,id string) error {
 	}
 
 	// Llamar a la función DeleteChallenge de la implementación
-	return implementationChallenge.DeleteChallenge(ctx, id)
+	return implementationChallenge.DeleteChallenge(ctx, id, version)
+}
+
+// RestoreChallenge es una función que restaura un reto eliminado.
+func RestoreChallenge(ctx context.Context, id string) error {
+	// Verificar que implementationChallenge no sea nil
+	if implementationChallenge == nil {
+		return errors.New("implementationChallenge cannot be nil")
+	}
+	// Verificar que el ID del reto no esté vacío
+	if id == "" {
+		return errors.New("challenge id cannot be empty")
+	}
+	// Llamar a la función RestoreChallenge de la implementación
+	return implementationChallenge.RestoreChallenge(ctx, id)
 }
 
 
-// GetChallenges es una función que obtiene una lista de retos de la base de datos.
-func GetChallenges(ctx context.Context, page int, pageSize int) ([]*models.Challenge, int, error) {
+// GetChallenges es una función que obtiene una lista de los retos que cumplen el filtro.
+func GetChallenges(ctx context.Context, filter models.ChallengeFilter, page int, pageSize int) ([]*models.Challenge, int, error) {
 	// Verificar que implementationChallenge no sea nil
 	if implementationChallenge == nil {
 		return nil, 0, errors.New("implementationChallenge cannot be nil")
@@ -130,8 +163,38 @@ func GetChallenges(ctx context.Context, page int, pageSize int) ([]*models.Chall
 		return nil, 0, errors.New("context cannot be nil")
 	}
 
+	// Verificar que los niveles del filtro existan
+	if err := validateDifficulties(filter.Difficulties); err != nil {
+		return nil, 0, err
+	}
+
 	// Llamar a la función GetChallenges de la implementación
-	return implementationChallenge.GetChallenges(ctx, page, pageSize)
+	return implementationChallenge.GetChallenges(ctx, filter, page, pageSize)
+}
+
+// GetChallengeStats es una función que cuenta los retos que cumplen el filtro por nivel de dificultad.
+// Los niveles sin retos no aparecen en el resultado.
+func GetChallengeStats(ctx context.Context, filter models.ChallengeFilter) (map[int]int, error) {
+	// Verificar que implementationChallenge no sea nil
+	if implementationChallenge == nil {
+		return nil, errors.New("implementationChallenge cannot be nil")
+	}
+	// Verificar que los niveles del filtro existan
+	if err := validateDifficulties(filter.Difficulties); err != nil {
+		return nil, err
+	}
+	// Llamar a la función GetChallengeStats de la implementación
+	return implementationChallenge.GetChallengeStats(ctx, filter)
+}
+
+// validateDifficulties es una función que verifica que los niveles de dificultad de un filtro existan.
+func validateDifficulties(levels []int) error {
+	for _, level := range levels {
+		if !models.ValidDifficulty(level) {
+			return fmt.Errorf("invalid challenge difficulty %d", level)
+		}
+	}
+	return nil
 }
 
 
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"talentpitchGo/models"
+	"talentpitchGo/validation"
 )
 
 // CompanyRepository es una interfaz que define las operaciones de base de datos para las empresas.
@@ -11,12 +12,20 @@ type CompanyRepository interface {
 	// InsertCompany es una función que inserta una nueva empresa en la base de datos.
 	InsertCompany(ctx context.Context, company *models.Company) error
 	// UpdateCompany es una función que actualiza una empresa en la base de datos.
+	// Si la versión del modelo no es 0, solo actualiza si coincide con la versión actual; si no, devuelve ErrVersionConflict.
 	UpdateCompany(ctx context.Context, id string, company *models.Company) error
 	// DeleteCompany es una función que elimina una empresa de la base de datos por su ID.
-	DeleteCompany(ctx context.Context, id string) error
+	// Si version no es 0, solo elimina si coincide con la versión actual; si no, devuelve ErrVersionConflict.
+	DeleteCompany(ctx context.Context, id string, version int) error
+	// PatchCompany es una función que actualiza solo los campos dados de una empresa, con la misma regla de versión que UpdateCompany.
+	PatchCompany(ctx context.Context, id string, company *models.Company, fields []string) error
+	// RestoreCompany es una función que restaura una empresa eliminada.
+	RestoreCompany(ctx context.Context, id string) error
 	// GetCompanies es una función que obtiene una lista de empresas de la base de datos.
 	GetCompanies(ctx context.Context, page int, pageSize int) ([]*models.Company, int, error)
-	// GetCompanyById es una función que obtiene una empresa de la base de datos por su ID.
+	// GetCompaniesByUser es una función que obtiene una lista de las empresas de un usuario.
+	GetCompaniesByUser(ctx context.Context, userID string, page int, pageSize int) ([]*models.Company, int, error)
+	// GetCompanyById es una función que obtiene una empresa de la base de datos por su ID; devuelve ErrNotFound si no existe.
 	GetCompanyById(ctx context.Context, id string) (*models.Company, error)
 	// CloseCompany es una función que cierra la conexión a la base de datos.
 	CloseCompany() error
@@ -41,18 +50,9 @@ func InsertCompany(ctx context.Context, company *models.Company) error {
 	if ctx == nil {
 		return errors.New("context cannot be nil")
 	}
-	// Verificar que los campos necesarios de la empresa no estén vacíos
-	if company.Name == "" {
-		return errors.New("company name cannot be empty")
-	}
-	if company.Location == "" {
-		return errors.New("company location cannot be empty")
-	}
-	if company.Industry == "" {
-		return errors.New("company industry cannot be empty")
-	}
-	if company.UserID == "" {
-		return errors.New("company user_id cannot be empty")
+	// Verificar que los campos de la empresa sean válidos
+	if err := ValidateCompany(company); err != nil {
+		return err
 	}
 	// Insertar la empresa en la base de datos
 	return implementationCompany.InsertCompany(ctx, company)
@@ -68,25 +68,45 @@ func UpdateCompany(ctx context.Context, id string, company *models.Company) erro
 	if ctx == nil {
 		return errors.New("context cannot be nil")
 	}
-	// Verificar que los campos necesarios de la empresa no estén vacíos
-	if company.Name == "" {
-		return errors.New("company name cannot be empty")
+	// Verificar que los campos de la empresa sean válidos
+	if err := ValidateCompany(company); err != nil {
+		return err
 	}
-	if company.Location == "" {
-		return errors.New("company location cannot be empty")
+	// Actualizar la empresa en la base de datos
+	return implementationCompany.UpdateCompany(ctx, id, company)
+}
+
+// PatchCompany es una función que actualiza solo los campos dados de una empresa.
+// La validación se hace sobre la empresa completa ya combinada con los cambios.
+func PatchCompany(ctx context.Context, id string, company *models.Company, fields []string) error {
+	// Verificar que implementationCompany no sea nil
+	if implementationCompany == nil {
+		return errors.New("implementationCompany cannot be nil")
 	}
-	if company.Industry == "" {
-		return errors.New("company industry cannot be empty")
+	// Verificar que haya campos que actualizar
+	if len(fields) == 0 {
+		return errors.New("company patch fields cannot be empty")
 	}
-	if company.UserID == "" {
-		return errors.New("company user_id cannot be empty")
+	// Verificar que los campos de la empresa sean válidos
+	if err := ValidateCompany(company); err != nil {
+		return err
 	}
-	// Actualizar la empresa en la base de datos
-	return implementationCompany.UpdateCompany(ctx, id, company)
+	// Actualizar los campos de la empresa en la base de datos
+	return implementationCompany.PatchCompany(ctx, id, company, fields)
+}
+
+// ValidateCompany es una función que verifica los campos de una empresa con las reglas de su etiqueta validate.
+// Devuelve todos los errores de los campos como validation.Errors.
+func ValidateCompany(company *models.Company) error {
+	// Verificar que la empresa no sea nil
+	if company == nil {
+		return errors.New("company cannot be nil")
+	}
+	return validation.Validate(company)
 }
 
 // DeleteCompany es una función que elimina una empresa de la base de datos por su ID.
-func DeleteCompany(ctx context.Context, id string) error {
+func DeleteCompany(ctx context.Context, id string, version int) error {
 	// Verificar que implementationCompany no sea nil
 	if implementationCompany == nil {
 		return errors.New("implementationCompany cannot be nil")
@@ -96,7 +116,21 @@ func DeleteCompany(ctx context.Context, id string) error {
 		return errors.New("context cannot be nil")
 	}
 	// Eliminar la empresa de la base de datos
-	return implementationCompany.DeleteCompany(ctx, id)
+	return implementationCompany.DeleteCompany(ctx, id, version)
+}
+
+// RestoreCompany es una función que restaura una empresa eliminada.
+func RestoreCompany(ctx context.Context, id string) error {
+	// Verificar que implementationCompany no sea nil
+	if implementationCompany == nil {
+		return errors.New("implementationCompany cannot be nil")
+	}
+	// Verificar que el ID de la empresa no esté vacío
+	if id == "" {
+		return errors.New("company id cannot be empty")
+	}
+	// Restaurar la empresa
+	return implementationCompany.RestoreCompany(ctx, id)
 }
 
 
@@ -114,6 +148,20 @@ func GetCompanies(ctx context.Context, page int, pageSize int) ([]*models.Compan
 	return implementationCompany.GetCompanies(ctx, page, pageSize)
 }
 
+// GetCompaniesByUser es una función que obtiene una lista de las empresas de un usuario.
+func GetCompaniesByUser(ctx context.Context, userID string, page int, pageSize int) ([]*models.Company, int, error) {
+	// Verificar que implementationCompany no sea nil
+	if implementationCompany == nil {
+		return nil, 0, errors.New("implementationCompany cannot be nil")
+	}
+	// Verificar que el ID del usuario no esté vacío
+	if userID == "" {
+		return nil, 0, errors.New("company user_id cannot be empty")
+	}
+	// Obtener las empresas del usuario
+	return implementationCompany.GetCompaniesByUser(ctx, userID, page, pageSize)
+}
+
 // GetCompanyById es una función que obtiene una empresa de la base de datos por su ID.
 func GetCompanyById(ctx context.Context, id string) (*models.Company, error) {
 	// Verificar que implementationCompany no sea nil
@@ -0,0 +1,12 @@
+package repository
+
+import "errors"
+
+// ErrNotFound es el error que se devuelve cuando el registro no existe o fue eliminado.
+var ErrNotFound = errors.New("not found")
+
+// ErrCodeUsed es el error que se devuelve cuando un código de un solo uso (de recuperación o TOTP) ya se había usado.
+var ErrCodeUsed = errors.New("code already used")
+
+// ErrVersionConflict es el error que se devuelve cuando el registro cambió desde la versión que se esperaba modificar.
+var ErrVersionConflict = errors.New("version conflict")
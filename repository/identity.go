@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"talentpitchGo/models"
+)
+
+// IdentityRepository es una interfaz que define las operaciones de base de datos para las identidades externas.
+type IdentityRepository interface {
+	// GetIdentity es una función que obtiene una identidad por proveedor y sujeto, o nil si no existe.
+	GetIdentity(ctx context.Context, provider string, subject string) (*models.Identity, error)
+	// InsertIdentity es una función que vincula una identidad externa con un usuario existente.
+	InsertIdentity(ctx context.Context, identity *models.Identity) error
+	// InsertUserWithIdentity es una función que crea un usuario y su identidad externa de forma atómica.
+	InsertUserWithIdentity(ctx context.Context, user *models.User, identity *models.Identity) error
+}
+
+// implementationIdentity es una variable que contiene la implementación de la interfaz IdentityRepository.
+var implementationIdentity IdentityRepository
+
+// SetIdentityRepository es una función que establece la implementación de la interfaz IdentityRepository.
+func SetIdentityRepository(repo IdentityRepository) {
+	// Establecer la implementación de la interfaz IdentityRepository
+	implementationIdentity = repo
+}
+
+// GetIdentity es una función que obtiene una identidad por proveedor y sujeto, o nil si no existe.
+func GetIdentity(ctx context.Context, provider string, subject string) (*models.Identity, error) {
+	// Verificar que implementationIdentity no sea nil
+	if implementationIdentity == nil {
+		return nil, errors.New("implementationIdentity cannot be nil")
+	}
+	// Verificar que los campos necesarios no estén vacíos
+	if provider == "" || subject == "" {
+		return nil, errors.New("identity provider and subject cannot be empty")
+	}
+	// Obtener la identidad
+	return implementationIdentity.GetIdentity(ctx, provider, subject)
+}
+
+// InsertIdentity es una función que vincula una identidad externa con un usuario existente.
+func InsertIdentity(ctx context.Context, identity *models.Identity) error {
+	// Verificar que implementationIdentity no sea nil
+	if implementationIdentity == nil {
+		return errors.New("implementationIdentity cannot be nil")
+	}
+	// Verificar que la identidad sea válida
+	if err := validateIdentity(identity); err != nil {
+		return err
+	}
+	// Insertar la identidad
+	return implementationIdentity.InsertIdentity(ctx, identity)
+}
+
+// InsertUserWithIdentity es una función que crea un usuario y su identidad externa de forma atómica.
+func InsertUserWithIdentity(ctx context.Context, user *models.User, identity *models.Identity) error {
+	// Verificar que implementationIdentity no sea nil
+	if implementationIdentity == nil {
+		return errors.New("implementationIdentity cannot be nil")
+	}
+	// Verificar que el usuario no sea nil
+	if user == nil {
+		return errors.New("user cannot be nil")
+	}
+	// Verificar que los campos del usuario sean válidos
+	if err := ValidateUser(user); err != nil {
+		return err
+	}
+	if user.Password == "" {
+		return errors.New("user password cannot be empty")
+	}
+	// Verificar que la identidad sea válida
+	if err := validateIdentity(identity); err != nil {
+		return err
+	}
+	// Insertar el usuario y la identidad
+	return implementationIdentity.InsertUserWithIdentity(ctx, user, identity)
+}
+
+// validateIdentity es una función que verifica los campos necesarios de una identidad.
+func validateIdentity(identity *models.Identity) error {
+	if identity == nil {
+		return errors.New("identity cannot be nil")
+	}
+	if identity.UserID == "" {
+		return errors.New("identity user_id cannot be empty")
+	}
+	if identity.Provider == "" {
+		return errors.New("identity provider cannot be empty")
+	}
+	if identity.Subject == "" {
+		return errors.New("identity subject cannot be empty")
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"talentpitchGo/models"
+)
+
+// MFARepository es una interfaz que define las operaciones de base de datos para la autenticación de dos factores.
+type MFARepository interface {
+	// GetUserMFA es una función que obtiene la configuración TOTP de un usuario, o nil si no tiene.
+	GetUserMFA(ctx context.Context, userId string) (*models.UserMFA, error)
+	// SaveUserMFASecret es una función que guarda un secreto TOTP pendiente de verificación para un usuario.
+	SaveUserMFASecret(ctx context.Context, userId string, secret string) error
+	// EnableUserMFA es una función que activa el TOTP de un usuario y reemplaza sus códigos de recuperación.
+	EnableUserMFA(ctx context.Context, userId string, codes []*models.RecoveryCode) error
+	// DisableUserMFA es una función que desactiva el TOTP de un usuario y elimina sus códigos de recuperación.
+	DisableUserMFA(ctx context.Context, userId string) error
+	// GetRecoveryCodes es una función que obtiene los códigos de recuperación sin usar de un usuario.
+	GetRecoveryCodes(ctx context.Context, userId string) ([]*models.RecoveryCode, error)
+	// UseRecoveryCode es una función que marca un código de recuperación como usado, o devuelve ErrCodeUsed si ya lo estaba.
+	UseRecoveryCode(ctx context.Context, id string) error
+	// UseTOTPStep es una función que guarda la ventana del último código TOTP aceptado de un usuario, o devuelve
+	// ErrCodeUsed si ya se había aceptado un código de esa ventana o de una posterior.
+	UseTOTPStep(ctx context.Context, userId string, step int64) error
+}
+
+// implementationMFA es una variable que contiene la implementación de la interfaz MFARepository.
+var implementationMFA MFARepository
+
+// SetMFARepository es una función que establece la implementación de la interfaz MFARepository.
+func SetMFARepository(repo MFARepository) {
+	// Establecer la implementación de la interfaz MFARepository
+	implementationMFA = repo
+}
+
+// GetUserMFA es una función que obtiene la configuración TOTP de un usuario, o nil si no tiene.
+func GetUserMFA(ctx context.Context, userId string) (*models.UserMFA, error) {
+	// Verificar que implementationMFA no sea nil
+	if implementationMFA == nil {
+		return nil, errors.New("implementationMFA cannot be nil")
+	}
+	// Verificar que el ID del usuario no esté vacío
+	if userId == "" {
+		return nil, errors.New("user id cannot be empty")
+	}
+	// Obtener la configuración TOTP del usuario
+	return implementationMFA.GetUserMFA(ctx, userId)
+}
+
+// SaveUserMFASecret es una función que guarda un secreto TOTP pendiente de verificación para un usuario.
+func SaveUserMFASecret(ctx context.Context, userId string, secret string) error {
+	// Verificar que implementationMFA no sea nil
+	if implementationMFA == nil {
+		return errors.New("implementationMFA cannot be nil")
+	}
+	// Verificar que los campos necesarios no estén vacíos
+	if userId == "" {
+		return errors.New("user id cannot be empty")
+	}
+	if secret == "" {
+		return errors.New("mfa secret cannot be empty")
+	}
+	// Guardar el secreto TOTP
+	return implementationMFA.SaveUserMFASecret(ctx, userId, secret)
+}
+
+// EnableUserMFA es una función que activa el TOTP de un usuario y reemplaza sus códigos de recuperación.
+func EnableUserMFA(ctx context.Context, userId string, codes []*models.RecoveryCode) error {
+	// Verificar que implementationMFA no sea nil
+	if implementationMFA == nil {
+		return errors.New("implementationMFA cannot be nil")
+	}
+	// Verificar que el ID del usuario no esté vacío
+	if userId == "" {
+		return errors.New("user id cannot be empty")
+	}
+	// Verificar que se hayan generado códigos de recuperación
+	if len(codes) == 0 {
+		return errors.New("recovery codes cannot be empty")
+	}
+	// Activar el TOTP del usuario
+	return implementationMFA.EnableUserMFA(ctx, userId, codes)
+}
+
+// DisableUserMFA es una función que desactiva el TOTP de un usuario y elimina sus códigos de recuperación.
+func DisableUserMFA(ctx context.Context, userId string) error {
+	// Verificar que implementationMFA no sea nil
+	if implementationMFA == nil {
+		return errors.New("implementationMFA cannot be nil")
+	}
+	// Verificar que el ID del usuario no esté vacío
+	if userId == "" {
+		return errors.New("user id cannot be empty")
+	}
+	// Desactivar el TOTP del usuario
+	return implementationMFA.DisableUserMFA(ctx, userId)
+}
+
+// GetRecoveryCodes es una función que obtiene los códigos de recuperación sin usar de un usuario.
+func GetRecoveryCodes(ctx context.Context, userId string) ([]*models.RecoveryCode, error) {
+	// Verificar que implementationMFA no sea nil
+	if implementationMFA == nil {
+		return nil, errors.New("implementationMFA cannot be nil")
+	}
+	// Obtener los códigos de recuperación del usuario
+	return implementationMFA.GetRecoveryCodes(ctx, userId)
+}
+
+// UseRecoveryCode es una función que marca un código de recuperación como usado, o devuelve ErrCodeUsed si ya lo estaba.
+func UseRecoveryCode(ctx context.Context, id string) error {
+	// Verificar que implementationMFA no sea nil
+	if implementationMFA == nil {
+		return errors.New("implementationMFA cannot be nil")
+	}
+	// Marcar el código de recuperación como usado
+	return implementationMFA.UseRecoveryCode(ctx, id)
+}
+
+// UseTOTPStep es una función que guarda la ventana del último código TOTP aceptado de un usuario, o devuelve
+// ErrCodeUsed si ya se había aceptado un código de esa ventana o de una posterior.
+func UseTOTPStep(ctx context.Context, userId string, step int64) error {
+	// Verificar que implementationMFA no sea nil
+	if implementationMFA == nil {
+		return errors.New("implementationMFA cannot be nil")
+	}
+	// Guardar la ventana del código
+	return implementationMFA.UseTOTPStep(ctx, userId, step)
+}
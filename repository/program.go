@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"talentpitchGo/models"
+	"talentpitchGo/validation"
+)
+
+// ProgramRepository es una interfaz que define las operaciones de base de datos para los programas.
+type ProgramRepository interface {
+	// GetProgramById es una función que obtiene un programa de la base de datos por su ID.
+	GetProgramById(ctx context.Context, id string) (*models.Program, error)
+	// PatchProgram es una función que actualiza solo los campos dados de un programa.
+	// Si la versión del modelo no es 0, solo actualiza si coincide con la versión actual; si no, devuelve ErrVersionConflict.
+	PatchProgram(ctx context.Context, id string, program *models.Program, fields []string) error
+	// InsertProgramParticipant es una función que inscribe un usuario, un reto o una empresa en un programa.
+	InsertProgramParticipant(ctx context.Context, participant *models.ProgramParticipant) error
+}
+
+// implementationProgram es una variable que contiene la implementación de la interfaz ProgramRepository.
+var implementationProgram ProgramRepository
+
+// SetProgramRepository es una función que establece la implementación de la interfaz ProgramRepository.
+func SetProgramRepository(repo ProgramRepository) {
+	// Establecer la implementación de la interfaz ProgramRepository
+	implementationProgram = repo
+}
+
+// GetProgramById es una función que obtiene un programa de la base de datos por su ID.
+func GetProgramById(ctx context.Context, id string) (*models.Program, error) {
+	// Verificar que implementationProgram no sea nil
+	if implementationProgram == nil {
+		return nil, errors.New("implementationProgram cannot be nil")
+	}
+	// Obtener el programa
+	return implementationProgram.GetProgramById(ctx, id)
+}
+
+// PatchProgram es una función que actualiza solo los campos dados de un programa.
+// La validación se hace sobre el programa completo ya combinado con los cambios.
+func PatchProgram(ctx context.Context, id string, program *models.Program, fields []string) error {
+	// Verificar que implementationProgram no sea nil
+	if implementationProgram == nil {
+		return errors.New("implementationProgram cannot be nil")
+	}
+	// Verificar que haya campos que actualizar
+	if len(fields) == 0 {
+		return errors.New("program patch fields cannot be empty")
+	}
+	// Verificar que los campos del programa sean válidos
+	if err := ValidateProgram(program); err != nil {
+		return err
+	}
+	// Actualizar los campos del programa en la base de datos
+	return implementationProgram.PatchProgram(ctx, id, program, fields)
+}
+
+// InsertProgramParticipant es una función que inscribe un usuario, un reto o una empresa en un programa.
+func InsertProgramParticipant(ctx context.Context, participant *models.ProgramParticipant) error {
+	// Verificar que implementationProgram no sea nil
+	if implementationProgram == nil {
+		return errors.New("implementationProgram cannot be nil")
+	}
+	// Verificar que la inscripción no sea nil
+	if participant == nil {
+		return errors.New("program participant cannot be nil")
+	}
+	// Inscribir la entidad en el programa
+	return implementationProgram.InsertProgramParticipant(ctx, participant)
+}
+
+// ValidateProgram es una función que verifica los campos de un programa con las reglas de su etiqueta validate
+// y que sus fechas sean coherentes. Devuelve todos los errores de los campos como validation.Errors.
+func ValidateProgram(program *models.Program) error {
+	// Verificar que el programa no sea nil
+	if program == nil {
+		return errors.New("program cannot be nil")
+	}
+	errs := validation.Struct(program)
+	if !program.StartDate.IsZero() && !program.EndDate.IsZero() && program.EndDate.Before(program.StartDate) {
+		errs = append(errs, validation.FieldError{Field: "end_date", Code: "after_start_date", Message: "cannot be before start_date"})
+	}
+	return errs.Err()
+}
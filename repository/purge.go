@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// PurgeRepository es una interfaz que define la eliminación definitiva de los registros eliminados lógicamente.
+type PurgeRepository interface {
+	// PurgeDeleted es una función que elimina definitivamente los registros eliminados antes de la fecha dada.
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
+}
+
+// implementationPurge es una variable que contiene la implementación de la interfaz PurgeRepository.
+var implementationPurge PurgeRepository
+
+// SetPurgeRepository es una función que establece la implementación de la interfaz PurgeRepository.
+func SetPurgeRepository(repo PurgeRepository) {
+	// Establecer la implementación de la interfaz PurgeRepository
+	implementationPurge = repo
+}
+
+// PurgeDeleted es una función que elimina definitivamente los registros eliminados antes de la fecha dada.
+func PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	// Verificar que implementationPurge no sea nil
+	if implementationPurge == nil {
+		return 0, errors.New("implementationPurge cannot be nil")
+	}
+	// Purgar los registros
+	return implementationPurge.PurgeDeleted(ctx, before)
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// TX_MAX_RETRIES es el número de reintentos por defecto de una unidad de trabajo que falla por serialización
+const TX_MAX_RETRIES = 3
+
+// TxOptions es la estructura de las opciones de una unidad de trabajo
+type TxOptions struct {
+	Isolation  sql.IsolationLevel // Nivel de aislamiento; sql.LevelDefault usa el de la base de datos
+	ReadOnly   bool               // Transacción de solo lectura
+	MaxRetries int                // Reintentos ante fallos de serialización; 0 usa TX_MAX_RETRIES y un valor negativo no reintenta
+}
+
+// Repositories es una interfaz que agrupa todos los repositorios. Dentro de una unidad de trabajo
+// todas sus operaciones se ejecutan en la misma transacción.
+type Repositories interface {
+	UserRepository
+	ChallengeRepository
+	CompanyRepository
+	MFARepository
+	IdentityRepository
+	APIKeyRepository
+	PurgeRepository
+	AuditRepository
+	ProgramRepository
+	AttachmentRepository
+}
+
+// UnitOfWork es una interfaz que define la ejecución de varias operaciones de los repositorios en una transacción.
+type UnitOfWork interface {
+	// WithTx es una función que ejecuta fn en una transacción: la confirma si fn no devuelve error y si no la deshace.
+	// Si la transacción falla por serialización, se vuelve a ejecutar fn desde el principio.
+	WithTx(ctx context.Context, options TxOptions, fn func(repos Repositories) error) error
+}
+
+// implementationUnitOfWork es una variable que contiene la implementación de la interfaz UnitOfWork.
+var implementationUnitOfWork UnitOfWork
+
+// SetUnitOfWork es una función que establece la implementación de la interfaz UnitOfWork.
+func SetUnitOfWork(uow UnitOfWork) {
+	// Establecer la implementación de la interfaz UnitOfWork
+	implementationUnitOfWork = uow
+}
+
+// WithTx es una función que ejecuta fn en una transacción con las opciones por defecto.
+// fn puede ejecutarse más de una vez, así que no debe tener efectos fuera de la base de datos.
+func WithTx(ctx context.Context, fn func(repos Repositories) error) error {
+	return WithTxOptions(ctx, TxOptions{}, fn)
+}
+
+// WithTxOptions es una función que ejecuta fn en una transacción con el nivel de aislamiento y los reintentos dados.
+// fn puede ejecutarse más de una vez, así que no debe tener efectos fuera de la base de datos.
+func WithTxOptions(ctx context.Context, options TxOptions, fn func(repos Repositories) error) error {
+	// Verificar que implementationUnitOfWork no sea nil
+	if implementationUnitOfWork == nil {
+		return errors.New("implementationUnitOfWork cannot be nil")
+	}
+	// Verificar que la función no sea nil
+	if fn == nil {
+		return errors.New("unit of work function cannot be nil")
+	}
+	// Ejecutar la unidad de trabajo
+	return implementationUnitOfWork.WithTx(ctx, options, fn)
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"context"
 	"talentpitchGo/models"
+	"talentpitchGo/validation"
 )
 
 // UserRepository es una interfaz que define las operaciones de base de datos para los usuarios.
@@ -12,14 +13,20 @@ type UserRepository interface {
 	// InsertUser es una función que inserta un nuevo usuario en la base de datos.
 	InsertUser(ctx context.Context, user *models.User) error
 	// UpdateUser es una función que actualiza un usuario en la base de datos.
+	// Si la versión del modelo no es 0, solo actualiza si coincide con la versión actual; si no, devuelve ErrVersionConflict.
 	UpdateUser(ctx context.Context, id string, user *models.User) error
-	// DeleteUser es una función que elimina un usuario de la base de datos por su ID.
-	DeleteUser(ctx context.Context, id string) error
+	// DeleteUser es una función que elimina lógicamente un usuario y sus recursos por su ID.
+	// Si version no es 0, solo elimina si coincide con la versión actual; si no, devuelve ErrVersionConflict.
+	DeleteUser(ctx context.Context, id string, version int) error
+	// PatchUser es una función que actualiza solo los campos dados de un usuario, con la misma regla de versión que UpdateUser.
+	PatchUser(ctx context.Context, id string, user *models.User, fields []string) error
+	// RestoreUser es una función que restaura un usuario eliminado y los recursos eliminados con él.
+	RestoreUser(ctx context.Context, id string) error
 	// GetUsers es una función que obtiene una lista de usuarios de la base de datos.
 	GetUsers(ctx context.Context, page int, pageSize int) ([]*models.User, int, error)
-	// GetUserById es una función que obtiene un usuario de la base de datos por su ID.
+	// GetUserById es una función que obtiene un usuario de la base de datos por su ID; devuelve ErrNotFound si no existe.
 	GetUserById(ctx context.Context, id string) (*models.User, error)
-	// GetUserByEmail es una función que obtiene un usuario de la base de datos por su correo electrónico.
+	// GetUserByEmail es una función que obtiene un usuario de la base de datos por su correo electrónico, o nil si no existe.
 	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
 	// Close es una función que cierra la conexión a la base de datos.
 	Close() error
@@ -53,13 +60,11 @@ func InsertUser(ctx context.Context, user *models.User) error {
         return errors.New("user cannot be nil")
     }
 
-    // Verificar que los campos necesarios del usuario no estén vacíos
-    if user.Email == "" {
-        return errors.New("user email cannot be empty")
-    }
-    if user.Fullname == "" {
-        return errors.New("user fullname cannot be empty")
+    // Verificar que los campos del usuario sean válidos
+    if err := ValidateUser(user); err != nil {
+        return err
     }
+    // La contraseña ya llega hasheada, solo se verifica que exista
     if user.Password == "" {
         return errors.New("user password cannot be empty")
     }
@@ -79,24 +84,45 @@ func UpdateUser(ctx context.Context ,id string, user *models.User) error {
 		return  errors.New("context cannot be nil")
 	}
 
-	// Verificar que el usuario no sea nil
-	if user == nil {
-		return  errors.New("user cannot be nil")
+	// Verificar que los campos del usuario sean válidos
+	if err := ValidateUser(user); err != nil {
+		return err
 	}
+	// Insertar un nuevo usuario en la base de datos
+	return implementation.UpdateUser(ctx, id, user)
+}
 
-	// Verificar que los campos necesarios del usuario no estén vacíos
-	if user.Email == "" {
-		return  errors.New("user email cannot be empty")
+// PatchUser es una función que actualiza solo los campos dados de un usuario.
+// La validación se hace sobre el usuario completo ya combinado con los cambios.
+func PatchUser(ctx context.Context, id string, user *models.User, fields []string) error {
+	// Verificar que implementation no sea nil
+	if implementation == nil {
+		return errors.New("implementation cannot be nil")
 	}
-	if user.Fullname == "" {
-		return errors.New("user fullname cannot be empty")
+	// Verificar que haya campos que actualizar
+	if len(fields) == 0 {
+		return errors.New("user patch fields cannot be empty")
 	}
-	// Insertar un nuevo usuario en la base de datos
-	return implementation.UpdateUser(ctx, id, user)
+	// Verificar que los campos del usuario sean válidos
+	if err := ValidateUser(user); err != nil {
+		return err
+	}
+	// Actualizar los campos del usuario en la base de datos
+	return implementation.PatchUser(ctx, id, user, fields)
+}
+
+// ValidateUser es una función que verifica los campos editables de un usuario con las reglas de su etiqueta validate.
+// Devuelve todos los errores de los campos como validation.Errors.
+func ValidateUser(user *models.User) error {
+	// Verificar que el usuario no sea nil
+	if user == nil {
+		return errors.New("user cannot be nil")
+	}
+	return validation.Validate(user)
 }
 
 // DeleteUser es una función que elimina un usuario de la base de datos por su ID.
-func DeleteUser(ctx context.Context, id string) error {
+func DeleteUser(ctx context.Context, id string, version int) error {
 	// Verificar que implementation no sea nil
 	if implementation == nil {
 		return errors.New("implementation cannot be nil")
@@ -107,7 +133,21 @@ func DeleteUser(ctx context.Context, id string) error {
 		return errors.New("context cannot be nil")
 	}
 	// Eliminar un usuario de la base de datos por su ID
-	return implementation.DeleteUser(ctx, id)
+	return implementation.DeleteUser(ctx, id, version)
+}
+
+// RestoreUser es una función que restaura un usuario eliminado y los recursos eliminados con él.
+func RestoreUser(ctx context.Context, id string) error {
+	// Verificar que implementation no sea nil
+	if implementation == nil {
+		return errors.New("implementation cannot be nil")
+	}
+	// Verificar que el ID del usuario no esté vacío
+	if id == "" {
+		return errors.New("user id cannot be empty")
+	}
+	// Restaurar el usuario
+	return implementation.RestoreUser(ctx, id)
 }
 
 
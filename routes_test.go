@@ -0,0 +1,133 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"talentpitchGo/docs"
+	"talentpitchGo/server"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+// routesServer es un servidor de prueba para registrar las rutas
+type routesServer struct{}
+
+func (s *routesServer) Config() *server.Config {
+	return &server.Config{JWTSecret: "secret"}
+}
+
+// TestRoutesDocumented falla si una ruta registrada en BindRoutes no está en la especificación OpenAPI
+func TestRoutesDocumented(t *testing.T) {
+	router := mux.NewRouter()
+	BindRoutes(&routesServer{}, router)
+	paths := docs.Spec(docs.OPERATIONS)["paths"].(map[string]map[string]interface{})
+
+	registered := 0
+	err := router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		// Las rutas sin métodos, como el prefijo de la API, no son operaciones
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+		for _, method := range methods {
+			registered++
+			_, ok := paths[path][strings.ToLower(method)]
+			assert.True(t, ok, "%s %s is not documented in the OpenAPI spec", method, path)
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.NotZero(t, registered)
+
+	// La especificación tampoco documenta rutas que no existen
+	documented := 0
+	for _, operations := range paths {
+		documented += len(operations)
+	}
+	assert.Equal(t, registered, documented)
+}
+
+func TestDocsRoutes(t *testing.T) {
+	router := mux.NewRouter()
+	BindRoutes(&routesServer{}, router)
+
+	// La especificación y la interfaz son públicas
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"openapi":"3.0.3"`)
+	assert.Contains(t, rr.Body.String(), `"SingUpRequest"`)
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/docs", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "/openapi.json")
+
+	// Las métricas solo se sirven en el puerto interno
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+// TestLegacyRoutes comprueba que solo las rutas anteriores a la versión tienen alias sin prefijo
+func TestLegacyRoutes(t *testing.T) {
+	router := mux.NewRouter()
+	BindRoutes(&routesServer{}, router)
+	status := func(method string, path string) int {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(method, path, nil))
+		return rr.Code
+	}
+
+	// Los alias existen y, sin token, piden autenticación
+	assert.Equal(t, http.StatusUnauthorized, status(http.MethodGet, "/challenges/c1"))
+	assert.Equal(t, http.StatusUnauthorized, status(http.MethodPut, "/updateCompany/c1"))
+	// Las rutas nuevas no tienen alias, y las rutas fijas de los retos no se toman como un ID
+	assert.Equal(t, http.StatusNotFound, status(http.MethodGet, "/challenges/stats"))
+	assert.Equal(t, http.StatusNotFound, status(http.MethodGet, "/challenges/difficulties"))
+	assert.Equal(t, http.StatusNotFound, status(http.MethodGet, "/programs/p1"))
+	assert.Equal(t, http.StatusNotFound, status(http.MethodPost, "/mfa/totp/enroll"))
+}
+
+// corsServer es un servidor de prueba con un origen permitido de CORS
+type corsServer struct{}
+
+func (s *corsServer) Config() *server.Config {
+	return &server.Config{JWTSecret: "secret", CORSAllowedOrigins: []string{"https://app.example.com"}, CORSMaxAge: time.Minute}
+}
+
+func TestCORSPreflightAndSecurityHeaders(t *testing.T) {
+	router := mux.NewRouter()
+	BindRoutes(&corsServer{}, router)
+
+	// La verificación previa de una ruta registrada se responde sin autenticación
+	req := httptest.NewRequest(http.MethodOptions, "/api/v1/challenges/c1", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodPatch)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+	assert.Equal(t, "https://app.example.com", rr.Header().Get("Access-Control-Allow-Origin"))
+	assert.Contains(t, rr.Header().Get("Access-Control-Allow-Methods"), http.MethodPatch)
+	assert.Equal(t, "60", rr.Header().Get("Access-Control-Max-Age"))
+
+	// Un origen no permitido no recibe las cabeceras CORS
+	req = httptest.NewRequest(http.MethodGet, "/openapi.json", nil)
+	req.Header.Set("Origin", "https://evil.example.com")
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Empty(t, rr.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "nosniff", rr.Header().Get("X-Content-Type-Options"))
+	assert.Equal(t, "DENY", rr.Header().Get("X-Frame-Options"))
+	assert.NotEmpty(t, rr.Header().Get("Strict-Transport-Security"))
+}
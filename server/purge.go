@@ -0,0 +1,59 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"talentpitchGo/logging"
+	"talentpitchGo/ratelimit"
+	"talentpitchGo/repository"
+)
+
+// Intervalos de los procesos periódicos
+const (
+	PURGE_INTERVAL            = time.Hour        // Purga de registros eliminados
+	RATE_LIMIT_SWEEP_INTERVAL = 10 * time.Minute // Limpieza de las cubetas de límites de solicitudes llenas
+)
+
+// startPurgeJob es una función que elimina definitivamente, cada interval, los registros
+// que llevan eliminados lógicamente más de retention. Termina cuando se cancela el contexto.
+func startPurgeJob(ctx context.Context, interval time.Duration, retention time.Duration) {
+	// Crear el temporizador de la purga
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		// Purgar los registros vencidos
+		purged, err := repository.PurgeDeleted(ctx, time.Now().Add(-retention))
+		if err != nil {
+			logging.FromContext(ctx).Error("error purging deleted records", "error", err)
+		} else if purged > 0 {
+			logging.FromContext(ctx).Info("purged deleted records", "count", purged)
+		}
+		// Esperar a la siguiente ejecución
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// startRateLimitSweepJob es una función que elimina, cada interval, las cubetas de límites de solicitudes que ya
+// se llenaron de nuevo, para que la tabla no crezca con cada cliente. Termina cuando se cancela el contexto.
+func startRateLimitSweepJob(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		deleted, err := ratelimit.DeleteFullBuckets(ctx)
+		if err != nil {
+			logging.FromContext(ctx).Error("error deleting full rate limit buckets", "error", err)
+		} else if deleted > 0 {
+			logging.FromContext(ctx).Debug("deleted full rate limit buckets", "count", deleted)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
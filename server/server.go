@@ -4,18 +4,56 @@ package server
 import (
 	"context"
 	"errors"
-	"log"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"net/netip"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 	"talentpitchGo/database" 
+	"talentpitchGo/metrics"
+	"talentpitchGo/ratelimit"
 	"talentpitchGo/repository"
 	"github.com/gorilla/mux"
 )
 
+// API_V1_PREFIX es el prefijo de las rutas de la versión 1 de la API
+const API_V1_PREFIX = "/api/v1"
+
+// UPLOADS_PREFIX es la ruta bajo la que se sirven los archivos subidos cuando se guardan en el sistema de archivos local
+const UPLOADS_PREFIX = "/uploads"
+
+// SHUTDOWN_TIMEOUT es el tiempo que se espera a que terminen las solicitudes en curso al detener el servidor
+const SHUTDOWN_TIMEOUT = 30 * time.Second
+
+// Almacenes de los límites de solicitudes
+const (
+	RATE_LIMIT_MEMORY   = "memory"
+	RATE_LIMIT_POSTGRES = "postgres"
+)
+
+// Controladores de Postgres de los repositorios de usuarios, retos y empresas
+const (
+	DATABASE_DRIVER_PQ  = "pq"
+	DATABASE_DRIVER_PGX = "pgx"
+)
+
 // Config es la estructura de configuración del servidor.
 type Config struct {
 	Port string // Puerto del servidor
 	JWTSecret string // Secreto para firmar el token JWT
 	DatabaseURL string // URL de la base de datos
+	DatabaseDriver string // Controlador de los repositorios de usuarios, retos y empresas: pq (por defecto) o pgx. Los demás repositorios y WithTx siempre usan pq
+	SoftDeleteRetention time.Duration // Tiempo que se conservan los registros eliminados antes de purgarlos (0 desactiva la purga)
+	RequireIfMatch bool // Exigir la cabecera If-Match en las actualizaciones y eliminaciones
+	RateLimitStore string // Almacén de los límites de solicitudes: memory (por defecto) o postgres
+	CORSAllowedOrigins []string // Orígenes que pueden llamar a la API desde el navegador; "*" permite cualquiera
+	CORSAllowCredentials bool // Permitir credenciales del navegador en las solicitudes CORS
+	CORSMaxAge time.Duration // Tiempo que el navegador guarda la verificación previa de CORS
+	TrustedProxies []netip.Prefix // Proxies cuya cabecera X-Forwarded-For indica la IP del cliente; vacío la ignora
+	MetricsPort string // Puerto interno en el que se sirven las métricas de Prometheus; vacío no las sirve
 }
 
 // Server es una interfaz que define las operaciones del servidor.
@@ -47,6 +85,26 @@ func NewServer(ctx context.Context, config *Config) (*Broker, error) {
 		// Retornar un error de secreto requerido
 		return nil, errors.New("secret is required")
 	}
+	// Verificar que el almacén de los límites de solicitudes exista
+	if config.RateLimitStore != "" && config.RateLimitStore != RATE_LIMIT_MEMORY && config.RateLimitStore != RATE_LIMIT_POSTGRES {
+		return nil, fmt.Errorf("unknown rate limit store %q", config.RateLimitStore)
+	}
+	// Verificar que el controlador de la base de datos exista
+	if config.DatabaseDriver != "" && config.DatabaseDriver != DATABASE_DRIVER_PQ && config.DatabaseDriver != DATABASE_DRIVER_PGX {
+		return nil, fmt.Errorf("unknown database driver %q", config.DatabaseDriver)
+	}
+	// Con credenciales, "*" permitiría que cualquier sitio hiciera solicitudes autenticadas en nombre del usuario
+	if config.CORSAllowCredentials {
+		for _, origin := range config.CORSAllowedOrigins {
+			if origin == "*" {
+				return nil, errors.New(`CORS allowed origins cannot be "*" when credentials are allowed`)
+			}
+		}
+	}
+	// Las métricas no se pueden servir en el puerto público de la API
+	if config.MetricsPort != "" && config.MetricsPort == config.Port {
+		return nil, errors.New("metrics port must be different from the server port")
+	}
 	// Verificar si la URL de la base de datos está vacía
 	if config.DatabaseURL == "" {
 		// Retornar un error de base de datos requerida
@@ -61,8 +119,12 @@ func NewServer(ctx context.Context, config *Config) (*Broker, error) {
 	return broker, nil
 }
 
-// Start es una función que inicia el servidor y enlaza las rutas con el enrutador.
+// Start es una función que inicia el servidor y enlaza las rutas con el enrutador. Al recibir SIGINT o SIGTERM
+// espera a que terminen las solicitudes en curso, cierra las conexiones a la base de datos y retorna.
 func (b *Broker) Start(binder func (s Server, r *mux.Router )) {
+	// Cancelar el contexto de los procesos periódicos al recibir la señal de detener el servidor
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 	// Enlazar las rutas con el enrutador
 	b.router = mux.NewRouter()
 	// Iniciar el servidor
@@ -72,7 +134,8 @@ func (b *Broker) Start(binder func (s Server, r *mux.Router )) {
 	// Verificar si hubo un error creando el repositorio
 	if err != nil {
 		// Loggear el error
-		log.Fatal("NewRepository", err)
+		slog.Error("error creating repository", "error", err)
+		os.Exit(1)
 	}
 	// Establecer el repositorio de usuario
 	repository.SetUserRepository(repo)
@@ -80,11 +143,89 @@ func (b *Broker) Start(binder func (s Server, r *mux.Router )) {
 	repository.SetChallengeRepository(repo)
 	// Establecer el repositorio de empresa
 	repository.SetCompanyRepository(repo)
+	// Con pgx, los repositorios de usuarios, retos y empresas usan un pool de pgx. El cambio es parcial: los demás
+	// repositorios y las unidades de trabajo (WithTx) siguen con database/sql, incluidas las operaciones de usuarios,
+	// retos y empresas que se ejecutan dentro de una transacción
+	var pgxRepo *database.PgxRepository
+	if b.config.DatabaseDriver == DATABASE_DRIVER_PGX {
+		pgxRepo, err = database.NewPgxRepository(ctx, b.Config().DatabaseURL)
+		if err != nil {
+			slog.Error("error creating pgx repository", "error", err)
+			os.Exit(1)
+		}
+		repository.SetUserRepository(pgxRepo)
+		repository.SetChallengeRepository(pgxRepo)
+		repository.SetCompanyRepository(pgxRepo)
+	}
+	// Establecer el repositorio de autenticación de dos factores
+	repository.SetMFARepository(repo)
+	// Establecer el repositorio de identidades externas
+	repository.SetIdentityRepository(repo)
+	// Establecer el repositorio de API keys
+	repository.SetAPIKeyRepository(repo)
+	// Establecer el repositorio de purga de registros eliminados
+	repository.SetPurgeRepository(repo)
+	// Establecer el repositorio del registro de auditoría
+	repository.SetAuditRepository(repo)
+	// Establecer el repositorio de programas
+	repository.SetProgramRepository(repo)
+	// Establecer el repositorio de archivos adjuntos de los retos
+	repository.SetAttachmentRepository(repo)
+	// Establecer la unidad de trabajo para las operaciones transaccionales
+	repository.SetUnitOfWork(repo)
+	// Establecer el almacén de los límites de solicitudes
+	if b.config.RateLimitStore == RATE_LIMIT_POSTGRES {
+		ratelimit.SetStore(repo)
+		// Las cubetas de la base de datos no se eliminan al llenarse, como las de memoria
+		go startRateLimitSweepJob(ctx, RATE_LIMIT_SWEEP_INTERVAL)
+	} else {
+		ratelimit.SetStore(ratelimit.NewMemoryStore())
+	}
+	// Iniciar el proceso de purga de registros eliminados
+	if b.config.SoftDeleteRetention > 0 {
+		go startPurgeJob(ctx, PURGE_INTERVAL, b.config.SoftDeleteRetention)
+	}
 	// Loggear el inicio del servidor
-	log.Printf("Server is running on port %s", b.Config().Port)
+	slog.Info("server is running", "port", b.Config().Port)
 	// Iniciar el servidor
-	if err := http.ListenAndServe(b.config.Port, b.router); err != nil {
-		// Loggear el error
-		log.Fatal("ListenAndServe",err)
+	httpServer := &http.Server{Addr: b.config.Port, Handler: b.router}
+	go func() {
+		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			// Loggear el error
+			slog.Error("error serving http", "error", err)
+			os.Exit(1)
+		}
+	}()
+	// Servir las métricas en su puerto interno, que no debe publicarse fuera de la red de monitoreo
+	var metricsServer *http.Server
+	if b.config.MetricsPort != "" {
+		metricsRouter := http.NewServeMux()
+		metricsRouter.Handle("/metrics", metrics.Handler())
+		metricsServer = &http.Server{Addr: b.config.MetricsPort, Handler: metricsRouter}
+		slog.Info("metrics server is running", "port", b.config.MetricsPort)
+		go func() {
+			if err := metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				slog.Error("error serving metrics", "error", err)
+				os.Exit(1)
+			}
+		}()
 	}
-}	
\ No newline at end of file
+	// Esperar la señal de detener el servidor
+	<-ctx.Done()
+	slog.Info("shutting down server")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), SHUTDOWN_TIMEOUT)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		slog.Error("error shutting down http server", "error", err)
+	}
+	if metricsServer != nil {
+		metricsServer.Shutdown(shutdownCtx)
+	}
+	// Cerrar las conexiones a la base de datos cuando ya no hay solicitudes en curso
+	if pgxRepo != nil {
+		pgxRepo.Close()
+	}
+	if err := repo.Close(); err != nil {
+		slog.Error("error closing repository", "error", err)
+	}
+}
@@ -0,0 +1,33 @@
+package server_test
+
+import (
+	"context"
+	"testing"
+
+	"talentpitchGo/server"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewServerRejectsWildcardCORSWithCredentials(t *testing.T) {
+	config := func(origins []string, credentials bool) *server.Config {
+		return &server.Config{Port: ":5050", JWTSecret: "secret", DatabaseURL: "postgres://localhost/test", CORSAllowedOrigins: origins, CORSAllowCredentials: credentials}
+	}
+
+	_, err := server.NewServer(context.Background(), config([]string{"https://app.example.com", "*"}, true))
+	assert.Error(t, err)
+	// "*" sin credenciales y los orígenes listados con credenciales son válidos
+	_, err = server.NewServer(context.Background(), config([]string{"*"}, false))
+	assert.NoError(t, err)
+	_, err = server.NewServer(context.Background(), config([]string{"https://app.example.com"}, true))
+	assert.NoError(t, err)
+}
+
+func TestNewServerRejectsPublicMetrics(t *testing.T) {
+	config := &server.Config{Port: ":5050", JWTSecret: "secret", DatabaseURL: "postgres://localhost/test", MetricsPort: ":5050"}
+	_, err := server.NewServer(context.Background(), config)
+	assert.Error(t, err)
+	config.MetricsPort = ":9090"
+	_, err = server.NewServer(context.Background(), config)
+	assert.NoError(t, err)
+}
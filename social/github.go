@@ -0,0 +1,99 @@
+package social
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"golang.org/x/oauth2"
+)
+
+// GitHubProvider es un proveedor OAuth2 para GitHub, que no implementa OIDC y expone la identidad por su API REST.
+type GitHubProvider struct {
+	name   string
+	config *oauth2.Config
+	apiURL string
+}
+
+// NewGitHubProvider es una función que crea un proveedor de GitHub. UserInfoURL es la URL base de la API.
+func NewGitHubProvider(config ProviderConfig) *GitHubProvider {
+	// Completar los valores por defecto de GitHub
+	if config.Name == "" {
+		config.Name = "github"
+	}
+	if config.AuthURL == "" {
+		config.AuthURL = "https://github.com/login/oauth/authorize"
+	}
+	if config.TokenURL == "" {
+		config.TokenURL = "https://github.com/login/oauth/access_token"
+	}
+	if config.UserInfoURL == "" {
+		config.UserInfoURL = "https://api.github.com"
+	}
+	if len(config.Scopes) == 0 {
+		config.Scopes = []string{"read:user", "user:email"}
+	}
+	return &GitHubProvider{
+		name:   config.Name,
+		config: config.oauth2Config(),
+		apiURL: config.UserInfoURL,
+	}
+}
+
+// Name es una función que devuelve el nombre del proveedor.
+func (p *GitHubProvider) Name() string {
+	return p.name
+}
+
+// AuthCodeURL es una función que construye la URL de autorización con el reto PKCE S256.
+func (p *GitHubProvider) AuthCodeURL(state string, verifier string) string {
+	return p.config.AuthCodeURL(state, oauth2.S256ChallengeOption(verifier))
+}
+
+// Exchange es una función que intercambia el código por un token y consulta el usuario y sus correos en la API.
+func (p *GitHubProvider) Exchange(ctx context.Context, code string, verifier string) (*Identity, error) {
+	// Intercambiar el código enviando el code_verifier
+	token, err := p.config.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, err
+	}
+	client := p.config.Client(ctx, token)
+
+	// Obtener el usuario
+	var user struct {
+		Id    int64  `json:"id"`
+		Login string `json:"login"`
+		Name  string `json:"name"`
+	}
+	if err = getJSON(ctx, client, p.apiURL+"/user", &user); err != nil {
+		return nil, err
+	}
+	if user.Id == 0 {
+		return nil, errors.New("github user response without id")
+	}
+	// Obtener los correos para conocer el principal y si está verificado
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err = getJSON(ctx, client, p.apiURL+"/user/emails", &emails); err != nil {
+		return nil, err
+	}
+	// Construir la identidad
+	identity := &Identity{
+		Provider: p.Name(),
+		Subject:  strconv.FormatInt(user.Id, 10),
+		Name:     user.Name,
+	}
+	if identity.Name == "" {
+		identity.Name = user.Login
+	}
+	for _, email := range emails {
+		if email.Primary {
+			identity.Email = email.Email
+			identity.EmailVerified = email.Verified
+		}
+	}
+	return identity, nil
+}
@@ -0,0 +1,178 @@
+// El paquete social contiene los proveedores de inicio de sesión externos (OAuth2/OIDC) con PKCE.
+package social
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"golang.org/x/oauth2" // cliente OAuth2 con soporte PKCE
+)
+
+// Identity es la estructura de la identidad externa devuelta por un proveedor.
+type Identity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// Provider es una interfaz que define las operaciones de un proveedor de inicio de sesión externo.
+type Provider interface {
+	// Name es una función que devuelve el nombre del proveedor usado en las rutas (google, github, ...).
+	Name() string
+	// AuthCodeURL es una función que construye la URL de autorización con el state y el reto PKCE del verifier.
+	AuthCodeURL(state string, verifier string) string
+	// Exchange es una función que intercambia el código de autorización por la identidad del usuario.
+	Exchange(ctx context.Context, code string, verifier string) (*Identity, error)
+}
+
+// ProviderConfig es la estructura de configuración de un proveedor OAuth2/OIDC.
+type ProviderConfig struct {
+	Name         string   // Nombre del proveedor
+	ClientID     string   // ID de cliente
+	ClientSecret string   // Secreto de cliente
+	AuthURL      string   // Endpoint de autorización
+	TokenURL     string   // Endpoint de tokens
+	UserInfoURL  string   // Endpoint de información del usuario (o URL base de la API para GitHub)
+	RedirectURL  string   // URL de retorno registrada en el proveedor
+	Scopes       []string // Permisos solicitados
+}
+
+// oauth2Config es una función que convierte la configuración en un *oauth2.Config.
+func (c ProviderConfig) oauth2Config() *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     c.ClientID,
+		ClientSecret: c.ClientSecret,
+		RedirectURL:  c.RedirectURL,
+		Scopes:       c.Scopes,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  c.AuthURL,
+			TokenURL: c.TokenURL,
+		},
+	}
+}
+
+// GenerateVerifier es una función que genera un code_verifier PKCE aleatorio.
+func GenerateVerifier() string {
+	return oauth2.GenerateVerifier()
+}
+
+// OIDCProvider es un proveedor OpenID Connect genérico que obtiene la identidad del endpoint userinfo.
+type OIDCProvider struct {
+	name        string
+	config      *oauth2.Config
+	userInfoURL string
+}
+
+// NewOIDCProvider es una función que crea un proveedor OIDC a partir de su configuración.
+func NewOIDCProvider(config ProviderConfig) *OIDCProvider {
+	// Solicitar los permisos estándar si no se especificaron
+	if len(config.Scopes) == 0 {
+		config.Scopes = []string{"openid", "email", "profile"}
+	}
+	return &OIDCProvider{
+		name:        config.Name,
+		config:      config.oauth2Config(),
+		userInfoURL: config.UserInfoURL,
+	}
+}
+
+// Name es una función que devuelve el nombre del proveedor.
+func (p *OIDCProvider) Name() string {
+	return p.name
+}
+
+// AuthCodeURL es una función que construye la URL de autorización con el reto PKCE S256.
+func (p *OIDCProvider) AuthCodeURL(state string, verifier string) string {
+	return p.config.AuthCodeURL(state, oauth2.S256ChallengeOption(verifier))
+}
+
+// Exchange es una función que intercambia el código por un token y consulta el endpoint userinfo.
+func (p *OIDCProvider) Exchange(ctx context.Context, code string, verifier string) (*Identity, error) {
+	// Intercambiar el código enviando el code_verifier
+	token, err := p.config.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, err
+	}
+	// Consultar la información del usuario con el token de acceso
+	var claims struct {
+		Subject       string          `json:"sub"`
+		Email         string          `json:"email"`
+		EmailVerified json.RawMessage `json:"email_verified"`
+		Name          string          `json:"name"`
+	}
+	if err = getJSON(ctx, p.config.Client(ctx, token), p.userInfoURL, &claims); err != nil {
+		return nil, err
+	}
+	// Verificar que el proveedor haya devuelto un sujeto
+	if claims.Subject == "" {
+		return nil, errors.New("userinfo response without sub claim")
+	}
+	// Retornar la identidad
+	return &Identity{
+		Provider:      p.name,
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: parseBool(claims.EmailVerified),
+		Name:          claims.Name,
+	}, nil
+}
+
+// Discover es una función que completa los endpoints de la configuración a partir del
+// documento /.well-known/openid-configuration del emisor.
+func Discover(ctx context.Context, issuer string, config ProviderConfig) (ProviderConfig, error) {
+	var document struct {
+		AuthorizationEndpoint string `json:"authorization_endpoint"`
+		TokenEndpoint         string `json:"token_endpoint"`
+		UserInfoEndpoint      string `json:"userinfo_endpoint"`
+	}
+	// Obtener el documento de descubrimiento
+	if err := getJSON(ctx, http.DefaultClient, issuer+"/.well-known/openid-configuration", &document); err != nil {
+		return config, err
+	}
+	// Completar los endpoints
+	config.AuthURL = document.AuthorizationEndpoint
+	config.TokenURL = document.TokenEndpoint
+	config.UserInfoURL = document.UserInfoEndpoint
+	return config, nil
+}
+
+// getJSON es una función que realiza una petición GET y decodifica la respuesta JSON.
+func getJSON(ctx context.Context, client *http.Client, url string, v interface{}) error {
+	// Crear la solicitud
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	// Ejecutar la solicitud
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	// Verificar el código de estado
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: unexpected status %d", url, res.StatusCode)
+	}
+	// Decodificar la respuesta
+	return json.NewDecoder(res.Body).Decode(v)
+}
+
+// parseBool es una función que interpreta un claim booleano que algunos proveedores envían como texto.
+func parseBool(raw json.RawMessage) bool {
+	var b bool
+	if json.Unmarshal(raw, &b) == nil {
+		return b
+	}
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		b, _ = strconv.ParseBool(s)
+	}
+	return b
+}
@@ -0,0 +1,92 @@
+package social_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"talentpitchGo/social"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mockOIDCServer es un servidor OIDC local que verifica el reto PKCE en el endpoint de tokens.
+func mockOIDCServer(t *testing.T, challenge *string) *httptest.Server {
+	mux := http.NewServeMux()
+	var srv *httptest.Server
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 srv.URL,
+			"authorization_endpoint": srv.URL + "/authorize",
+			"token_endpoint":         srv.URL + "/token",
+			"userinfo_endpoint":      srv.URL + "/userinfo",
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		// Verificar que el code_verifier corresponda al code_challenge enviado en la autorización
+		sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		if r.PostForm.Get("code") != "good-code" || base64.RawURLEncoding.EncodeToString(sum[:]) != *challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "access", "token_type": "Bearer", "expires_in": 3600})
+	})
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"sub":            "mock-123",
+			"email":          "test@example.com",
+			"email_verified": "true",
+			"name":           "Test User",
+		})
+	})
+	srv = httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestOIDCProviderPKCEFlow(t *testing.T) {
+	var challenge string
+	srv := mockOIDCServer(t, &challenge)
+
+	// Descubrir los endpoints del servidor local
+	config, err := social.Discover(context.Background(), srv.URL, social.ProviderConfig{
+		Name:        "mock",
+		ClientID:    "client",
+		RedirectURL: "http://localhost/auth/mock/callback",
+	})
+	assert.NoError(t, err)
+	provider := social.NewOIDCProvider(config)
+
+	// La URL de autorización incluye el state y el reto S256
+	verifier := social.GenerateVerifier()
+	authURL, err := url.Parse(provider.AuthCodeURL("state-1", verifier))
+	assert.NoError(t, err)
+	assert.Equal(t, srv.URL+"/authorize", authURL.Scheme+"://"+authURL.Host+authURL.Path)
+	assert.Equal(t, "state-1", authURL.Query().Get("state"))
+	assert.Equal(t, "S256", authURL.Query().Get("code_challenge_method"))
+	challenge = authURL.Query().Get("code_challenge")
+
+	// El intercambio con el verifier correcto devuelve la identidad
+	identity, err := provider.Exchange(context.Background(), "good-code", verifier)
+	assert.NoError(t, err)
+	assert.Equal(t, "mock", identity.Provider)
+	assert.Equal(t, "mock-123", identity.Subject)
+	assert.Equal(t, "test@example.com", identity.Email)
+	assert.True(t, identity.EmailVerified)
+
+	// Un verifier distinto es rechazado por el servidor
+	_, err = provider.Exchange(context.Background(), "good-code", social.GenerateVerifier())
+	assert.Error(t, err)
+}
@@ -0,0 +1,32 @@
+package social
+
+// Endpoints conocidos de los proveedores OIDC soportados.
+var (
+	GOOGLE = ProviderConfig{
+		Name:        "google",
+		AuthURL:     "https://accounts.google.com/o/oauth2/v2/auth",
+		TokenURL:    "https://oauth2.googleapis.com/token",
+		UserInfoURL: "https://openidconnect.googleapis.com/v1/userinfo",
+	}
+	LINKEDIN = ProviderConfig{
+		Name:        "linkedin",
+		AuthURL:     "https://www.linkedin.com/oauth/v2/authorization",
+		TokenURL:    "https://www.linkedin.com/oauth/v2/accessToken",
+		UserInfoURL: "https://api.linkedin.com/v2/userinfo",
+	}
+)
+
+// providers es una variable que contiene los proveedores registrados por nombre.
+var providers = map[string]Provider{}
+
+// Register es una función que registra un proveedor. Debe llamarse al iniciar la aplicación.
+func Register(provider Provider) {
+	// Registrar el proveedor con su nombre
+	providers[provider.Name()] = provider
+}
+
+// GetProvider es una función que obtiene un proveedor registrado por su nombre.
+func GetProvider(name string) (Provider, bool) {
+	provider, ok := providers[name]
+	return provider, ok
+}
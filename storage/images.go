@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"path"
+	"strconv"
+	"strings"
+)
+
+// THUMBNAIL_SIZES son los lados de las miniaturas que se guardan de cada imagen
+var THUMBNAIL_SIZES = []int{256, 64}
+
+// CompanyLogoPrefix es una función que devuelve el prefijo de las claves del logo de la empresa.
+func CompanyLogoPrefix(companyID string) string {
+	return "companies/" + companyID + "/logo"
+}
+
+// UserAvatarPrefix es una función que devuelve el prefijo de las claves del avatar del usuario.
+func UserAvatarPrefix(userID string) string {
+	return "users/" + userID + "/avatar"
+}
+
+// ThumbnailKey es una función que devuelve la clave de la miniatura del lado dado de la imagen de la clave dada.
+func ThumbnailKey(key string, size int) string {
+	extension := path.Ext(key)
+	return strings.TrimSuffix(key, extension) + "-" + strconv.Itoa(size) + extension
+}
+
+// ImageKeys es una función que devuelve las claves de la imagen y sus miniaturas guardadas bajo el prefijo dado a
+// partir de la URL de la imagen. Devuelve nil si la URL está vacía o no es de una imagen subida bajo el prefijo.
+func ImageKeys(url string, prefix string) []string {
+	index := strings.Index(url, prefix+"/")
+	if url == "" || index < 0 {
+		return nil
+	}
+	key := url[index:]
+	if ValidateKey(key) != nil {
+		return nil
+	}
+	keys := []string{key}
+	for _, size := range THUMBNAIL_SIZES {
+		keys = append(keys, ThumbnailKey(key, size))
+	}
+	return keys
+}
@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStore es un almacén que guarda los archivos en un directorio del sistema de archivos.
+// Los archivos se sirven con Handler bajo la URL base.
+type LocalStore struct {
+	dir     string // Directorio raíz de los archivos
+	baseURL string // URL pública bajo la que se sirve el directorio
+}
+
+// NewLocalStore es una función que crea un almacén local en el directorio dado, creándolo si no existe.
+func NewLocalStore(dir string, baseURL string) (*LocalStore, error) {
+	// Verificar que el directorio no esté vacío
+	if dir == "" {
+		return nil, errors.New("storage directory is required")
+	}
+	// Crear el directorio si no existe
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &LocalStore{dir: dir, baseURL: strings.TrimRight(baseURL, "/")}, nil
+}
+
+// Put es una función que escribe el archivo en el directorio y devuelve su URL pública.
+func (l *LocalStore) Put(ctx context.Context, key string, contentType string, data []byte) (string, error) {
+	// Verificar la clave también aquí: una clave inválida podría salir del directorio
+	if err := ValidateKey(key); err != nil {
+		return "", err
+	}
+	path := l.path(key)
+	// Crear los directorios intermedios de la clave
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+	// Escribir en un archivo temporal y renombrarlo para no servir archivos a medio escribir
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err = tmp.Close(); err != nil {
+		return "", err
+	}
+	if err = os.Chmod(tmp.Name(), 0o644); err != nil {
+		return "", err
+	}
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return "", err
+	}
+	return l.baseURL + "/" + key, nil
+}
+
+// Get es una función que abre el archivo del directorio.
+func (l *LocalStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	if err := ValidateKey(key); err != nil {
+		return nil, err
+	}
+	file, err := os.Open(l.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	return file, err
+}
+
+// Delete es una función que elimina el archivo del directorio.
+func (l *LocalStore) Delete(ctx context.Context, key string) error {
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+	err := os.Remove(l.path(key))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Handler es una función que devuelve el controlador que sirve los archivos del directorio.
+// Debe montarse quitando el prefijo de la URL base; no lista el contenido de los directorios.
+func (l *LocalStore) Handler() http.Handler {
+	files := http.FileServer(http.Dir(l.dir))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Rechazar los directorios, los archivos temporales de las escrituras en curso y los archivos privados.
+		// La ruta se limpia igual que en el servidor de archivos para que "a/../private/..." no se salte la verificación.
+		key := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+		if r.URL.Path == "" || strings.HasSuffix(r.URL.Path, "/") || strings.HasPrefix(filepath.Base(r.URL.Path), ".") || IsPrivate(key) {
+			http.NotFound(w, r)
+			return
+		}
+		// Los archivos no cambian: cada subida usa una clave nueva
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		files.ServeHTTP(w, r)
+	})
+}
+
+// path es una función que devuelve la ruta del archivo de una clave dentro del directorio.
+func (l *LocalStore) path(key string) string {
+	return filepath.Join(l.dir, filepath.FromSlash(key))
+}
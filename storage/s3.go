@@ -0,0 +1,222 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3Config es la configuración de un almacén compatible con S3 (AWS S3, MinIO, Cloudflare R2, ...).
+type S3Config struct {
+	Endpoint        string // URL del servicio; por defecto el endpoint de AWS de la región
+	Region          string // Región usada en la firma; por defecto us-east-1
+	Bucket          string // Bucket donde se guardan los archivos
+	AccessKeyID     string // Credencial de acceso
+	SecretAccessKey string // Secreto de la credencial
+	PublicURL       string // URL pública de los archivos (por ejemplo un CDN); por defecto la URL del objeto
+	PathStyle       bool   // Usar endpoint/bucket/clave en lugar de bucket.endpoint/clave, como exigen MinIO y otros servicios locales
+}
+
+// S3Store es un almacén que guarda los archivos en un bucket compatible con S3, firmando las solicitudes con AWS Signature V4.
+type S3Store struct {
+	config   S3Config
+	endpoint *url.URL
+	client   *http.Client
+	now      func() time.Time
+}
+
+// S3_SERVICE es el nombre del servicio en el alcance de la firma
+const S3_SERVICE = "s3"
+
+// NewS3Store es una función que crea un almacén compatible con S3 con la configuración dada.
+func NewS3Store(config S3Config) (*S3Store, error) {
+	// Verificar la configuración obligatoria
+	if config.Bucket == "" {
+		return nil, errors.New("s3 bucket is required")
+	}
+	if config.AccessKeyID == "" || config.SecretAccessKey == "" {
+		return nil, errors.New("s3 credentials are required")
+	}
+	// Completar los valores por defecto de AWS
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+	if config.Endpoint == "" {
+		config.Endpoint = "https://s3." + config.Region + ".amazonaws.com"
+	}
+	endpoint, err := url.Parse(strings.TrimRight(config.Endpoint, "/"))
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid s3 endpoint %q", config.Endpoint)
+	}
+	return &S3Store{
+		config:   config,
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: 30 * time.Second},
+		now:      time.Now,
+	}, nil
+}
+
+// Put es una función que sube el archivo al bucket y devuelve su URL pública.
+func (s *S3Store) Put(ctx context.Context, key string, contentType string, data []byte) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(key), bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", contentType)
+	// Los archivos no cambian: cada subida usa una clave nueva. Los privados no se guardan en cachés compartidas.
+	if IsPrivate(key) {
+		req.Header.Set("Cache-Control", "private, no-store")
+	} else {
+		req.Header.Set("Cache-Control", "public, max-age=31536000, immutable")
+	}
+	if err = s.do(req, data, http.StatusOK); err != nil {
+		return "", err
+	}
+	return s.publicURL(key), nil
+}
+
+// Get es una función que descarga el archivo del bucket.
+func (s *S3Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.objectURL(key), nil)
+	if err != nil {
+		return nil, err
+	}
+	s.sign(req, nil, s.now().UTC())
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	switch res.StatusCode {
+	case http.StatusOK:
+		// El cuerpo lo cierra quien llama
+		return res.Body, nil
+	case http.StatusNotFound:
+		res.Body.Close()
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	defer res.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+	return nil, fmt.Errorf("s3 %s %s: %s: %s", req.Method, req.URL.Path, res.Status, strings.TrimSpace(string(body)))
+}
+
+// Delete es una función que elimina el archivo del bucket.
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, s.objectURL(key), nil)
+	if err != nil {
+		return err
+	}
+	// S3 responde 204 aunque el objeto no exista; otros servicios responden 404
+	return s.do(req, nil, http.StatusNoContent, http.StatusOK, http.StatusNotFound)
+}
+
+// do es una función que firma y envía la solicitud, y verifica el código de la respuesta.
+func (s *S3Store) do(req *http.Request, payload []byte, expected ...int) error {
+	s.sign(req, payload, s.now().UTC())
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	for _, code := range expected {
+		if res.StatusCode == code {
+			return nil
+		}
+	}
+	// Incluir el inicio del error XML del servicio
+	body, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+	return fmt.Errorf("s3 %s %s: %s: %s", req.Method, req.URL.Path, res.Status, strings.TrimSpace(string(body)))
+}
+
+// objectURL es una función que devuelve la URL del objeto en el servicio.
+func (s *S3Store) objectURL(key string) string {
+	u := *s.endpoint
+	if s.config.PathStyle {
+		u.Path = u.Path + "/" + s.config.Bucket + "/" + key
+	} else {
+		u.Host = s.config.Bucket + "." + u.Host
+		u.Path = u.Path + "/" + key
+	}
+	return u.String()
+}
+
+// publicURL es una función que devuelve la URL pública del objeto.
+func (s *S3Store) publicURL(key string) string {
+	if s.config.PublicURL != "" {
+		return strings.TrimRight(s.config.PublicURL, "/") + "/" + key
+	}
+	return s.objectURL(key)
+}
+
+// sign es una función que firma la solicitud con AWS Signature V4 usando todas sus cabeceras.
+func (s *S3Store) sign(req *http.Request, payload []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(payload)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	// Cabeceras canónicas: host más las de la solicitud, en minúsculas y ordenadas
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	// Solicitud canónica
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	// Texto a firmar y clave derivada de la fecha, la región y el servicio
+	scope := date + "/" + s.config.Region + "/" + S3_SERVICE + "/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+	key := hmacSHA256([]byte("AWS4"+s.config.SecretAccessKey), date)
+	key = hmacSHA256(key, s.config.Region)
+	key = hmacSHA256(key, S3_SERVICE)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+s.config.AccessKeyID+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+// sha256Hex es una función que devuelve el hash SHA-256 en hexadecimal.
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// hmacSHA256 es una función que calcula el HMAC-SHA256 del texto con la clave dada.
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
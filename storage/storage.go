@@ -0,0 +1,112 @@
+// El paquete storage guarda los archivos subidos (logos, avatares) detrás de la interfaz BlobStore.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// BlobStore es una interfaz que define las operaciones de un almacén de archivos.
+type BlobStore interface {
+	// Put es una función que guarda el contenido con la clave y el tipo dados y devuelve su URL pública.
+	// Si la clave ya existe, reemplaza el contenido.
+	Put(ctx context.Context, key string, contentType string, data []byte) (string, error)
+	// Get es una función que abre el contenido del archivo de la clave dada; devuelve ErrNotFound si no existe.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete es una función que elimina el archivo de la clave dada. No devuelve error si no existe.
+	Delete(ctx context.Context, key string) error
+}
+
+// ErrNotFound es el error que se devuelve cuando el archivo no existe
+var ErrNotFound = errors.New("blob not found")
+
+// PRIVATE_PREFIX es el prefijo de las claves de los archivos privados, como los adjuntos de los retos.
+// Handler nunca los sirve: solo se leen con Get desde un controlador que verifica el acceso.
+// Con S3, la política del bucket debe negar la lectura pública de este prefijo.
+const PRIVATE_PREFIX = "private/"
+
+// keyPattern son los caracteres permitidos en las claves: segmentos separados por "/" sin espacios ni comodines
+var keyPattern = regexp.MustCompile(`^[A-Za-z0-9._-]+(/[A-Za-z0-9._-]+)*$`)
+
+// IsPrivate es una función que indica si la clave es de un archivo privado.
+func IsPrivate(key string) bool {
+	return strings.HasPrefix(key, PRIVATE_PREFIX)
+}
+
+// implementationBlobStore es una variable que contiene la implementación de la interfaz BlobStore.
+var implementationBlobStore BlobStore
+
+// SetBlobStore es una función que establece la implementación de la interfaz BlobStore.
+func SetBlobStore(store BlobStore) {
+	// Establecer la implementación de la interfaz BlobStore
+	implementationBlobStore = store
+}
+
+// ValidateKey es una función que verifica que la clave sea una ruta relativa sin segmentos "." ni "..".
+func ValidateKey(key string) error {
+	if !keyPattern.MatchString(key) {
+		return fmt.Errorf("invalid blob key %q", key)
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "." || segment == ".." {
+			return fmt.Errorf("invalid blob key %q", key)
+		}
+	}
+	return nil
+}
+
+// Put es una función que guarda un archivo en el almacén configurado y devuelve su URL pública.
+func Put(ctx context.Context, key string, contentType string, data []byte) (string, error) {
+	// Verificar que implementationBlobStore no sea nil
+	if implementationBlobStore == nil {
+		return "", errors.New("implementationBlobStore cannot be nil")
+	}
+	// Verificar que la clave sea válida
+	if err := ValidateKey(key); err != nil {
+		return "", err
+	}
+	// Llamar a la función Put de la implementación
+	return implementationBlobStore.Put(ctx, key, contentType, data)
+}
+
+// Get es una función que abre un archivo del almacén configurado. Quien llama debe cerrarlo.
+func Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	// Verificar que implementationBlobStore no sea nil
+	if implementationBlobStore == nil {
+		return nil, errors.New("implementationBlobStore cannot be nil")
+	}
+	// Verificar que la clave sea válida
+	if err := ValidateKey(key); err != nil {
+		return nil, err
+	}
+	// Llamar a la función Get de la implementación
+	return implementationBlobStore.Get(ctx, key)
+}
+
+// Delete es una función que elimina un archivo del almacén configurado.
+func Delete(ctx context.Context, key string) error {
+	// Verificar que implementationBlobStore no sea nil
+	if implementationBlobStore == nil {
+		return errors.New("implementationBlobStore cannot be nil")
+	}
+	// Verificar que la clave sea válida
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+	// Llamar a la función Delete de la implementación
+	return implementationBlobStore.Delete(ctx, key)
+}
+
+// Handler es una función que devuelve el controlador que sirve los archivos del almacén configurado,
+// o nil si el almacén los sirve por su cuenta, como S3 o un CDN.
+func Handler() http.Handler {
+	if local, ok := implementationBlobStore.(*LocalStore); ok {
+		return local.Handler()
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package storage_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"talentpitchGo/storage"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeS3 es un servicio compatible con S3 en memoria, con el formato de rutas bucket/clave
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	types   map[string]string
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	// Las solicitudes deben venir firmadas y con el hash del cuerpo
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=key/") {
+		http.Error(w, "AccessDenied", http.StatusForbidden)
+		return
+	}
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		sum := sha256.Sum256(body)
+		if r.Header.Get("X-Amz-Content-Sha256") != hex.EncodeToString(sum[:]) {
+			http.Error(w, "XAmzContentSHA256Mismatch", http.StatusBadRequest)
+			return
+		}
+		f.objects[r.URL.Path] = body
+		f.types[r.URL.Path] = r.Header.Get("Content-Type")
+	case http.MethodGet:
+		body, ok := f.objects[r.URL.Path]
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		w.Write(body)
+	case http.MethodDelete:
+		delete(f.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestS3Store(t *testing.T) {
+	fake := &fakeS3{objects: map[string][]byte{}, types: map[string]string{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	store, err := storage.NewS3Store(storage.S3Config{
+		Endpoint: server.URL, Bucket: "logos", AccessKeyID: "key", SecretAccessKey: "secret",
+		PublicURL: "https://cdn.example.com", PathStyle: true,
+	})
+	assert.NoError(t, err)
+
+	// Put guarda el objeto en bucket/clave y devuelve la URL pública
+	url, err := store.Put(context.Background(), "companies/c1/logo.png", "image/png", []byte("png"))
+	assert.NoError(t, err)
+	assert.Equal(t, "https://cdn.example.com/companies/c1/logo.png", url)
+	assert.Equal(t, []byte("png"), fake.objects["/logos/companies/c1/logo.png"])
+	assert.Equal(t, "image/png", fake.types["/logos/companies/c1/logo.png"])
+
+	// Get descarga el objeto
+	body, err := store.Get(context.Background(), "companies/c1/logo.png")
+	assert.NoError(t, err)
+	content, _ := io.ReadAll(body)
+	body.Close()
+	assert.Equal(t, []byte("png"), content)
+
+	// Delete elimina el objeto, y después Get devuelve ErrNotFound
+	assert.NoError(t, store.Delete(context.Background(), "companies/c1/logo.png"))
+	assert.Empty(t, fake.objects)
+	_, err = store.Get(context.Background(), "companies/c1/logo.png")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+
+	// Los errores del servicio se devuelven
+	bad, _ := storage.NewS3Store(storage.S3Config{Endpoint: server.URL, Bucket: "logos", AccessKeyID: "other", SecretAccessKey: "secret", PathStyle: true})
+	_, err = bad.Put(context.Background(), "a.png", "image/png", []byte("png"))
+	assert.ErrorContains(t, err, "403")
+}
+
+func TestLocalStore(t *testing.T) {
+	dir := t.TempDir()
+	store, err := storage.NewLocalStore(dir, "/uploads/")
+	assert.NoError(t, err)
+	storage.SetBlobStore(store)
+
+	// Put escribe el archivo y devuelve su URL bajo la URL base
+	url, err := storage.Put(context.Background(), "users/u1/avatar.png", "image/png", []byte("png"))
+	assert.NoError(t, err)
+	assert.Equal(t, "/uploads/users/u1/avatar.png", url)
+
+	// El controlador sirve el archivo, pero no los directorios
+	handler := http.StripPrefix("/uploads/", store.Handler())
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/uploads/users/u1/avatar.png", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "png", rr.Body.String())
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/uploads/users/", nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	// Los archivos privados no se sirven, tampoco con rutas que los alcanzan desde otro directorio
+	_, err = storage.Put(context.Background(), storage.PRIVATE_PREFIX+"doc.html", "text/html", []byte("<script></script>"))
+	assert.NoError(t, err)
+	for _, target := range []string{"/uploads/private/doc.html", "/uploads/users/../private/doc.html"} {
+		rr = httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, target, nil))
+		assert.Equal(t, http.StatusNotFound, rr.Code, target)
+	}
+
+	// Las claves que saldrían del directorio se rechazan
+	_, err = storage.Put(context.Background(), "../escape.png", "image/png", []byte("png"))
+	assert.Error(t, err)
+
+	// Delete no falla si el archivo ya no existe, y después Get devuelve ErrNotFound
+	assert.NoError(t, storage.Delete(context.Background(), "users/u1/avatar.png"))
+	assert.NoError(t, storage.Delete(context.Background(), "users/u1/avatar.png"))
+	_, err = storage.Get(context.Background(), "users/u1/avatar.png")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+}
+
+func TestImageKeys(t *testing.T) {
+	// La imagen subida y sus miniaturas, con la URL pública del almacén
+	assert.Equal(t, []string{
+		"companies/c1/logo/abc.png",
+		"companies/c1/logo/abc-256.png",
+		"companies/c1/logo/abc-64.png",
+	}, storage.ImageKeys("https://cdn.example.com/companies/c1/logo/abc.png", storage.CompanyLogoPrefix("c1")))
+
+	// Las URL vacías, externas o de otro recurso no tienen claves
+	assert.Nil(t, storage.ImageKeys("", storage.UserAvatarPrefix("u1")))
+	assert.Nil(t, storage.ImageKeys("https://example.com/avatar.png", storage.UserAvatarPrefix("u1")))
+	assert.Nil(t, storage.ImageKeys("/uploads/users/u2/avatar/abc.png", storage.UserAvatarPrefix("u1")))
+}
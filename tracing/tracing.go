@@ -0,0 +1,72 @@
+// El paquete tracing configura el trazado distribuido con OpenTelemetry: el exportador de las trazas,
+// la propagación de la cabecera W3C traceparent y el tracer que usan el enrutador y el repositorio.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// INSTRUMENTATION es el nombre del tracer de la aplicación
+const INSTRUMENTATION = "talentpitchGo"
+
+// Exportadores de las trazas
+const (
+	EXPORTER_NONE   = "none"
+	EXPORTER_OTLP   = "otlp"
+	EXPORTER_STDOUT = "stdout"
+)
+
+// Setup es una función que configura el trazado con el exportador dado: "otlp" envía las trazas por HTTP al colector
+// de OTEL_EXPORTER_OTLP_ENDPOINT, "stdout" las escribe en la salida estándar y "none" (por defecto) no las exporta,
+// aunque sigue propagando la cabecera traceparent. Devuelve la función que envía las trazas pendientes al terminar.
+func Setup(ctx context.Context, exporter string, service string) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagator())
+	var spanExporter sdktrace.SpanExporter
+	var err error
+	switch strings.ToLower(exporter) {
+	case "", EXPORTER_NONE:
+		return func(context.Context) error { return nil }, nil
+	case EXPORTER_OTLP:
+		spanExporter, err = otlptracehttp.New(ctx)
+	case EXPORTER_STDOUT:
+		spanExporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	default:
+		return nil, fmt.Errorf("invalid trace exporter %q", exporter)
+	}
+	if err != nil {
+		return nil, err
+	}
+	provider := Install(sdktrace.NewBatchSpanProcessor(spanExporter), sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(service))))
+	return provider.Shutdown, nil
+}
+
+// Install es una función que registra como proveedor global uno que envía las trazas al procesador dado.
+// Las pruebas lo usan con sdktrace.NewSimpleSpanProcessor y un tracetest.InMemoryExporter para revisar las trazas.
+func Install(processor sdktrace.SpanProcessor, options ...sdktrace.TracerProviderOption) *sdktrace.TracerProvider {
+	provider := sdktrace.NewTracerProvider(append(options, sdktrace.WithSpanProcessor(processor))...)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagator())
+	return provider
+}
+
+// Tracer es una función que devuelve el tracer de la aplicación del proveedor global actual.
+func Tracer() trace.Tracer {
+	return otel.Tracer(INSTRUMENTATION)
+}
+
+// propagator es una función que devuelve el propagador del contexto de las trazas (W3C traceparent y baggage)
+func propagator() propagation.TextMapPropagator {
+	return propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+}
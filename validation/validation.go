@@ -0,0 +1,187 @@
+// El paquete validation valida estructuras con las reglas declaradas en la etiqueta `validate` de sus campos
+// y devuelve todos los errores de los campos a la vez.
+//
+// Reglas disponibles, separadas por comas:
+//   - required: el campo no puede estar vacío (texto vacío o número 0)
+//   - email: el texto debe ser una dirección de correo
+//   - min=N, max=N: longitud mínima y máxima de un texto, o valor mínimo y máximo de un número
+//
+// Otros paquetes pueden agregar reglas propias con RegisterRule.
+package validation
+
+import (
+	"fmt"
+	"net/mail"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// TAG es el nombre de la etiqueta con las reglas de un campo
+const TAG = "validate"
+
+// Códigos de error de las reglas
+const (
+	CODE_REQUIRED  = "required"
+	CODE_EMAIL     = "email"
+	CODE_MIN       = "min"
+	CODE_MAX       = "max"
+	CODE_NOT_FOUND = "not_found" // El campo referencia un recurso que no existe
+)
+
+// Rule es el tipo de una regla propia: recibe el valor del campo y el parámetro de la regla,
+// y devuelve el mensaje de error, o vacío si el valor es válido
+type Rule func(value reflect.Value, param string) string
+
+// customRules son las reglas registradas con RegisterRule
+var (
+	customRulesMu sync.RWMutex
+	customRules   = map[string]Rule{}
+)
+
+// RegisterRule es una función que registra una regla propia con el nombre dado, para usarla en la etiqueta validate
+func RegisterRule(name string, rule Rule) {
+	customRulesMu.Lock()
+	defer customRulesMu.Unlock()
+	customRules[name] = rule
+}
+
+// FieldError es la estructura del error de validación de un campo
+type FieldError struct {
+	Field   string `json:"field"`   // Nombre JSON del campo
+	Code    string `json:"code"`    // Regla que no se cumple
+	Message string `json:"message"` // Descripción del error
+}
+
+// Errors es la lista de errores de validación de una estructura
+type Errors []FieldError
+
+// Error es una función que describe todos los errores en un solo texto
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, fieldError := range e {
+		messages[i] = fieldError.Field + ": " + fieldError.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Err es una función que devuelve la lista como error, o nil si está vacía
+func (e Errors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Validate es una función que valida la estructura dada y devuelve sus errores, o nil si es válida
+func Validate(value interface{}) error {
+	return Struct(value).Err()
+}
+
+// Struct es una función que valida los campos de la estructura (o puntero a estructura) dada
+// y devuelve los errores en el orden de los campos.
+func Struct(value interface{}) Errors {
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return Errors{{Field: "", Code: CODE_REQUIRED, Message: "cannot be nil"}}
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	var errs Errors
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		rules := field.Tag.Get(TAG)
+		if rules == "" || !field.IsExported() {
+			continue
+		}
+		// Usar el nombre JSON del campo en los errores
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			name = field.Name
+		}
+		if fieldError, ok := check(name, v.Field(i), rules); !ok {
+			errs = append(errs, fieldError)
+		}
+	}
+	return errs
+}
+
+// check es una función que aplica las reglas a un campo y devuelve el primer error encontrado
+func check(name string, value reflect.Value, rules string) (FieldError, bool) {
+	for _, rule := range strings.Split(rules, ",") {
+		rule, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		// Las reglas distintas de required no se aplican a los campos vacíos
+		if rule != CODE_REQUIRED && value.IsZero() {
+			continue
+		}
+		var message string
+		switch rule {
+		case CODE_REQUIRED:
+			if value.IsZero() {
+				message = "is required"
+			}
+		case CODE_EMAIL:
+			address, err := mail.ParseAddress(value.String())
+			if err != nil || address.Address != value.String() || !strings.Contains(address.Address[strings.LastIndex(address.Address, "@"):], ".") {
+				message = "must be a valid email address"
+			}
+		case CODE_MIN, CODE_MAX:
+			message = checkBound(rule, value, param)
+		default:
+			// Buscar la regla entre las registradas
+			customRulesMu.RLock()
+			custom, ok := customRules[rule]
+			customRulesMu.RUnlock()
+			if !ok {
+				panic(fmt.Sprintf("validation: unknown rule %q on field %s", rule, name))
+			}
+			message = custom(value, param)
+		}
+		if message != "" {
+			return FieldError{Field: name, Code: rule, Message: message}, false
+		}
+	}
+	return FieldError{}, true
+}
+
+// checkBound es una función que aplica las reglas min y max a la longitud de un texto o al valor de un número
+func checkBound(rule string, value reflect.Value, param string) string {
+	bound, err := strconv.Atoi(param)
+	if err != nil {
+		panic(fmt.Sprintf("validation: invalid %s parameter %q", rule, param))
+	}
+	switch value.Kind() {
+	case reflect.String:
+		length := utf8.RuneCountInString(value.String())
+		if rule == CODE_MIN && length < bound {
+			return fmt.Sprintf("must be at least %d characters long", bound)
+		}
+		if rule == CODE_MAX && length > bound {
+			return fmt.Sprintf("must be at most %d characters long", bound)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		number := value.Int()
+		if rule == CODE_MIN && number < int64(bound) {
+			return fmt.Sprintf("must be at least %d", bound)
+		}
+		if rule == CODE_MAX && number > int64(bound) {
+			return fmt.Sprintf("must be at most %d", bound)
+		}
+	case reflect.Slice, reflect.Map:
+		length := value.Len()
+		if rule == CODE_MIN && length < bound {
+			return fmt.Sprintf("must have at least %d items", bound)
+		}
+		if rule == CODE_MAX && length > bound {
+			return fmt.Sprintf("must have at most %d items", bound)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,46 @@
+package validation_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"talentpitchGo/validation"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// signup es una estructura de prueba con varias reglas por campo
+type signup struct {
+	Email    string `json:"email" validate:"required,email,max=20"`
+	Fullname string `json:"fullname" validate:"required,max=5"`
+	Level    int    `json:"level" validate:"required,min=1,max=5"`
+	Nickname string `json:"nickname" validate:"min=3"`
+	Ignored  string `json:"ignored"`
+}
+
+func TestStructReturnsAllFieldErrors(t *testing.T) {
+	errs := validation.Struct(&signup{Email: "not-an-email", Fullname: "", Level: 9, Nickname: "ab"})
+
+	// Se devuelven todos los errores, uno por campo y en el orden de los campos
+	assert.Equal(t, validation.Errors{
+		{Field: "email", Code: validation.CODE_EMAIL, Message: "must be a valid email address"},
+		{Field: "fullname", Code: validation.CODE_REQUIRED, Message: "is required"},
+		{Field: "level", Code: validation.CODE_MAX, Message: "must be at most 5"},
+		{Field: "nickname", Code: validation.CODE_MIN, Message: "must be at least 3 characters long"},
+	}, errs)
+
+	// La lista se codifica con los nombres JSON
+	encoded, err := json.Marshal(errs)
+	assert.NoError(t, err)
+	assert.Contains(t, string(encoded), `{"field":"email","code":"email","message":"must be a valid email address"}`)
+}
+
+func TestValidateAcceptsValidStruct(t *testing.T) {
+	assert.NoError(t, validation.Validate(signup{Email: "ana@example.com", Fullname: "Ana", Level: 3}))
+
+	// Las direcciones con nombre o sin dominio completo no son correos válidos
+	for _, email := range []string{"Ana <ana@example.com>", "ana@localhost", "ana"} {
+		err := validation.Validate(signup{Email: email, Fullname: "Ana", Level: 3})
+		assert.Error(t, err, email)
+	}
+}